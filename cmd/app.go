@@ -0,0 +1,549 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"fetch-sketchfab-data/internal/alerts"
+	"fetch-sketchfab-data/internal/api"
+	"fetch-sketchfab-data/internal/config"
+	"fetch-sketchfab-data/internal/database"
+	"fetch-sketchfab-data/internal/events"
+	"fetch-sketchfab-data/internal/models"
+	"fetch-sketchfab-data/internal/notify"
+	"fetch-sketchfab-data/internal/report"
+	"fetch-sketchfab-data/internal/scheduler"
+	"fetch-sketchfab-data/internal/search"
+	"fetch-sketchfab-data/internal/service"
+	"fetch-sketchfab-data/internal/tracing"
+)
+
+// serviceName 日誌與追蹤使用的服務名稱
+const serviceName = "sketchfab-fetcher"
+
+// commonFlags 需要載入設定的子命令共用的參數
+type commonFlags struct {
+	configPath string
+	logLevel   string
+}
+
+// newFlagSet 建立子命令的參數集，usage 為參數摘要，description 為說明
+func newFlagSet(name, usage, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "使用方式: %s %s %s\n\n%s\n\n參數:\n", programName, name, usage, description)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// addCommonFlags 加入 -config 與 -log-level 參數
+func addCommonFlags(fs *flag.FlagSet) *commonFlags {
+	flags := &commonFlags{}
+	fs.StringVar(&flags.configPath, "config", os.Getenv("CONFIG_FILE"), "設定檔路徑（.yaml、.yml 或 .toml）")
+	fs.StringVar(&flags.logLevel, "log-level", "", "日誌等級，覆寫設定檔與 LOG_LEVEL")
+	return flags
+}
+
+// parseFlags 解析參數，參數錯誤時回傳已輸出的 exitUsage 錯誤
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		// flag 套件已輸出錯誤與使用說明
+		return &exitError{code: exitUsage, err: err, reported: true}
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("%s 不接受參數: %s", fs.Name(), strings.Join(fs.Args(), " "))
+	}
+	return nil
+}
+
+// app 子命令共用的相依元件，依需要建立並在 Close 時依相反順序釋放
+type app struct {
+	configPath string
+	overrides  func(*config.Config)
+	cfg        *config.Config
+
+	logService *service.LogService
+	mongo      *database.MongoDBClient
+	models     *service.ModelsService
+	runs       *service.RunsService
+	watermarks *service.WatermarksService
+	search     *search.Index
+	outbox     *service.OutboxService
+	subs       *service.SubscriptionsService
+	searches   *service.SavedSearchesService
+	downloads  *service.DownloadsService
+	client     *api.SketchfabClient
+
+	closers []func()
+}
+
+// newApp 載入設定：設定檔 → 環境變數 → 命令列參數（重新載入時同樣套用命令列參數）
+func newApp(flags *commonFlags, overrides ...func(*config.Config)) (*app, error) {
+	a := &app{configPath: flags.configPath}
+	a.overrides = func(c *config.Config) {
+		if flags.logLevel != "" {
+			c.Logstash.Level = flags.logLevel
+		}
+		for _, override := range overrides {
+			override(c)
+		}
+	}
+
+	cfg, err := config.Load(flags.configPath, a.overrides)
+	if err != nil {
+		return nil, withExitCode(exitConfig, fmt.Errorf("載入設定失敗: %v", err))
+	}
+	a.cfg = cfg
+	return a, nil
+}
+
+// Close 依建立的相反順序釋放資源
+func (a *app) Close() {
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		a.closers[i]()
+	}
+	a.closers = nil
+}
+
+// Logger 取得日誌服務
+func (a *app) Logger() (*service.LogService, error) {
+	if a.logService != nil {
+		return a.logService, nil
+	}
+	logService, err := newLogService(a.cfg.Logstash, serviceName)
+	if err != nil {
+		return nil, withExitCode(exitConfig, fmt.Errorf("建立日誌服務失敗: %v", err))
+	}
+	a.logService = logService
+	a.closers = append(a.closers, func() { logService.Close() })
+	return logService, nil
+}
+
+// StartTracing 啟用分散式追蹤，Close 時送出剩餘的 span
+func (a *app) StartTracing() error {
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    a.cfg.Tracing.Exporter,
+		Endpoint:    a.cfg.Tracing.Endpoint,
+		SampleRatio: a.cfg.Tracing.SampleRatio,
+		ServiceName: serviceName,
+	})
+	if err != nil {
+		return withExitCode(exitConfig, fmt.Errorf("啟用追蹤失敗: %v", err))
+	}
+	a.closers = append(a.closers, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("關閉追蹤時發生錯誤: %v", err)
+		}
+	})
+	return nil
+}
+
+// Mongo 取得 MongoDB 連線
+func (a *app) Mongo() (*database.MongoDBClient, error) {
+	if a.mongo != nil {
+		return a.mongo, nil
+	}
+	mongoClient, err := database.NewMongoDBClient(&database.MongoDBConfig{
+		URI:      a.cfg.MongoDB.ConnectionURI(),
+		Database: a.cfg.MongoDB.Database,
+		Timeout:  a.cfg.MongoDB.Timeout,
+	})
+	if err != nil {
+		return nil, withExitCode(exitUnavailable, fmt.Errorf("MongoDB連線失敗: %v", err))
+	}
+	a.mongo = mongoClient
+	a.closers = append(a.closers, func() {
+		if err := mongoClient.Close(); err != nil {
+			log.Printf("關閉MongoDB連線時發生錯誤: %v", err)
+		}
+	})
+	return mongoClient, nil
+}
+
+// Models 取得模型服務
+func (a *app) Models() (*service.ModelsService, error) {
+	if a.models != nil {
+		return a.models, nil
+	}
+	mongoClient, err := a.Mongo()
+	if err != nil {
+		return nil, err
+	}
+	a.models = service.NewModelsService(mongoClient)
+	if index := a.Search(); index != nil {
+		a.models.AddSink(index)
+	}
+	if a.cfg.Events.Enabled() {
+		outbox, err := a.Outbox()
+		if err != nil {
+			return nil, err
+		}
+		a.models.SetOutbox(outbox)
+	}
+	return a.models, nil
+}
+
+// Outbox 取得模型事件 outbox
+func (a *app) Outbox() (*service.OutboxService, error) {
+	if a.outbox != nil {
+		return a.outbox, nil
+	}
+	mongoClient, err := a.Mongo()
+	if err != nil {
+		return nil, err
+	}
+	a.outbox = service.NewOutboxService(mongoClient)
+	return a.outbox, nil
+}
+
+// Subscriptions 取得 webhook 訂閱服務
+func (a *app) Subscriptions() (*service.SubscriptionsService, error) {
+	if a.subs != nil {
+		return a.subs, nil
+	}
+	mongoClient, err := a.Mongo()
+	if err != nil {
+		return nil, err
+	}
+	a.subs = service.NewSubscriptionsService(mongoClient)
+	return a.subs, nil
+}
+
+// SavedSearches 取得儲存搜尋服務
+func (a *app) SavedSearches() (*service.SavedSearchesService, error) {
+	if a.searches != nil {
+		return a.searches, nil
+	}
+	mongoClient, err := a.Mongo()
+	if err != nil {
+		return nil, err
+	}
+	a.searches = service.NewSavedSearchesService(mongoClient)
+	return a.searches, nil
+}
+
+// Downloads 取得檔案封存下載紀錄服務
+func (a *app) Downloads() (*service.DownloadsService, error) {
+	if a.downloads != nil {
+		return a.downloads, nil
+	}
+	mongoClient, err := a.Mongo()
+	if err != nil {
+		return nil, err
+	}
+	a.downloads = service.NewDownloadsService(mongoClient)
+	return a.downloads, nil
+}
+
+// Notifier 建立通知 notifier，未設定 notify.smtp.host 與 notify.webhook_url 時回傳 nil
+func (a *app) Notifier() notify.Notifier {
+	cfg := a.cfg.Notify
+	var notifiers notify.Multi
+	if cfg.SMTP.Host != "" {
+		notifiers = append(notifiers, notify.NewSMTPNotifier(notify.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+			To:       cfg.SMTP.To,
+			Timeout:  cfg.Timeout,
+		}))
+	}
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, notify.NewWebhookNotifier(cfg.WebhookURL, cfg.Timeout))
+	}
+	switch len(notifiers) {
+	case 0:
+		return nil
+	case 1:
+		return notifiers[0]
+	default:
+		return notifiers
+	}
+}
+
+// dispatcherConfig 事件 dispatcher 與訂閱投遞共用的送出設定
+func (a *app) dispatcherConfig() events.DispatcherConfig {
+	cfg := a.cfg.Events
+	return events.DispatcherConfig{
+		BatchSize:      100,
+		PollInterval:   cfg.PollInterval,
+		MaxAttempts:    cfg.MaxAttempts,
+		RetryBaseDelay: cfg.RetryBaseDelay,
+		Lease:          100*cfg.Timeout + time.Minute,
+	}
+}
+
+// Dispatcher 建立模型事件 dispatcher，未設定 events.webhook_url 且未啟用訂閱時回傳 nil
+func (a *app) Dispatcher() (*events.Dispatcher, error) {
+	if !a.cfg.Events.Enabled() {
+		return nil, nil
+	}
+	logService, err := a.Logger()
+	if err != nil {
+		return nil, err
+	}
+	outbox, err := a.Outbox()
+	if err != nil {
+		return nil, err
+	}
+	cfg := a.cfg.Events
+	var publishers events.MultiPublisher
+	if cfg.WebhookURL != "" {
+		publishers = append(publishers, events.NewWebhookPublisher(cfg.WebhookURL, cfg.Timeout))
+	}
+	if cfg.Subscriptions {
+		subscriptions, err := a.Subscriptions()
+		if err != nil {
+			return nil, err
+		}
+		publishers = append(publishers, events.NewSubscriptionPublisher(subscriptions))
+	}
+	var publisher events.Publisher = publishers
+	if len(publishers) == 1 {
+		publisher = publishers[0]
+	}
+	modelsService, err := a.Models()
+	if err != nil {
+		return nil, err
+	}
+	dispatcher := events.NewDispatcher(outbox, publisher, logService, a.dispatcherConfig())
+	dispatcher.SetSweeper(modelsService)
+	return dispatcher, nil
+}
+
+// DeliveryWorker 建立訂閱投遞 worker，未啟用訂閱時回傳 nil
+func (a *app) DeliveryWorker() (*events.DeliveryWorker, error) {
+	if !a.cfg.Events.Subscriptions {
+		return nil, nil
+	}
+	logService, err := a.Logger()
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := a.Subscriptions()
+	if err != nil {
+		return nil, err
+	}
+	return events.NewDeliveryWorker(subscriptions, logService, a.cfg.Events.Timeout, a.dispatcherConfig()), nil
+}
+
+// Search 取得模型搜尋索引，未設定 elasticsearch.url 時回傳 nil
+func (a *app) Search() *search.Index {
+	if a.search == nil && a.cfg.Elasticsearch.URL != "" {
+		a.search = search.NewIndex(search.Config{
+			URL:      a.cfg.Elasticsearch.URL,
+			Index:    a.cfg.Elasticsearch.Index,
+			Username: a.cfg.Elasticsearch.Username,
+			Password: a.cfg.Elasticsearch.Password,
+			Timeout:  a.cfg.Elasticsearch.Timeout,
+		})
+	}
+	return a.search
+}
+
+// Runs 取得執行紀錄服務
+func (a *app) Runs() (*service.RunsService, error) {
+	if a.runs != nil {
+		return a.runs, nil
+	}
+	mongoClient, err := a.Mongo()
+	if err != nil {
+		return nil, err
+	}
+	a.runs = service.NewRunsService(mongoClient)
+	return a.runs, nil
+}
+
+// Watermarks 取得增量匯出進度服務
+func (a *app) Watermarks() (*service.WatermarksService, error) {
+	if a.watermarks != nil {
+		return a.watermarks, nil
+	}
+	mongoClient, err := a.Mongo()
+	if err != nil {
+		return nil, err
+	}
+	a.watermarks = service.NewWatermarksService(mongoClient)
+	return a.watermarks, nil
+}
+
+// Client 取得 Sketchfab API 客戶端
+func (a *app) Client() *api.SketchfabClient {
+	if a.client == nil {
+		a.client = newSketchfabClient(a.cfg.API)
+	}
+	return a.client
+}
+
+// Scheduler 依設定的任務建立排程器，每次執行都會寫入執行紀錄
+func (a *app) Scheduler() (*scheduler.DailyScheduler, error) {
+	logService, err := a.Logger()
+	if err != nil {
+		return nil, err
+	}
+	modelsService, err := a.Models()
+	if err != nil {
+		return nil, err
+	}
+	runsService, err := a.Runs()
+	if err != nil {
+		return nil, err
+	}
+	dailyScheduler := scheduler.NewDailySchedulerWithJobs(a.Client(), modelsService, logService, schedulerJobs(a.cfg))
+	dailyScheduler.SetRunsService(runsService)
+	if notifier := a.Notifier(); notifier != nil {
+		searches, err := a.SavedSearches()
+		if err != nil {
+			return nil, err
+		}
+		dailyScheduler.AddHook(alerts.NewSavedSearchAlerts(searches, modelsService, notifier, logService))
+	}
+	if cfg := a.cfg.Report; cfg.Enabled() {
+		var notifier notify.Notifier
+		if cfg.Notify {
+			notifier = a.Notifier()
+		}
+		dailyScheduler.AddHook(report.NewReporter(modelsService, cfg.Dir, notifier, logService))
+	}
+	return dailyScheduler, nil
+}
+
+// Reloader 建立設定重新載入器，套用可即時生效的設定：日誌等級、API 速率限制，以及排程模式下的排程時間與任務
+func (a *app) Reloader(dailyScheduler *scheduler.DailyScheduler) *config.Reloader {
+	logService, client := a.logService, a.Client()
+	return config.NewReloader(a.configPath, a.cfg, func(old, next *config.Config, changes []config.Change) {
+		if next.Logstash.Level != old.Logstash.Level && logService != nil {
+			if level, err := service.ParseLevel(next.Logstash.Level); err == nil {
+				logService.SetLevel(level)
+			}
+		}
+		if next.API.RateLimit != old.API.RateLimit {
+			client.RateLimiter.SetInterval(next.API.RateLimit)
+		}
+		if dailyScheduler != nil {
+			for _, change := range changes {
+				if strings.HasPrefix(change.Field, "schedule.") || strings.HasPrefix(change.Field, "jobs") {
+					dailyScheduler.SetJobs(schedulerJobs(next))
+					break
+				}
+			}
+		}
+	}, a.overrides)
+}
+
+// waitForShutdown 等待停止信號：SIGHUP 或設定檔變更時重新載入設定，SIGINT/SIGTERM 時返回；errChan 收到錯誤時回傳該錯誤
+func waitForShutdown(ctx context.Context, logService *service.LogService, reloader *config.Reloader, errChan <-chan error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	// 設定檔重新載入：SIGHUP 或偵測到檔案變更
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	defer signal.Stop(hupChan)
+	go reloader.Watch(ctx, reloader.Current().Reload.WatchInterval, reloadHandler(logService))
+
+	for {
+		select {
+		case <-hupChan:
+			logService.Info("收到 SIGHUP，重新載入設定檔...")
+			reloadHandler(logService)(reloader.Reload())
+		case <-sigChan:
+			logService.Info("收到停止信號，正在關閉...")
+			return nil
+		case err := <-errChan:
+			return err
+		}
+	}
+}
+
+// reloadHandler 回傳記錄重新載入結果的函式：列出已套用的變更，以及需要重新啟動才會生效的欄位
+func reloadHandler(logService *service.LogService) func(changes []config.Change, err error) {
+	return func(changes []config.Change, err error) {
+		if err != nil {
+			logService.Error("❌ 新設定無效，繼續使用目前的設定", "error", err)
+			return
+		}
+		if len(changes) == 0 {
+			logService.Info("設定檔沒有變更")
+			return
+		}
+
+		var applied, restart []string
+		for _, change := range changes {
+			if change.Live {
+				applied = append(applied, change.String())
+			} else {
+				restart = append(restart, change.String())
+			}
+		}
+		logService.Info(fmt.Sprintf("🔁 設定已重新載入，%d 項變更已套用", len(applied)), "changes", applied)
+		if len(restart) > 0 {
+			logService.Warn(fmt.Sprintf("⚠️ %d 項變更需要重新啟動才會生效", len(restart)), "pending_changes", restart)
+		}
+	}
+}
+
+// newSketchfabClient 依設定建立 Sketchfab API 客戶端
+func newSketchfabClient(cfg config.APIConfig) *api.SketchfabClient {
+	client := api.NewSketchfabClient()
+	client.BaseURL = cfg.BaseURL
+	client.APIKey = cfg.SketchfabAPIKey
+	client.HTTPClient.Timeout = cfg.Timeout
+	client.MaxRetries = cfg.MaxRetries
+	client.RetryBaseDelay = cfg.RetryBaseDelay
+	client.RateLimiter.SetInterval(cfg.RateLimit)
+	return client
+}
+
+// schedulerJobs 將設定檔中的任務轉為排程任務
+func schedulerJobs(cfg *config.Config) []*scheduler.Job {
+	jobs := make([]*scheduler.Job, 0, len(cfg.Jobs))
+	for _, job := range cfg.Jobs {
+		params := &models.GetModelsParams{
+			Downloadable:     job.Downloadable,
+			ArchivesFlavours: job.ArchivesFlavours,
+		}
+		if job.Count > 0 {
+			params.Count = &job.Count
+		}
+		if job.Sort != "" {
+			params.Sort = &job.Sort
+		}
+		if job.Categories != "" {
+			params.Categories = &job.Categories
+		}
+		if job.Tags != "" {
+			params.Tags = &job.Tags
+		}
+		if job.Search != "" {
+			params.Search = &job.Search
+		}
+		jobs = append(jobs, &scheduler.Job{
+			Name:     job.Name,
+			Time:     cfg.ScheduleTimeOf(job),
+			Params:   params,
+			MaxPages: job.MaxPages,
+		})
+	}
+	return jobs
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"fetch-sketchfab-data/internal/config"
+)
+
+// runConfigCommand 執行 config print 或 config validate
+func runConfigCommand(args []string) error {
+	fs := newFlagSet("config", "print|validate [-config=config.yaml] [-format=yaml|toml]",
+		"print 輸出疊加設定檔與環境變數後實際生效的設定，機密欄位會被遮蔽；validate 只檢查設定是否有效。")
+	configPath := fs.String("config", os.Getenv("CONFIG_FILE"), "設定檔路徑（.yaml、.yml 或 .toml）")
+	format := fs.String("format", "yaml", "輸出格式: yaml 或 toml（僅 print）")
+	if len(args) == 0 || (args[0] != "print" && args[0] != "validate") {
+		if len(args) > 0 && isHelpFlag(args[0]) {
+			fs.Usage()
+			return nil
+		}
+		fs.Usage()
+		return &exitError{code: exitUsage, err: fmt.Errorf("請指定 print 或 validate"), reported: true}
+	}
+	action := args[0]
+	if err := parseFlags(fs, args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return withExitCode(exitConfig, fmt.Errorf("載入設定失敗: %v", err))
+	}
+	if action == "validate" {
+		fmt.Println("✅ 設定有效")
+		return nil
+	}
+	if err := config.Encode(os.Stdout, cfg.Redacted(), *format); err != nil {
+		return withExitCode(exitUsage, err)
+	}
+	return nil
+}
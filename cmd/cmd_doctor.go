@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"fetch-sketchfab-data/internal/config"
+	"fetch-sketchfab-data/internal/server"
+)
+
+// doctorResult 單一檢查項目的結果
+type doctorResult struct {
+	name    string
+	status  string // server.HealthOK / HealthDegraded / HealthFail
+	latency time.Duration
+	detail  string
+}
+
+// runDoctorCommand 檢查設定、本機目錄與相依服務，任一必要項目失敗時以代碼 5 結束
+func runDoctorCommand(args []string) error {
+	fs := newFlagSet("doctor", "[-config=config.yaml]",
+		"檢查設定是否有效、日誌暫存與檔案目錄是否可寫入，以及 MongoDB、Logstash、Sketchfab API、Elasticsearch（有設定時）與最近一次同步的狀態。")
+	flags := addCommonFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	var results []doctorResult
+	defer func() { printDoctorResults(results) }()
+
+	a, err := newApp(flags)
+	if err != nil {
+		results = append(results, doctorResult{name: "config", status: server.HealthFail, detail: err.Error()})
+		return &exitError{code: exitUnhealthy, err: err, reported: true}
+	}
+	defer a.Close()
+	results = append(results, configResult(a.cfg, flags.configPath))
+	results = append(results, directoryResults(a.cfg.Logstash)...)
+
+	// 相依服務沿用 /readyz 的檢查
+	var checks []server.HealthCheck
+	if mongoClient, err := a.Mongo(); err != nil {
+		results = append(results, doctorResult{name: "mongodb", status: server.HealthFail, detail: err.Error()})
+	} else {
+		runsService, _ := a.Runs()
+		checks = append(checks,
+			server.MongoHealthCheck(mongoClient),
+			server.SyncFreshnessHealthCheck(runsService, a.cfg.Server.MaxSyncAge),
+		)
+		if a.cfg.Events.Enabled() {
+			outbox, _ := a.Outbox()
+			checks = append(checks, server.EventOutboxHealthCheck(outbox))
+		}
+	}
+	if logService, err := a.Logger(); err != nil {
+		results = append(results, doctorResult{name: "logging", status: server.HealthFail, detail: err.Error()})
+	} else {
+		checks = append(checks, server.LogstashHealthCheck(logService))
+	}
+	checks = append(checks, server.SketchfabHealthCheck(a.Client()))
+	if index := a.Search(); index != nil {
+		checks = append(checks, server.ElasticsearchHealthCheck(index))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	report := server.NewHealthHandler(checks...).Check(ctx)
+	for _, check := range checks {
+		result := report.Checks[check.Name]
+		detail := result.Error
+		if detail == "" {
+			detail = formatDetails(result.Details)
+		}
+		results = append(results, doctorResult{
+			name:    check.Name,
+			status:  result.Status,
+			latency: time.Duration(result.LatencyMs) * time.Millisecond,
+			detail:  detail,
+		})
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.status == server.HealthFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return withExitCode(exitUnhealthy, fmt.Errorf("%d 項檢查未通過", failed))
+	}
+	return nil
+}
+
+// configResult 設定檢查結果，未設定 API 金鑰時視為 degraded
+func configResult(cfg *config.Config, path string) doctorResult {
+	source := path
+	if source == "" {
+		source = "環境變數"
+	}
+	result := doctorResult{
+		name:   "config",
+		status: server.HealthOK,
+		detail: fmt.Sprintf("來源=%s 任務=%d", source, len(cfg.Jobs)),
+	}
+	if cfg.API.SketchfabAPIKey == "" {
+		result.status = server.HealthDegraded
+		result.detail += "，未設定 SKETCHFAB_API_KEY（僅能存取公開資料，無法使用 download）"
+	}
+	return result
+}
+
+// directoryResults 檢查日誌暫存檔與日誌檔所在目錄是否可寫入
+func directoryResults(cfg config.LogstashConfig) []doctorResult {
+	var results []doctorResult
+	for _, sink := range cfg.Sinks {
+		switch {
+		case sink == "tcp" && cfg.SpoolMaxMB > 0:
+			results = append(results, writableResult("spool_dir", filepath.Dir(cfg.SpoolPath), server.HealthDegraded))
+		case sink == "file":
+			results = append(results, writableResult("log_file_dir", filepath.Dir(cfg.FilePath), server.HealthFail))
+		}
+	}
+	return results
+}
+
+// writableResult 建立目錄並寫入暫存檔，確認目錄可寫入；失敗時的狀態為 failStatus
+func writableResult(name, dir, failStatus string) doctorResult {
+	result := doctorResult{name: name, status: server.HealthOK, detail: dir}
+	err := os.MkdirAll(dir, 0o755)
+	if err == nil {
+		var file *os.File
+		file, err = os.CreateTemp(dir, ".doctor-*")
+		if err == nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}
+	if err != nil {
+		result.status = failStatus
+		result.detail = fmt.Sprintf("%s 無法寫入: %v", dir, err)
+	}
+	return result
+}
+
+// formatDetails 將檢查細節依鍵排序後以 key=value 表示
+func formatDetails(details map[string]interface{}) string {
+	keys := make([]string, 0, len(details))
+	for key := range details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if value := details[key]; value != nil {
+			parts = append(parts, fmt.Sprintf("%s=%v", key, value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// printDoctorResults 以表格輸出檢查結果
+func printDoctorResults(results []doctorResult) {
+	icons := map[string]string{server.HealthOK: "✅", server.HealthDegraded: "⚠️", server.HealthFail: "❌"}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, result := range results {
+		latency := ""
+		if result.latency > 0 {
+			latency = result.latency.String()
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%s\n", icons[result.status], result.name, latency, result.detail)
+	}
+	w.Flush()
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"fetch-sketchfab-data/internal/config"
+	"fetch-sketchfab-data/internal/download"
+	"fetch-sketchfab-data/internal/server"
+	"fetch-sketchfab-data/internal/service"
+)
+
+// runDownloadCommand 透過 Sketchfab 下載 API 下載模型的檔案封存
+func runDownloadCommand(args []string) error {
+	fs := newFlagSet("download", "[-id=uid,...] [-formats=glb,usdz] [-dir=downloads] [-concurrency=2] [查詢條件] [-config=config.yaml]",
+		"以 API token 呼叫下載 API，將符合條件的可下載模型的檔案封存寫入下載目錄，並在 downloads 集合記錄每個模型的下載狀態。\n"+
+			"檔案大小必須與模型資料的 Archive.Size 相符；中斷後重新執行會從 .part 檔續傳，已完成的檔案不會重新下載（除非指定 -force）。\n"+
+			"查詢條件與 GET /models 相同。")
+	flags := addCommonFlags(fs)
+	ids := fs.String("id", "", "只下載指定的模型，多個 uid 以逗號分隔")
+	formats := fs.String("formats", "", "下載的格式，多個以逗號分隔: "+strings.Join(download.Formats, "、")+"（覆寫 download.formats）")
+	dir := fs.String("dir", "", "下載目錄，覆寫設定檔的 download.dir")
+	concurrency := fs.Int("concurrency", 0, "同時下載的模型數，覆寫設定檔的 download.concurrency")
+	maxSize := fs.Int("max-size-mb", -1, "略過超過此大小（MB）的檔案，0 表示不限制，覆寫設定檔的 download.max_size_mb")
+	force := fs.Bool("force", false, "已完成的檔案也重新下載")
+	status := fs.Bool("status", false, "只列出各格式的下載狀態統計")
+	filterValues := addFilterFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	filter, err := server.ParseModelFilter(filterValues())
+	if err != nil {
+		return withExitCode(exitUsage, err)
+	}
+	if *ids != "" {
+		for _, id := range strings.Split(*ids, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				filter.IDs = append(filter.IDs, id)
+			}
+		}
+	}
+
+	a, err := newApp(flags, func(c *config.Config) {
+		if *formats != "" {
+			c.Download.Formats = nil
+			for _, format := range strings.Split(*formats, ",") {
+				c.Download.Formats = append(c.Download.Formats, strings.TrimSpace(format))
+			}
+		}
+		if *dir != "" {
+			c.Download.Dir = *dir
+		}
+		if *concurrency > 0 {
+			c.Download.Concurrency = *concurrency
+		}
+		if *maxSize >= 0 {
+			c.Download.MaxSizeMB = *maxSize
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	downloads, err := a.Downloads()
+	if err != nil {
+		return err
+	}
+	if *status {
+		return printDownloadStatus(downloads)
+	}
+
+	cfg := a.cfg.Download
+	if a.cfg.API.SketchfabAPIKey == "" {
+		return withExitCode(exitConfig, fmt.Errorf("下載需要 API token: 請設定 api.sketchfab_api_key（或 SKETCHFAB_API_KEY）"))
+	}
+	logService, err := a.Logger()
+	if err != nil {
+		return err
+	}
+	modelsService, err := a.Models()
+	if err != nil {
+		return err
+	}
+
+	// 中斷時停止下載，已下載的部分留待下次續傳
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	downloader := download.NewDownloader(a.Client(), downloads, logService, download.Config{
+		Dir:         cfg.Dir,
+		Formats:     cfg.Formats,
+		Concurrency: cfg.Concurrency,
+		Timeout:     cfg.Timeout,
+		MaxSize:     int64(cfg.MaxSizeMB) << 20,
+		Force:       *force,
+	})
+	startedAt := time.Now()
+	result, err := downloader.Run(ctx, modelsService, filter)
+	if result != nil {
+		fmt.Fprintf(os.Stderr, "%d 個模型：完成 %d、略過 %d、失敗 %d 個檔案，下載 %.1f MB（%s）\n",
+			result.Models, result.Completed, result.Skipped, result.Failed,
+			float64(result.Bytes)/(1<<20), time.Since(startedAt).Round(time.Second))
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("下載已中斷，重新執行即可續傳")
+		}
+		return err
+	}
+	if result.Failed > 0 {
+		return fmt.Errorf("%d 個檔案下載失敗，詳細原因見日誌或 download -status", result.Failed)
+	}
+	fmt.Fprintf(os.Stderr, "✅ 下載完成：%s\n", cfg.Dir)
+	return nil
+}
+
+// printDownloadStatus 列出各格式的下載狀態統計
+func printDownloadStatus(downloads *service.DownloadsService) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	counts, err := downloads.CountByStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if len(counts) == 0 {
+		fmt.Println("尚無下載紀錄")
+		return nil
+	}
+
+	formats := make([]string, 0, len(counts))
+	for format := range counts {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	statuses := []string{service.DownloadCompleted, service.DownloadInProgress, service.DownloadFailed, service.DownloadSkipped}
+	fmt.Printf("%-8s %10s %10s %10s %10s\n", "格式", statuses[0], statuses[1], statuses[2], statuses[3])
+	for _, format := range formats {
+		fmt.Printf("%-8s %10d %10d %10d %10d\n", format,
+			counts[format][statuses[0]], counts[format][statuses[1]], counts[format][statuses[2]], counts[format][statuses[3]])
+	}
+	return nil
+}
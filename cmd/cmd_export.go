@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fetch-sketchfab-data/internal/export"
+	"fetch-sketchfab-data/internal/server"
+	"fetch-sketchfab-data/internal/service"
+	"fetch-sketchfab-data/internal/site"
+)
+
+// filterFlags 與模型資料庫 API 相同的查詢條件（參數名稱 → 查詢參數名稱）
+var filterFlags = []struct {
+	name, query, usage string
+}{
+	{"tag", "tag", "標籤 slug 或名稱"},
+	{"category", "category", "分類名稱"},
+	{"license", "license", "授權 uid 或名稱"},
+	{"user", "user", "作者 uid 或帳號"},
+	{"downloadable", "downloadable", "是否可下載: true 或 false"},
+	{"created-after", "created_after", "建立時間下限（RFC3339 或 YYYY-MM-DD）"},
+	{"created-before", "created_before", "建立時間上限（RFC3339 或 YYYY-MM-DD）"},
+	{"min-views", "min_views", "最少瀏覽數"},
+	{"max-views", "max_views", "最多瀏覽數"},
+	{"min-likes", "min_likes", "最少喜歡數"},
+	{"max-likes", "max_likes", "最多喜歡數"},
+	{"sort", "sort", "排序欄位，前綴 - 表示遞減，例如 -like_count（預設 -fetched_at）"},
+	{"limit", "limit", "最多匯出幾筆（預設不限制）"},
+}
+
+// siteFormat 產生靜態網站的匯出格式，輸出為目錄而非單一檔案
+const siteFormat = "site"
+
+// runExportCommand 以 CSV、NDJSON 或 Parquet 串流匯出模型資料，或產生靜態網站
+func runExportCommand(args []string) error {
+	fs := newFlagSet("export", "[-format=csv|ndjson|parquet|site] [-output=檔案或目錄] [-since=時間 | -watermark=名稱] [查詢條件] [-config=config.yaml]",
+		"以資料庫游標逐筆匯出模型，不會一次載入全部資料；查詢條件與 GET /models 相同。\n"+
+			"csv 為展開後的欄位，ndjson 為完整文件（含原始 API 資料），parquet 含型別與巢狀的標籤、檔案封存欄位。\n"+
+			"指定 -since 或 -watermark 時只匯出期間內新增或變更的模型，並先輸出期間內的刪除紀錄。\n"+
+			"site 在 -output 目錄產生可離線瀏覽的靜態網站：依標籤、分類、授權、作者的索引頁、每個模型的詳細頁與搜尋。")
+	flags := addCommonFlags(fs)
+	format := fs.String("format", "ndjson", "匯出格式: "+strings.Join(append(export.Formats, siteFormat), "、"))
+	output := fs.String("output", "-", "輸出檔案路徑，- 表示標準輸出；site 格式為輸出目錄")
+	title := fs.String("title", "Sketchfab 模型目錄", "site 格式的網站標題")
+	since := fs.String("since", "", "只匯出此時間之後新增或變更的模型（RFC3339、YYYY-MM-DD，或 24h 等相對時間）")
+	watermarkName := fs.String("watermark", "", "具名的匯出進度：從上次成功匯出的時間開始，完成後更新進度")
+	filterValues := addFilterFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	filter, err := server.ParseModelFilter(filterValues())
+	if err != nil {
+		return withExitCode(exitUsage, err)
+	}
+	filter.WithRawData = true
+	var from *time.Time
+	if *since != "" {
+		if from, err = parseSince(*since); err != nil {
+			return withExitCode(exitUsage, err)
+		}
+	}
+	if *watermarkName != "" && filter.Limit > 0 {
+		// 截斷的匯出若更新進度，未匯出的模型就不會再被匯出
+		return usageErrorf("-watermark 不可與 -limit 同時使用")
+	}
+	if *format == siteFormat {
+		if *output == "-" {
+			return usageErrorf("site 格式須以 -output 指定輸出目錄")
+		}
+		if *since != "" || *watermarkName != "" {
+			return usageErrorf("site 格式不支援 -since 與 -watermark")
+		}
+	}
+	if *output == "-" && *format == "parquet" && isTerminal(os.Stdout) {
+		return usageErrorf("Parquet 為二進位格式，請以 -output 指定檔案或導向到其他程式")
+	}
+
+	a, err := newApp(flags)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	modelsService, err := a.Models()
+	if err != nil {
+		return err
+	}
+
+	if *format == siteFormat {
+		return exportSite(modelsService, filter, *output, *title)
+	}
+
+	// 增量匯出的範圍為 [from, upTo)，upTo 在查詢前決定，期間內變更的模型留待下次匯出；
+	// 進度只記錄到 upTo 減去 ChangedAtLag，讓下次重新涵蓋 changed_at 已決定但尚未寫入的模型
+	upTo := time.Now()
+	var watermarks *service.WatermarksService
+	if *watermarkName != "" {
+		if watermarks, err = a.Watermarks(); err != nil {
+			return err
+		}
+		watermark, err := watermarks.GetWatermark(context.Background(), *watermarkName)
+		if err != nil {
+			return err
+		}
+		if watermark != nil && from == nil {
+			from = &watermark.ExportedUpTo
+		}
+	}
+	incremental := from != nil
+	if incremental {
+		filter.ChangedSince = from
+		filter.ChangedBefore = &upTo
+		if filter.Sort == "" {
+			filter.Sort = "changed_at"
+		}
+	}
+
+	// 寫入檔案時先寫到暫存檔，完成後再改名，避免失敗時留下不完整的檔案
+	var out io.Writer = os.Stdout
+	var tmp *os.File
+	if *output != "-" {
+		tmp, err = os.CreateTemp(filepath.Dir(*output), "."+filepath.Base(*output)+".*")
+		if err != nil {
+			return fmt.Errorf("建立輸出檔案失敗: %v", err)
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		out = tmp
+	}
+
+	writer, err := export.NewWriter(*format, out)
+	if err != nil {
+		return withExitCode(exitUsage, err)
+	}
+
+	// 先輸出刪除紀錄：期間內先刪除又重新加入的模型，後面的模型資料會覆蓋刪除
+	tombstones := 0
+	if incremental {
+		tombstones, err = modelsService.StreamTombstones(context.Background(), from, &upTo, writer.WriteTombstone)
+		if err != nil {
+			return fmt.Errorf("匯出刪除紀錄失敗: %v", err)
+		}
+	}
+	count, err := modelsService.StreamModels(context.Background(), filter, writer.Write)
+	if err != nil {
+		return fmt.Errorf("匯出失敗（已寫入 %d 筆）: %v", count, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("寫入匯出資料失敗: %v", err)
+	}
+
+	if tmp != nil {
+		if err := tmp.Close(); err != nil {
+			return fmt.Errorf("寫入匯出資料失敗: %v", err)
+		}
+		if err := os.Rename(tmp.Name(), *output); err != nil {
+			return fmt.Errorf("建立輸出檔案失敗: %v", err)
+		}
+	}
+
+	// 輸出完整寫出後才更新進度，失敗時下次會重新匯出同一範圍（至少一次）
+	if watermarks != nil {
+		exportedUpTo := upTo.Add(-service.ChangedAtLag)
+		if from != nil && exportedUpTo.Before(*from) {
+			exportedUpTo = *from
+		}
+		err := watermarks.SaveWatermark(context.Background(), &service.Watermark{
+			Name:         *watermarkName,
+			ExportedUpTo: exportedUpTo,
+			Models:       count,
+			Tombstones:   tombstones,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if incremental {
+		fmt.Fprintf(os.Stderr, "✅ 已匯出 %s 至 %s 間新增或變更的 %d 個模型、%d 筆刪除紀錄（%s）\n",
+			from.Local().Format(time.RFC3339), upTo.Local().Format(time.RFC3339), count, tombstones, *format)
+	} else {
+		fmt.Fprintf(os.Stderr, "✅ 已匯出 %d 個模型（%s）\n", count, *format)
+	}
+	return nil
+}
+
+// exportSite 將符合條件的模型產生為靜態網站
+//
+// 先產生到同層的暫存目錄，完成後才取代 output；output 已存在時必須是空目錄或先前產生的網站，避免誤刪其他檔案。
+func exportSite(modelsService *service.ModelsService, filter *service.ModelFilter, output, title string) error {
+	output = filepath.Clean(output)
+	if entries, err := os.ReadDir(output); err == nil && len(entries) > 0 {
+		if _, err := os.Stat(filepath.Join(output, site.IndexFile)); err != nil {
+			return usageErrorf("輸出目錄 %s 不是空目錄，也不是先前產生的網站", output)
+		}
+	} else if err != nil && !os.IsNotExist(err) {
+		return withExitCode(exitUsage, fmt.Errorf("輸出目錄無法使用: %v", err))
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(output), "."+filepath.Base(output)+".*")
+	if err != nil {
+		return fmt.Errorf("建立輸出目錄失敗: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	generator, err := site.NewGenerator(tmp, title)
+	if err != nil {
+		return err
+	}
+	count, err := modelsService.StreamModels(context.Background(), filter, generator.Write)
+	if err != nil {
+		return fmt.Errorf("匯出失敗（已寫入 %d 筆）: %v", count, err)
+	}
+	if err := generator.Close(); err != nil {
+		return err
+	}
+	// MkdirTemp 建立的目錄權限為 0700，改為一般目錄的權限以便網頁伺服器讀取
+	if err := os.Chmod(tmp, 0o755); err != nil {
+		return fmt.Errorf("建立輸出目錄失敗: %v", err)
+	}
+	if err := os.RemoveAll(output); err != nil {
+		return fmt.Errorf("移除舊的網站失敗: %v", err)
+	}
+	if err := os.Rename(tmp, output); err != nil {
+		return fmt.Errorf("建立輸出目錄失敗: %v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "✅ 已產生 %d 個模型的靜態網站：%s\n", count, filepath.Join(output, "index.html"))
+	return nil
+}
+
+// parseSince 解析 -since：RFC3339、YYYY-MM-DD，或 24h、90m 等相對於現在的時間
+func parseSince(value string) (*time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		t := time.Now().Add(-d)
+		return &t, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("-since 格式錯誤: %s（請使用 RFC3339、YYYY-MM-DD 或 24h 等相對時間）", value)
+}
+
+// addFilterFlags 加入模型查詢條件參數，回傳取得對應查詢參數的函式
+func addFilterFlags(fs *flag.FlagSet) func() url.Values {
+	values := make([]*string, len(filterFlags))
+	for i, f := range filterFlags {
+		values[i] = fs.String(f.name, "", f.usage)
+	}
+	return func() url.Values {
+		query := url.Values{}
+		for i, f := range filterFlags {
+			if *values[i] != "" {
+				query.Set(f.query, *values[i])
+			}
+		}
+		return query
+	}
+}
+
+// isTerminal 判斷檔案是否為終端機
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// migrateStep 一組要建立的索引
+type migrateStep struct {
+	name   string
+	ensure func(ctx context.Context) ([]string, error)
+}
+
+// runMigrateCommand 建立所有集合的索引，已存在的索引不會重複建立
+func runMigrateCommand(args []string) error {
+	fs := newFlagSet("migrate", "[-config=config.yaml]",
+		"建立 models、model_history、sync_runs、event_outbox、webhook 訂閱與 saved_searches 集合的索引，以及設定 Elasticsearch 時的模型搜尋索引；\n"+
+			"可重複執行，已存在的索引不會重複建立。")
+	flags := addCommonFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	a, err := newApp(flags)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	modelsService, err := a.Models()
+	if err != nil {
+		return err
+	}
+	runsService, err := a.Runs()
+	if err != nil {
+		return err
+	}
+	outbox, err := a.Outbox()
+	if err != nil {
+		return err
+	}
+	subscriptions, err := a.Subscriptions()
+	if err != nil {
+		return err
+	}
+	searches, err := a.SavedSearches()
+	if err != nil {
+		return err
+	}
+	downloads, err := a.Downloads()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	steps := []migrateStep{
+		{"models / model_history", modelsService.EnsureIndexes},
+		{"sync_runs", runsService.EnsureIndexes},
+		{"event_outbox", outbox.EnsureIndexes},
+		{"webhook_subscriptions / webhook_deliveries / webhook_delivery_log", subscriptions.EnsureIndexes},
+		{"saved_searches", searches.EnsureIndexes},
+		{"downloads", downloads.EnsureIndexes},
+	}
+	if index := a.Search(); index != nil {
+		steps = append(steps, migrateStep{"elasticsearch " + index.Alias(), index.EnsureIndex})
+	}
+	for _, step := range steps {
+		names, err := step.ensure(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ %s: %s\n", step.name, strings.Join(names, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// runReindexCommand 從 MongoDB 重建 Elasticsearch 模型搜尋索引
+func runReindexCommand(args []string) error {
+	fs := newFlagSet("reindex", "[-batch-size=500] [-config=config.yaml]",
+		"將 models 集合的所有模型寫入新的 Elasticsearch 索引，完成後切換索引別名並刪除舊索引；\n"+
+			"重建期間搜尋仍使用舊索引，期間的新增、變更與刪除會在切換後補上。")
+	flags := addCommonFlags(fs)
+	batchSize := fs.Int("batch-size", 500, "每次 bulk 請求的模型數")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *batchSize <= 0 {
+		return usageErrorf("-batch-size 必須大於 0")
+	}
+
+	a, err := newApp(flags)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	index := a.Search()
+	if index == nil {
+		return withExitCode(exitConfig, errors.New("未設定 elasticsearch.url（或 ELASTICSEARCH_URL）"))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if err := index.Ping(ctx); err != nil {
+		return withExitCode(exitUnavailable, fmt.Errorf("Elasticsearch 連線失敗: %v", err))
+	}
+	modelsService, err := a.Models()
+	if err != nil {
+		return err
+	}
+
+	startedAt := time.Now()
+	result, err := index.Reindex(ctx, modelsService, *batchSize)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已重建索引 %s → %s：%d 個模型，補寫 %d 個、補刪 %d 個（%s）\n",
+		index.Alias(), result.Index, result.Indexed, result.CaughtUp, result.Removed,
+		time.Since(startedAt).Round(time.Millisecond))
+	if len(result.Dropped) > 0 {
+		fmt.Printf("🗑️ 已刪除舊索引: %s\n", strings.Join(result.Dropped, ", "))
+	}
+	return nil
+}
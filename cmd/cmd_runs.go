@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// runRunsCommand 列出同步任務的執行紀錄
+func runRunsCommand(args []string) error {
+	fs := newFlagSet("runs", "[-job=名稱] [-status=done|failed] [-limit=20] [-json] [-config=config.yaml]",
+		"列出 sync 與 schedule 寫入的執行紀錄（sync_runs 集合），由新到舊排列。")
+	flags := addCommonFlags(fs)
+	job := fs.String("job", "", "只列出指定任務的紀錄")
+	status := fs.String("status", "", "只列出指定狀態的紀錄: done 或 failed")
+	limit := fs.Int("limit", 20, "最多列出幾筆")
+	asJSON := fs.Bool("json", false, "以 JSON 輸出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *status != "" && *status != "done" && *status != "failed" {
+		return usageErrorf("-status 無效: %q（可用: done、failed）", *status)
+	}
+	if *limit <= 0 {
+		return usageErrorf("-limit 必須大於 0")
+	}
+
+	a, err := newApp(flags)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	runsService, err := a.Runs()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	runs, err := runsService.ListRuns(ctx, &service.RunFilter{JobName: *job, Status: *status, Limit: *limit})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(runs)
+	}
+	if len(runs) == 0 {
+		fmt.Println("沒有執行紀錄")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "開始時間\t任務\t觸發\t狀態\t耗時\t頁數\t取得\t新增\t更新\t未變更\t錯誤")
+	for _, run := range runs {
+		state := "✅"
+		if run.Status == "failed" {
+			state = "❌"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			run.StartedAt.Local().Format("2006-01-02 15:04:05"),
+			run.JobName,
+			run.Trigger,
+			state,
+			(time.Duration(run.DurationMs) * time.Millisecond).Round(time.Second),
+			run.Pages,
+			run.FetchedCount,
+			run.InsertedCount,
+			run.UpdatedCount,
+			run.UnchangedCount,
+			run.Error,
+		)
+	}
+	return w.Flush()
+}
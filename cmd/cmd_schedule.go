@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fetch-sketchfab-data/internal/config"
+	"fetch-sketchfab-data/internal/metrics"
+	"fetch-sketchfab-data/internal/server"
+)
+
+// runScheduleCommand 執行排程器模式：依排程同步，並提供指標、健康檢查與控制 API
+func runScheduleCommand(args []string) error {
+	fs := newFlagSet("schedule", "[-time=HH:MM] [-addr=:8080] [-config=config.yaml]",
+		"啟動時先同步一次，之後每天在各任務的時間執行；SIGHUP 或設定檔變更時重新載入設定。")
+	flags := addCommonFlags(fs)
+	scheduleTime := fs.String("time", "", "排程執行時間 (格式: HH:MM, 24小時制)，覆寫設定檔的 schedule.time")
+	addr := fs.String("addr", "", "HTTP 伺服器位址，覆寫設定檔的 server.addr")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	a, err := newApp(flags, func(c *config.Config) {
+		if *scheduleTime != "" {
+			c.Schedule.Time = *scheduleTime
+		}
+		if *addr != "" {
+			c.Server.Addr = *addr
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	logService, err := a.Logger()
+	if err != nil {
+		return err
+	}
+	if err := a.StartTracing(); err != nil {
+		return err
+	}
+	mongoClient, err := a.Mongo()
+	if err != nil {
+		return err
+	}
+	modelsService, err := a.Models()
+	if err != nil {
+		return err
+	}
+	runsService, err := a.Runs()
+	if err != nil {
+		return err
+	}
+	dailyScheduler, err := a.Scheduler()
+	if err != nil {
+		return err
+	}
+	reloader := a.Reloader(dailyScheduler)
+
+	// 輸出啟動訊息到標準輸出
+	fmt.Printf("⏰ 啟動每日排程模式，執行時間: %s\n", a.cfg.Schedule.Time)
+	logService.Info("⏰ 啟動每日排程模式", "schedule_time", a.cfg.Schedule.Time, "jobs", len(a.cfg.Jobs))
+
+	// 啟動 HTTP 伺服器（指標、健康檢查，以及設定 token 時的排程器控制 API）
+	serverConfig := a.cfg.Server
+	httpServer := server.NewServer(serverConfig.Addr, logService)
+	httpServer.Handle("GET /metrics", metrics.Handler())
+	checks := []server.HealthCheck{
+		server.MongoHealthCheck(mongoClient),
+		server.LogstashHealthCheck(logService),
+		server.SketchfabHealthCheck(a.Client()),
+		server.SyncFreshnessHealthCheck(runsService, serverConfig.MaxSyncAge),
+	}
+	if index := a.Search(); index != nil {
+		checks = append(checks, server.ElasticsearchHealthCheck(index))
+	}
+	if a.cfg.Events.Enabled() {
+		outbox, err := a.Outbox()
+		if err != nil {
+			return err
+		}
+		checks = append(checks, server.EventOutboxHealthCheck(outbox))
+	}
+	server.NewHealthHandler(checks...).Register(httpServer)
+	if serverConfig.ControlToken == "" {
+		logService.Warn("未設定 CONTROL_API_TOKEN，排程器控制 API 未啟用")
+	} else {
+		server.NewControlHandlerWithModels(dailyScheduler, modelsService, serverConfig.ControlToken).Register(httpServer)
+		searches, err := a.SavedSearches()
+		if err != nil {
+			return err
+		}
+		server.NewSearchesHandler(searches, modelsService, serverConfig.ControlToken).Register(httpServer)
+		if a.cfg.Events.Subscriptions {
+			subscriptions, err := a.Subscriptions()
+			if err != nil {
+				return err
+			}
+			server.NewSubscriptionsHandler(subscriptions, serverConfig.ControlToken).Register(httpServer)
+		}
+	}
+	if err := httpServer.Start(); err != nil {
+		return err
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer shutdownCancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logService.Error("關閉 HTTP 伺服器失敗", "error", err)
+		}
+	}()
+
+	// 在 goroutine 中啟動排程器
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- dailyScheduler.Start(ctx)
+	}()
+
+	// 在背景送出模型變更事件與訂閱投遞
+	dispatcher, err := a.Dispatcher()
+	if err != nil {
+		return err
+	}
+	if dispatcher != nil {
+		go dispatcher.Run(ctx)
+	}
+	worker, err := a.DeliveryWorker()
+	if err != nil {
+		return err
+	}
+	if worker != nil {
+		go worker.Run(ctx)
+	}
+
+	if err := waitForShutdown(ctx, logService, reloader, errChan); err != nil {
+		logService.Error("排程器執行失敗", "error", err)
+		return fmt.Errorf("排程器執行失敗: %v", err)
+	}
+	dailyScheduler.Stop()
+	return nil
+}
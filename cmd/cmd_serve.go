@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fetch-sketchfab-data/internal/config"
+	"fetch-sketchfab-data/internal/graphql"
+	"fetch-sketchfab-data/internal/metrics"
+	"fetch-sketchfab-data/internal/server"
+)
+
+// runServeCommand 執行模型資料庫 API 模式
+func runServeCommand(args []string) error {
+	fs := newFlagSet("serve", "[-addr=:8080] [-config=config.yaml]",
+		"提供唯讀的模型資料庫 REST API（/models）、GraphQL（/graphql）、Atom/RSS 訂閱源（/feeds）、指標與健康檢查。")
+	flags := addCommonFlags(fs)
+	addr := fs.String("addr", "", "HTTP 伺服器位址，覆寫設定檔的 server.addr")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	a, err := newApp(flags, func(c *config.Config) {
+		if *addr != "" {
+			c.Server.Addr = *addr
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	logService, err := a.Logger()
+	if err != nil {
+		return err
+	}
+	if err := a.StartTracing(); err != nil {
+		return err
+	}
+	mongoClient, err := a.Mongo()
+	if err != nil {
+		return err
+	}
+	modelsService, err := a.Models()
+	if err != nil {
+		return err
+	}
+	runsService, err := a.Runs()
+	if err != nil {
+		return err
+	}
+
+	serverConfig := a.cfg.Server
+	logService.Info("📚 啟動模型資料庫 API", "addr", serverConfig.Addr)
+
+	httpServer := server.NewServer(serverConfig.Addr, logService)
+	server.NewCatalogueHandler(modelsService).Register(httpServer)
+	server.NewFeedHandler(modelsService).Register(httpServer)
+	httpServer.Handle("GET /metrics", metrics.Handler())
+	server.NewHealthHandler(
+		server.MongoHealthCheck(mongoClient),
+		server.LogstashHealthCheck(logService),
+		server.SyncFreshnessHealthCheck(runsService, serverConfig.MaxSyncAge),
+	).Register(httpServer)
+
+	graphqlHandler, err := graphql.NewHandler(modelsService)
+	if err != nil {
+		return fmt.Errorf("建立 GraphQL 端點失敗: %v", err)
+	}
+	httpServer.Handle("/graphql", graphqlHandler)
+
+	if err := httpServer.Start(); err != nil {
+		return err
+	}
+
+	// 設定檔重新載入（API 服務模式只會套用日誌等級）
+	if err := waitForShutdown(context.Background(), logService, a.Reloader(nil), nil); err != nil {
+		return err
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("關閉 HTTP 伺服器失敗: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// runStatsCommand 顯示模型資料庫統計
+func runStatsCommand(args []string) error {
+	fs := newFlagSet("stats", "[-json] [-config=config.yaml]",
+		"顯示模型總數、可下載數、瀏覽與喜歡總數，以及授權、標籤、分類與作者的排行。")
+	flags := addCommonFlags(fs)
+	asJSON := fs.Bool("json", false, "以 JSON 輸出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	a, err := newApp(flags)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	modelsService, err := a.Models()
+	if err != nil {
+		return err
+	}
+	stats, err := modelsService.GetStats()
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "模型總數\t%d\n", stats.TotalModels)
+	fmt.Fprintf(w, "可下載\t%d\n", stats.DownloadableModels)
+	fmt.Fprintf(w, "瀏覽總數\t%d\n", stats.TotalViews)
+	fmt.Fprintf(w, "喜歡總數\t%d\n", stats.TotalLikes)
+	if stats.LastFetchedAt != nil && !stats.LastFetchedAt.IsZero() {
+		fmt.Fprintf(w, "最近同步\t%s\n", stats.LastFetchedAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	printBuckets(w, "授權", stats.Licenses, 10)
+	printBuckets(w, "熱門標籤", stats.TopTags, 10)
+	printBuckets(w, "熱門分類", stats.TopCategories, 10)
+	printBuckets(w, "模型最多的作者", stats.TopUsers, 10)
+	return w.Flush()
+}
+
+// printBuckets 輸出分組統計的前 limit 筆
+func printBuckets(w *tabwriter.Writer, title string, buckets []service.CountBucket, limit int) {
+	if len(buckets) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s\t\n", title)
+	for i, bucket := range buckets {
+		if i >= limit {
+			break
+		}
+		key := bucket.Key
+		if key == "" {
+			key = "(未指定)"
+		}
+		fmt.Fprintf(w, "  %s\t%d\n", key, bucket.Count)
+	}
+}
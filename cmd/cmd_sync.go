@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"fetch-sketchfab-data/internal/config"
+	"fetch-sketchfab-data/internal/service"
+)
+
+// runSyncCommand 立即依序執行設定中的任務（或 -job 指定的任務）一次
+func runSyncCommand(args []string) error {
+	fs := newFlagSet("sync", "[-job=名稱,...] [-max-pages=N] [-config=config.yaml]",
+		"立即依序執行設定中的所有任務（或 -job 指定的任務）一次，任一任務失敗時以代碼 1 結束。")
+	flags := addCommonFlags(fs)
+	jobNames := fs.String("job", "", "只執行指定的任務，多個以逗號分隔")
+	maxPages := fs.Int("max-pages", 0, "覆寫每個任務的最大頁數（-1 表示取到最後一頁）")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	overridePages := false
+	fs.Visit(func(f *flag.Flag) { overridePages = overridePages || f.Name == "max-pages" })
+
+	a, err := newApp(flags, func(c *config.Config) {
+		if overridePages {
+			for i := range c.Jobs {
+				c.Jobs[i].MaxPages = *maxPages
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	// 先確認任務名稱，避免連線後才發現參數錯誤
+	selected := make([]string, 0, len(a.cfg.Jobs))
+	if *jobNames == "" {
+		for _, job := range a.cfg.Jobs {
+			selected = append(selected, job.Name)
+		}
+	} else {
+		known := map[string]bool{}
+		for _, job := range a.cfg.Jobs {
+			known[job.Name] = true
+		}
+		for _, name := range strings.Split(*jobNames, ",") {
+			name = strings.TrimSpace(name)
+			if !known[name] {
+				return usageErrorf("找不到任務: %s", name)
+			}
+			selected = append(selected, name)
+		}
+	}
+
+	logService, err := a.Logger()
+	if err != nil {
+		return err
+	}
+	if err := a.StartTracing(); err != nil {
+		return err
+	}
+	dailyScheduler, err := a.Scheduler()
+	if err != nil {
+		return err
+	}
+
+	// 中斷時停止同步，已儲存的分頁保留
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	logService.Info("🔧 執行單次同步...", "jobs", selected)
+	failed := 0
+	for _, name := range selected {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := dailyScheduler.RunJob(ctx, name); err != nil {
+			logService.Error("❌ 任務執行失敗", "job", name, "error", err)
+			failed++
+			continue
+		}
+		logService.Info("✅ 任務執行完成", "job", name)
+	}
+	dispatchEvents(a, logService)
+	if failed > 0 {
+		return fmt.Errorf("%d 個任務執行失敗（共 %d 個）", failed, len(selected))
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("同步已中斷")
+	}
+	logService.Info("✅ 單次執行完成!")
+	return nil
+}
+
+// dispatchEvents 結束前送出同步產生的模型事件與訂閱投遞，未送出的部分由排程模式稍後重試
+func dispatchEvents(a *app, logService *service.LogService) {
+	dispatcher, err := a.Dispatcher()
+	if err != nil || dispatcher == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	delivered, err := dispatcher.DispatchPending(ctx)
+	if err != nil {
+		logService.Warn("⚠️ 送出模型事件失敗，將由排程模式重試", "delivered", delivered, "error", err)
+		return
+	}
+	if delivered > 0 {
+		logService.Info("📤 已送出模型事件", "delivered", delivered)
+	}
+
+	worker, err := a.DeliveryWorker()
+	if err != nil || worker == nil {
+		return
+	}
+	delivered, err = worker.DeliverPending(ctx)
+	if err != nil {
+		logService.Warn("⚠️ 送出 webhook 訂閱投遞失敗，將由排程模式重試", "delivered", delivered, "error", err)
+		return
+	}
+	if delivered > 0 {
+		logService.Info("📤 已送出 webhook 訂閱投遞", "delivered", delivered)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 結束代碼，讓外部排程或監控可以區分失敗原因
+const (
+	exitOK          = 0 // 成功
+	exitFailure     = 1 // 執行失敗
+	exitUsage       = 2 // 子命令或參數錯誤
+	exitConfig      = 3 // 設定錯誤
+	exitUnavailable = 4 // 相依服務（MongoDB 等）無法連線
+	exitUnhealthy   = 5 // doctor 檢查未通過
+)
+
+// exitError 帶有結束代碼的錯誤
+type exitError struct {
+	code     int
+	err      error
+	reported bool // 錯誤訊息已輸出（例如 flag 套件已印出參數錯誤），不再重複輸出
+}
+
+// Error 實作 error
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap 取得原始錯誤
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode 為錯誤指定結束代碼
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// usageErrorf 建立參數錯誤
+func usageErrorf(format string, args ...any) error {
+	return &exitError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+// exitCodeOf 取得錯誤對應的結束代碼，未指定時為 exitFailure
+func exitCodeOf(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
+}
+
+// isReported 判斷錯誤訊息是否已輸出
+func isReported(err error) bool {
+	var exitErr *exitError
+	return errors.As(err, &exitErr) && exitErr.reported
+}
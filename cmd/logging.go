@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"fetch-sketchfab-data/internal/config"
+	"fetch-sketchfab-data/internal/service"
+)
+
+// newLogService 依設定建立日誌服務與所選的 sink
+func newLogService(cfg config.LogstashConfig, serviceName string) (*service.LogService, error) {
+	sinks, err := newLogSinks(cfg, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	logService := service.NewLogServiceWithSinks(serviceName, service.ShipperOptions{
+		QueueSize:     cfg.QueueSize,
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: cfg.FlushInterval,
+		DropPolicy:    cfg.DropPolicy,
+	}, sinks...)
+	if level, err := service.ParseLevel(cfg.Level); err != nil {
+		logService.Warn("日誌等級設定錯誤，使用 INFO", "error", err)
+	} else {
+		logService.SetLevel(level)
+	}
+	return logService, nil
+}
+
+// newLogSinks 依 LOG_SINKS 建立 sink，可同時啟用多個
+func newLogSinks(cfg config.LogstashConfig, serviceName string) ([]service.LogSink, error) {
+	if len(cfg.Sinks) == 0 {
+		return nil, fmt.Errorf("未設定任何日誌 sink")
+	}
+
+	seen := map[string]bool{}
+	var sinks []service.LogSink
+	for _, name := range cfg.Sinks {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		switch name {
+		case "console":
+			sinks = append(sinks, service.NewConsoleSink(os.Stdout))
+		case "json":
+			sinks = append(sinks, service.NewJSONSink(os.Stdout))
+		case "file":
+			sink, err := service.NewFileSink(cfg.FilePath, int64(cfg.FileMaxMB)<<20, cfg.FileMaxBackups)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		case "tcp":
+			opts := service.DefaultTCPSinkOptions()
+			opts.ReconnectMax = cfg.ReconnectMax
+			if cfg.SpoolMaxMB > 0 {
+				opts.SpoolPath = cfg.SpoolPath
+				opts.SpoolMaxBytes = int64(cfg.SpoolMaxMB) << 20
+			}
+			sink, err := service.NewTCPSink(cfg.Host, cfg.Port, opts)
+			if err != nil {
+				// 暫存檔無法使用時仍發送到 Logstash，只是不暫存
+				fmt.Printf("[WARN] %v，無法送達的日誌將不會暫存\n", err)
+				opts.SpoolPath = ""
+				sink, _ = service.NewTCPSink(cfg.Host, cfg.Port, opts)
+			}
+			sinks = append(sinks, sink)
+		case "udp":
+			sinks = append(sinks, service.NewUDPSink(cfg.Host, cfg.Port))
+		case "gelf":
+			if _, _, err := net.SplitHostPort(cfg.GELFAddr); err != nil {
+				return nil, fmt.Errorf("LOG_GELF_ADDR 格式錯誤: %v", err)
+			}
+			sinks = append(sinks, service.NewGELFSink(cfg.GELFAddr))
+		case "syslog":
+			sink, err := service.NewSyslogSink(cfg.SyslogNetwork, cfg.SyslogAddr, serviceName)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		default:
+			return nil, fmt.Errorf("未知的日誌 sink: %s（可用: console、json、file、tcp、udp、gelf、syslog）", name)
+		}
+	}
+	return sinks, nil
+}
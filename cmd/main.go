@@ -1,152 +1,160 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
-	"os/signal"
-	"syscall"
-
-	"fetch-sketchfab-data/internal/api"
-	"fetch-sketchfab-data/internal/config"
-	"fetch-sketchfab-data/internal/database"
-	"fetch-sketchfab-data/internal/scheduler"
-	"fetch-sketchfab-data/internal/service"
+	"strings"
 )
 
-func main() {
-	// 命令列參數
-	var (
-		mode         = flag.String("mode", "once", "執行模式: once(單次執行) 或 schedule(排程執行)")
-		scheduleTime = flag.String("time", "09:00", "排程執行時間 (格式: HH:MM, 24小時制)")
-	)
-	flag.Parse()
-
-	// 顯示使用說明
-	if *mode != "once" && *mode != "schedule" {
-		fmt.Println("使用方式:")
-		fmt.Println("  單次執行: go run cmd/main.go -mode=once")
-		fmt.Println("  排程執行: go run cmd/main.go -mode=schedule -time=09:00")
-		os.Exit(1)
-	}
-	// 載入設定
-	cfg := config.LoadConfig()
-
-	// 建立MongoDB連線
-	mongoConfig := &database.MongoDBConfig{
-		URI:      cfg.MongoDB.URI,
-		Database: cfg.MongoDB.Database,
-		Timeout:  cfg.MongoDB.Timeout,
-	}
+// programName 使用說明中顯示的程式名稱
+const programName = "fetch-sketchfab"
 
-	// 建立 MongoDB Client端
-	mongoClient, err := database.NewMongoDBClient(mongoConfig)
-	if err != nil {
-		log.Fatalf("MongoDB連線失敗: %v", err)
+// command 子命令定義
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands 所有子命令，依使用說明中的順序排列
+func commands() []*command {
+	return []*command{
+		{name: "sync", summary: "立即執行一次同步（全部或指定的任務）", run: runSyncCommand},
+		{name: "schedule", summary: "依排程每日同步，並提供指標、健康檢查與控制 API", run: runScheduleCommand},
+		{name: "serve", summary: "提供模型資料庫 REST 與 GraphQL API", run: runServeCommand},
+		{name: "export", summary: "匯出模型資料", run: runExportCommand},
+		{name: "download", summary: "下載模型的 GLB、glTF、USDZ 或原始檔案封存", run: runDownloadCommand},
+		{name: "stats", summary: "顯示模型資料庫統計", run: runStatsCommand},
+		{name: "runs", summary: "列出同步任務的執行紀錄", run: runRunsCommand},
+		{name: "migrate", summary: "建立資料庫索引", run: runMigrateCommand},
+		{name: "reindex", summary: "從 MongoDB 重建 Elasticsearch 模型搜尋索引", run: runReindexCommand},
+		{name: "doctor", summary: "檢查設定與相依服務是否可用", run: runDoctorCommand},
+		{name: "config", summary: "輸出或驗證實際生效的設定", run: runConfigCommand},
 	}
-	defer func() {
-		if err := mongoClient.Close(); err != nil {
-			log.Printf("關閉MongoDB連線時發生錯誤: %v", err)
-		}
-	}()
+}
 
-	// 建立日誌服務
-	logService := service.NewLogService(cfg.Logstash.Host, cfg.Logstash.Port, "sketchfab-fetcher")
-	defer logService.Close()
+func main() {
+	os.Exit(run(legacyArgs(os.Args[1:])))
+}
 
-	// 輸出啟動訊息到標準輸出
-	fmt.Printf("⏰ 啟動每日排程模式，執行時間: %s\n", *scheduleTime)
+// run 執行子命令並回傳結束代碼
+func run(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return exitUsage
+	}
 
-	// 建立模型服務
-	modelsService := service.NewModelsService(mongoClient)
+	name := args[0]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			// help <子命令> 等同 <子命令> -h
+			return run([]string{args[1], "-h"})
+		}
+		printUsage(os.Stdout)
+		return exitOK
+	}
 
-	// 建立 Sketchfab API 客戶端
-	client := api.NewSketchfabClient()
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "未知的子命令: %s\n\n", name)
+		printUsage(os.Stderr)
+		return exitUsage
+	}
 
-	// 根據模式執行
-	switch *mode {
-	case "once":
-		logService.Info("🔧 執行單次同步...")
-		err = runOnce(client, modelsService, logService)
-		if err != nil {
-			logService.Error(fmt.Sprintf("單次執行失敗: %v", err))
-			log.Fatalf("單次執行失敗: %v", err)
-		}
-		logService.Info("✅ 單次執行完成!")
-
-	case "schedule":
-		logService.Info(fmt.Sprintf("⏰ 啟動每日排程模式，執行時間: %s", *scheduleTime))
-		err = runScheduler(client, modelsService, logService, *scheduleTime)
-		if err != nil {
-			logService.Error(fmt.Sprintf("排程器執行失敗: %v", err))
-			log.Fatalf("排程器執行失敗: %v", err)
-		}
+	err := cmd.run(args[1:])
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case !isReported(err):
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
 	}
+	return exitCodeOf(err)
 }
 
-// runOnce 執行單次同步
-func runOnce(client *api.SketchfabClient, modelsService *service.ModelsService, logService *service.LogService) error {
-	response, err := client.GetDownloadableModels()
-	if err != nil {
-		logService.Error(fmt.Sprintf("API呼叫失敗: %v", err))
-		return fmt.Errorf("API呼叫失敗: %v", err)
+// findCommand 依名稱尋找子命令
+func findCommand(name string) *command {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd
+		}
 	}
+	return nil
+}
 
-	logService.Info(fmt.Sprintf("📥 成功取得 %d 個模型資料", len(response.Results)))
+// printUsage 輸出子命令清單與結束代碼
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "使用方式: %s <子命令> [參數]\n\n子命令:\n", programName)
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\n執行 %s <子命令> -h 查看各子命令的參數。\n\n", programName)
+	fmt.Fprintln(w, "結束代碼:")
+	fmt.Fprintln(w, "  0  成功")
+	fmt.Fprintln(w, "  1  執行失敗")
+	fmt.Fprintln(w, "  2  子命令或參數錯誤")
+	fmt.Fprintln(w, "  3  設定錯誤")
+	fmt.Fprintln(w, "  4  相依服務（MongoDB 等）無法連線")
+	fmt.Fprintln(w, "  5  doctor 檢查未通過")
+}
 
-	// 將API回應儲存到資料庫
-	logService.Info("正在將模型資料儲存到資料庫...")
-	upsertResult, err := modelsService.ConvertAndSaveModelsResponse(response)
-	if err != nil {
-		logService.Error(fmt.Sprintf("儲存模型資料失敗: %v", err))
-		return fmt.Errorf("儲存模型資料失敗: %v", err)
+// legacyArgs 將舊版的 -mode 參數轉為子命令，例如 -mode=schedule -time=09:00 → schedule -time=09:00
+//
+// 舊版未指定 -mode 時為單次執行，因此只有參數、沒有子命令時視為 sync。
+func legacyArgs(args []string) []string {
+	if len(args) == 0 || !strings.HasPrefix(args[0], "-") || isHelpFlag(args[0]) {
+		return args
 	}
 
-	// 顯示 upsert 統計結果
-	logService.Info(fmt.Sprintf("📊 處理統計: 新增=%d, 更新=%d, 無變化=%d",
-		upsertResult.InsertedCount, upsertResult.UpdatedCount, upsertResult.UnchangedCount))
+	mode := "once"
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name != "mode" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		mode = value
+	}
 
-	// 顯示資料庫統計
-	totalCount, err := modelsService.GetModelsCount()
-	if err != nil {
-		logService.Error(fmt.Sprintf("取得模型總數失敗: %v", err))
-	} else {
-		logService.Info(fmt.Sprintf("💾 資料庫中的模型總數: %d", totalCount))
+	subcommand := map[string]string{"once": "sync", "schedule": "schedule", "serve": "serve"}[mode]
+	if subcommand == "" {
+		// 無法對應時交由 run 回報未知的子命令
+		subcommand = mode
 	}
+	if subcommand != "schedule" {
+		// -time 只有排程模式使用
+		rest = dropFlag(rest, "time")
+	}
+	fmt.Fprintf(os.Stderr, "[WARN] -mode 參數已停用，請改用子命令: %s %s\n", programName, strings.Join(append([]string{subcommand}, rest...), " "))
+	return append([]string{subcommand}, rest...)
+}
 
-	return nil
+// isHelpFlag 判斷是否為說明參數
+func isHelpFlag(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
 }
 
-// runScheduler 執行排程器模式
-func runScheduler(client *api.SketchfabClient, modelsService *service.ModelsService, logService *service.LogService, scheduleTime string) error {
-	// 建立每日排程器
-	dailyScheduler := scheduler.NewDailyScheduler(client, modelsService, logService, scheduleTime)
-
-	// 建立 context 和信號處理
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// 設定信號處理
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	// 在 goroutine 中啟動排程器
-	errChan := make(chan error, 1)
-	go func() {
-		errChan <- dailyScheduler.Start(ctx)
-	}()
-
-	// 等待信號或錯誤
-	select {
-	case <-sigChan:
-		logService.Info("收到停止信號，正在關閉...")
-		dailyScheduler.Stop()
-		cancel()
-		return nil
-	case err := <-errChan:
-		logService.Error(fmt.Sprintf("排程器錯誤: %v", err))
-		return err
+// dropFlag 移除指定名稱的參數（含 -name value 形式的值）
+func dropFlag(args []string, name string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		flagName, _, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if flagName != name {
+			out = append(out, args[i])
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+		}
 	}
+	return out
 }
@@ -0,0 +1,133 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"fetch-sketchfab-data/internal/notify"
+	"fetch-sketchfab-data/internal/report"
+	"fetch-sketchfab-data/internal/scheduler"
+	"fetch-sketchfab-data/internal/service"
+)
+
+// maxDigestModels 每則摘要列出的模型上限
+const maxDigestModels = 50
+
+//go:embed saved_search.txt.tmpl
+var textTemplateSource string
+
+//go:embed saved_search.html.tmpl
+var htmlTemplateSource string
+
+// templateFuncs 摘要範本使用的函式
+var templateFuncs = map[string]interface{}{
+	"sub":  func(a, b int) int { return a - b },
+	"join": strings.Join,
+}
+
+var (
+	textTemplate = texttemplate.Must(texttemplate.New("saved_search").Funcs(templateFuncs).Parse(textTemplateSource))
+	htmlTemplate = htmltemplate.Must(htmltemplate.New("saved_search").Funcs(templateFuncs).Parse(htmlTemplateSource))
+)
+
+// SearchDigest 一個儲存搜尋在一次同步中的摘要，也是 webhook 通知的 data 內容
+type SearchDigest struct {
+	SearchID   string                 `json:"search_id"`
+	SearchName string                 `json:"search_name"`
+	Criteria   service.SearchCriteria `json:"criteria"`
+	RunID      string                 `json:"run_id"`
+	JobName    string                 `json:"job_name"`
+	Total      int                    `json:"total"`
+	Models     []report.ModelSummary  `json:"models"` // 依喜歡數排序，最多 maxDigestModels 筆
+}
+
+// SavedSearchAlerts 每次同步後以新增的模型比對儲存搜尋，並透過 notifier 送出摘要
+type SavedSearchAlerts struct {
+	searches      *service.SavedSearchesService
+	modelsService *service.ModelsService
+	notifier      notify.Notifier
+	logService    *service.LogService
+}
+
+// NewSavedSearchAlerts 建立新的儲存搜尋通知
+func NewSavedSearchAlerts(searches *service.SavedSearchesService, modelsService *service.ModelsService, notifier notify.Notifier, logService *service.LogService) *SavedSearchAlerts {
+	return &SavedSearchAlerts{
+		searches:      searches,
+		modelsService: modelsService,
+		notifier:      notifier,
+		logService:    logService,
+	}
+}
+
+// Name 實作 scheduler.RunHook
+func (a *SavedSearchAlerts) Name() string {
+	return "saved_searches"
+}
+
+// AfterRun 實作 scheduler.RunHook，每個有符合的儲存搜尋送出一則摘要
+func (a *SavedSearchAlerts) AfterRun(ctx context.Context, run *scheduler.RunResult, upsert *service.UpsertResult) error {
+	if upsert == nil || len(upsert.InsertedIDs) == 0 {
+		return nil
+	}
+	matches, err := a.searches.Evaluate(ctx, a.modelsService, upsert.InsertedIDs)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, match := range matches {
+		message, err := NewSearchDigestMessage(run, match)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", match.Search.Name, err))
+			continue
+		}
+		if err := a.notifier.Send(ctx, message); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", match.Search.Name, err))
+			continue
+		}
+		a.logService.Info("🔔 已送出儲存搜尋摘要", "search", match.Search.Name, "models", len(match.Models), "notifier", a.notifier.Name())
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("送出儲存搜尋摘要失敗: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// NewSearchDigestMessage 建立儲存搜尋摘要通知，包含純文字與 HTML 內容
+func NewSearchDigestMessage(run *scheduler.RunResult, match *service.SearchMatch) (*notify.Message, error) {
+	digest := &SearchDigest{
+		SearchID:   match.Search.ID,
+		SearchName: match.Search.Name,
+		Criteria:   match.Search.Criteria,
+		RunID:      run.RunID,
+		JobName:    run.JobName,
+		Total:      len(match.Models),
+	}
+	for i, model := range match.Models {
+		if i == maxDigestModels {
+			break
+		}
+		digest.Models = append(digest.Models, report.NewModelSummary(model))
+	}
+
+	var text, html bytes.Buffer
+	if err := textTemplate.Execute(&text, digest); err != nil {
+		return nil, fmt.Errorf("產生摘要失敗: %v", err)
+	}
+	if err := htmlTemplate.Execute(&html, digest); err != nil {
+		return nil, fmt.Errorf("產生摘要失敗: %v", err)
+	}
+	return &notify.Message{
+		Kind:       "saved_search",
+		Subject:    fmt.Sprintf("[Sketchfab] %s：%d 個新模型", digest.SearchName, digest.Total),
+		Text:       text.String(),
+		HTML:       html.String(),
+		Recipients: match.Search.Recipients,
+		Data:       digest,
+	}, nil
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"fetch-sketchfab-data/internal/models"
+	"fetch-sketchfab-data/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrNoAPIKey 表示呼叫需要認證的 API 但未設定 API token
+var ErrNoAPIKey = errors.New("未設定 Sketchfab API token（api.sketchfab_api_key 或 SKETCHFAB_API_KEY）")
+
+// GetDownloadLinks 取得模型各格式的下載連結（需要 API token，且模型必須可下載）
+//
+// 回傳的網址為預先簽署的網址，約數分鐘後失效，每次下載前都應重新取得。
+func (c *SketchfabClient) GetDownloadLinks(ctx context.Context, uid string) (*models.DownloadLinks, error) {
+	if c.APIKey == "" {
+		return nil, ErrNoAPIKey
+	}
+	ctx, span := tracing.Start(ctx, "sketchfab.get_download_links", attribute.String("sketchfab.uid", uid))
+	links, err := c.getDownloadLinks(ctx, uid)
+	tracing.End(span, err)
+	return links, err
+}
+
+// getDownloadLinks 呼叫下載 API
+func (c *SketchfabClient) getDownloadLinks(ctx context.Context, uid string) (*models.DownloadLinks, error) {
+	body, err := c.doWithRetry(ctx, fmt.Sprintf("%s/models/%s/download", c.BaseURL, url.PathEscape(uid)), true)
+	if err != nil {
+		return nil, err
+	}
+	var links models.DownloadLinks
+	if err := json.Unmarshal(body, &links); err != nil {
+		return nil, fmt.Errorf("無法解析 JSON 回應: %w", err)
+	}
+	return &links, nil
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter 限制兩次請求之間的最小間隔
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewRateLimiter 建立新的速率限制器，interval 為 0 表示不限制
+func NewRateLimiter(interval time.Duration) *RateLimiter {
+	return &RateLimiter{interval: interval}
+}
+
+// Wait 等待直到可以發送下一個請求，回傳實際等待的時間；ctx 取消時立即回傳 ctx 的錯誤
+func (l *RateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	l.mu.Lock()
+	now := time.Now()
+	wait := time.Duration(0)
+	if l.next.After(now) {
+		wait = l.next.Sub(now)
+	}
+	l.next = now.Add(wait + l.interval)
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return wait, ctx.Err()
+	case <-timer.C:
+		return wait, nil
+	}
+}
+
+// SetInterval 更新請求間隔
+func (l *RateLimiter) SetInterval(interval time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.interval = interval
+}
+
+// Interval 取得目前的請求間隔
+func (l *RateLimiter) Interval() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.interval
+}
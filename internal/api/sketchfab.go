@@ -1,19 +1,33 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
+	"fetch-sketchfab-data/internal/metrics"
 	"fetch-sketchfab-data/internal/models"
+	"fetch-sketchfab-data/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// maxRetryDelay 單次重試等待時間的上限，Retry-After 超過此值時改用指數退避
+const maxRetryDelay = 2 * time.Minute
+
 type SketchfabClient struct {
-	BaseURL    string
-	HTTPClient *http.Client
+	BaseURL        string
+	APIKey         string // 下載等需要認證的 API 使用的 API token
+	HTTPClient     *http.Client
+	MaxRetries     int           // 連線錯誤、429 與 5xx 時的最大重試次數，0 表示不重試
+	RetryBaseDelay time.Duration // 重試的基礎等待時間，每次重試加倍
+	RateLimiter    *RateLimiter  // 間隔為 0 時不限制
 }
 
 func NewSketchfabClient() *SketchfabClient {
@@ -22,11 +36,24 @@ func NewSketchfabClient() *SketchfabClient {
 		HTTPClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		RetryBaseDelay: 2 * time.Second,
+		RateLimiter:    NewRateLimiter(0),
 	}
 }
 
 // GetModels API
-func (c *SketchfabClient) GetModels(params *models.GetModelsParams) (*models.ModelsResponse, error) {
+func (c *SketchfabClient) GetModels(ctx context.Context, params *models.GetModelsParams) (*models.ModelsResponse, error) {
+	ctx, span := tracing.Start(ctx, "sketchfab.get_models")
+	response, err := c.getModels(ctx, params)
+	if err == nil {
+		span.SetAttributes(attribute.Int("sketchfab.results", len(response.Results)))
+	}
+	tracing.End(span, err)
+	return response, err
+}
+
+// getModels 取得一頁模型列表
+func (c *SketchfabClient) getModels(ctx context.Context, params *models.GetModelsParams) (*models.ModelsResponse, error) {
 	// 建立 URL
 	apiURL, err := url.Parse(fmt.Sprintf("%s/models", c.BaseURL))
 	if err != nil {
@@ -72,50 +99,187 @@ func (c *SketchfabClient) GetModels(params *models.GetModelsParams) (*models.Mod
 
 	apiURL.RawQuery = query.Encode()
 
+	body, err := c.doWithRetry(ctx, apiURL.String(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析 JSON 回應
+	var modelsResponse models.ModelsResponse
+	if err := json.Unmarshal(body, &modelsResponse); err != nil {
+		return nil, fmt.Errorf("無法解析 JSON 回應: %w", err)
+	}
+
+	metrics.PagesFetched.Inc()
+	return &modelsResponse, nil
+}
+
+// doWithRetry 發送 GET 請求，遇到連線錯誤、429 或 5xx 時以指數退避重試；authorized 為 true 時附上 API token
+func (c *SketchfabClient) doWithRetry(ctx context.Context, apiURL string, authorized bool) ([]byte, error) {
+	var lastErr error
+	span := trace.SpanFromContext(ctx)
+
+	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
+		if attempt > 0 {
+			metrics.APIRetries.Inc()
+		}
+
+		body, retryAfter, err := c.do(ctx, apiURL, authorized)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+		if retryAfter < 0 || attempt == c.MaxRetries {
+			break
+		}
+
+		// 未指定 Retry-After 或超過上限時使用指數退避
+		if retryAfter == 0 || retryAfter > maxRetryDelay {
+			retryAfter = min(c.RetryBaseDelay<<attempt, maxRetryDelay)
+		}
+		span.AddEvent("retry", trace.WithAttributes(
+			attribute.Int("attempt", attempt+1),
+			attribute.String("error", err.Error()),
+			attribute.Float64("wait_seconds", retryAfter.Seconds()),
+		))
+
+		timer := time.NewTimer(retryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return nil, lastErr
+}
+
+// do 發送一次 GET 請求，回傳內容、建議的重試等待時間（-1 表示不應重試）與錯誤
+func (c *SketchfabClient) do(ctx context.Context, apiURL string, authorized bool) ([]byte, time.Duration, error) {
+	if c.RateLimiter != nil {
+		wait, err := c.RateLimiter.Wait(ctx)
+		metrics.RateLimiterWait.Observe(wait.Seconds())
+		if err != nil {
+			return nil, -1, err
+		}
+		if wait > 0 {
+			trace.SpanFromContext(ctx).AddEvent("rate_limited", trace.WithAttributes(attribute.Float64("wait_seconds", wait.Seconds())))
+		}
+	}
+
 	// 建立 HTTP 請求
-	req, err := http.NewRequest("GET", apiURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("無法建立 HTTP 請求: %w", err)
+		return nil, -1, fmt.Errorf("無法建立 HTTP 請求: %w", err)
 	}
 
 	// 設定請求標頭
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("User-Agent", "fetch-sketchfab-data/1.0")
+	if authorized {
+		req.Header.Set("Authorization", "Token "+c.APIKey)
+	}
 
 	// 發送請求
+	start := time.Now()
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP 請求失敗: %w", err)
+		metrics.ObserveAPIRequest(0, time.Since(start))
+		return nil, 0, fmt.Errorf("HTTP 請求失敗: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// 讀取回應內容
+	body, err := io.ReadAll(resp.Body)
+	metrics.ObserveAPIRequest(resp.StatusCode, time.Since(start))
+	if err != nil {
+		return nil, 0, fmt.Errorf("無法讀取回應內容: %w", err)
+	}
+
 	// 檢查回應狀態碼
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API 請求失敗，狀態碼: %d, 回應: %s", resp.StatusCode, string(body))
+		err := fmt.Errorf("API 請求失敗，狀態碼: %d, 回應: %s", resp.StatusCode, string(body))
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return nil, retryAfter(resp), err
+		}
+		return nil, -1, err
 	}
 
-	// 讀取回應內容
-	body, err := io.ReadAll(resp.Body)
+	return body, 0, nil
+}
+
+// retryAfter 解析 Retry-After 標頭（秒數）
+func retryAfter(resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
+
+// Ping 檢查 Sketchfab API 是否可連線（不經過速率限制與重試）
+func (c *SketchfabClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/categories", c.BaseURL), nil)
 	if err != nil {
-		return nil, fmt.Errorf("無法讀取回應內容: %w", err)
+		return fmt.Errorf("無法建立 HTTP 請求: %w", err)
 	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "fetch-sketchfab-data/1.0")
 
-	// 解析 JSON 回應
-	var modelsResponse models.ModelsResponse
-	if err := json.Unmarshal(body, &modelsResponse); err != nil {
-		return nil, fmt.Errorf("無法解析 JSON 回應: %w", err)
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP 請求失敗: %w", err)
 	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
 
-	return &modelsResponse, nil
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("API 回應異常，狀態碼: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// GetModelsPages 依游標逐頁取得模型，最多 maxPages 頁
+//
+// maxPages 為 0 時只取第一頁（與 GetModels 相同），負數表示取到最後一頁。
+func (c *SketchfabClient) GetModelsPages(ctx context.Context, params *models.GetModelsParams, maxPages int, handle func(page int, response *models.ModelsResponse) error) error {
+	pageParams := models.GetModelsParams{}
+	if params != nil {
+		pageParams = *params
+	}
+
+	if maxPages == 0 {
+		maxPages = 1
+	}
+	for page := 1; maxPages < 0 || page <= maxPages; page++ {
+		pageCtx, span := tracing.Start(ctx, "sketchfab.fetch_page", attribute.Int("sketchfab.page", page))
+		response, err := c.getModels(pageCtx, &pageParams)
+		if err == nil {
+			span.SetAttributes(attribute.Int("sketchfab.results", len(response.Results)))
+		}
+		tracing.End(span, err)
+		if err != nil {
+			return fmt.Errorf("取得第 %d 頁失敗: %w", page, err)
+		}
+		if err := handle(page, response); err != nil {
+			return err
+		}
+
+		if response.Cursors.Next == nil || *response.Cursors.Next == "" {
+			return nil
+		}
+		pageParams.Cursor = response.Cursors.Next
+	}
+
+	return nil
 }
 
 // GetDownloadableModels API
-func (c *SketchfabClient) GetDownloadableModels() (*models.ModelsResponse, error) {
+func (c *SketchfabClient) GetDownloadableModels(ctx context.Context) (*models.ModelsResponse, error) {
 	params := &models.GetModelsParams{
 		Downloadable:     true,
 		ArchivesFlavours: false,
 	}
 
-	return c.GetModels(params)
+	return c.GetModels(ctx, params)
 }
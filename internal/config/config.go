@@ -1,70 +1,500 @@
 package config
 
 import (
+	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"fetch-sketchfab-data/internal/secrets"
 )
 
 // Config 應用程式設定結構
+//
+// 設定依序由預設值、設定檔（YAML 或 TOML）、環境變數與命令列參數疊加而成，後者覆寫前者。
 type Config struct {
-	MongoDB  MongoDBConfig  `json:"mongodb"`
-	API      APIConfig      `json:"api"`
-	Logstash LogstashConfig `json:"logstash"`
+	API      APIConfig      `json:"api" yaml:"api" toml:"api"`
+	MongoDB  MongoDBConfig  `json:"mongodb" yaml:"mongodb" toml:"mongodb"`
+	Schedule ScheduleConfig `json:"schedule" yaml:"schedule" toml:"schedule"`
+	Jobs     []JobConfig    `json:"jobs" yaml:"jobs" toml:"jobs"`
+	Logstash LogstashConfig `json:"logging" yaml:"logging" toml:"logging"`
+	Server   ServerConfig   `json:"server" yaml:"server" toml:"server"`
+	Tracing  TracingConfig  `json:"tracing" yaml:"tracing" toml:"tracing"`
+	Reload   ReloadConfig   `json:"reload" yaml:"reload" toml:"reload"`
+
+	Elasticsearch ElasticsearchConfig `json:"elasticsearch" yaml:"elasticsearch" toml:"elasticsearch"`
+	Events        EventsConfig        `json:"events" yaml:"events" toml:"events"`
+	Notify        NotifyConfig        `json:"notify" yaml:"notify" toml:"notify"`
+	Report        ReportConfig        `json:"report" yaml:"report" toml:"report"`
+	Download      DownloadConfig      `json:"download" yaml:"download" toml:"download"`
 }
 
 // MongoDBConfig MongoDB設定
 type MongoDBConfig struct {
-	URI      string        `json:"uri"`
-	Database string        `json:"database"`
-	Timeout  time.Duration `json:"timeout"`
+	URI      string        `json:"uri" yaml:"uri" toml:"uri"`
+	Username string        `json:"username,omitempty" yaml:"username,omitempty" toml:"username,omitempty"` // 設定時覆寫 URI 中的帳號
+	Password string        `json:"password,omitempty" yaml:"password,omitempty" toml:"password,omitempty"` // 設定時覆寫 URI 中的密碼
+	Database string        `json:"database" yaml:"database" toml:"database"`
+	Timeout  time.Duration `json:"timeout" yaml:"timeout" toml:"timeout"`
+}
+
+// ConnectionURI 取得實際連線用的 URI，Username 或 Password 有設定時會放入 URI
+func (m MongoDBConfig) ConnectionURI() string {
+	if m.Username == "" && m.Password == "" {
+		return m.URI
+	}
+	u, err := url.Parse(m.URI)
+	if err != nil {
+		return m.URI
+	}
+	username := m.Username
+	if username == "" && u.User != nil {
+		username = u.User.Username()
+	}
+	password := m.Password
+	if password == "" && u.User != nil {
+		password, _ = u.User.Password()
+	}
+	u.User = url.UserPassword(username, password)
+	return u.String()
 }
 
 // APIConfig API設定
 type APIConfig struct {
-	SketchfabAPIKey string `json:"sketchfab_api_key"`
+	SketchfabAPIKey string        `json:"sketchfab_api_key" yaml:"sketchfab_api_key" toml:"sketchfab_api_key"`
+	BaseURL         string        `json:"base_url" yaml:"base_url" toml:"base_url"`
+	Timeout         time.Duration `json:"timeout" yaml:"timeout" toml:"timeout"`                            // 單次請求逾時
+	MaxRetries      int           `json:"max_retries" yaml:"max_retries" toml:"max_retries"`                // 連線錯誤、429 與 5xx 時的最大重試次數，0 表示不重試
+	RetryBaseDelay  time.Duration `json:"retry_base_delay" yaml:"retry_base_delay" toml:"retry_base_delay"` // 重試的基礎等待時間
+	RateLimit       time.Duration `json:"rate_limit" yaml:"rate_limit" toml:"rate_limit"`                   // 兩次請求之間的最小間隔，0 表示不限制
+}
+
+// ScheduleConfig 排程設定
+type ScheduleConfig struct {
+	Time string `json:"time" yaml:"time" toml:"time"` // 未指定時間的任務使用的預設執行時間（HH:MM）
+}
+
+// JobConfig 排程任務設定
+type JobConfig struct {
+	Name             string `json:"name" yaml:"name" toml:"name"`
+	Time             string `json:"time,omitempty" yaml:"time,omitempty" toml:"time,omitempty"` // 空值表示使用 schedule.time
+	MaxPages         int    `json:"max_pages" yaml:"max_pages" toml:"max_pages"`                // 0 表示只取第一頁，-1 表示取到最後一頁
+	Downloadable     bool   `json:"downloadable" yaml:"downloadable" toml:"downloadable"`
+	ArchivesFlavours bool   `json:"archives_flavours,omitempty" yaml:"archives_flavours,omitempty" toml:"archives_flavours,omitempty"`
+	Count            int    `json:"count,omitempty" yaml:"count,omitempty" toml:"count,omitzero"`
+	Sort             string `json:"sort,omitempty" yaml:"sort,omitempty" toml:"sort,omitempty"`
+	Categories       string `json:"categories,omitempty" yaml:"categories,omitempty" toml:"categories,omitempty"`
+	Tags             string `json:"tags,omitempty" yaml:"tags,omitempty" toml:"tags,omitempty"`
+	Search           string `json:"search,omitempty" yaml:"search,omitempty" toml:"search,omitempty"`
 }
 
 // LogstashConfig Logstash設定
 type LogstashConfig struct {
-	Host string `json:"host"`
-	Port string `json:"port"`
+	Host          string        `json:"host" yaml:"host" toml:"host"`
+	Port          string        `json:"port" yaml:"port" toml:"port"`
+	Level         string        `json:"level" yaml:"level" toml:"level"`                            // DEBUG、INFO、WARN、ERROR
+	QueueSize     int           `json:"queue_size" yaml:"queue_size" toml:"queue_size"`             // 背景佇列可容納的日誌筆數
+	BatchSize     int           `json:"batch_size" yaml:"batch_size" toml:"batch_size"`             // 單次寫入的最大筆數
+	FlushInterval time.Duration `json:"flush_interval" yaml:"flush_interval" toml:"flush_interval"` // 未滿一批時的最長等待時間
+	DropPolicy    string        `json:"drop_policy" yaml:"drop_policy" toml:"drop_policy"`          // drop_newest、drop_oldest 或 block
+	SpoolPath     string        `json:"spool_path" yaml:"spool_path" toml:"spool_path"`             // 無法送達時的本機暫存檔
+	SpoolMaxMB    int           `json:"spool_max_mb" yaml:"spool_max_mb" toml:"spool_max_mb"`       // 暫存檔容量上限（MB），0 表示不暫存
+	ReconnectMax  time.Duration `json:"reconnect_max" yaml:"reconnect_max" toml:"reconnect_max"`    // 重新連線的最長退避時間
+
+	Sinks          []string `json:"sinks" yaml:"sinks" toml:"sinks"`                                  // console、json、file、tcp、udp、gelf、syslog
+	FilePath       string   `json:"file_path" yaml:"file_path" toml:"file_path"`                      // file sink 的日誌檔路徑
+	FileMaxMB      int      `json:"file_max_mb" yaml:"file_max_mb" toml:"file_max_mb"`                // 日誌檔輪替大小（MB）
+	FileMaxBackups int      `json:"file_max_backups" yaml:"file_max_backups" toml:"file_max_backups"` // 保留的輪替檔數量
+	GELFAddr       string   `json:"gelf_addr" yaml:"gelf_addr" toml:"gelf_addr"`                      // GELF UDP 位址
+	SyslogAddr     string   `json:"syslog_addr" yaml:"syslog_addr" toml:"syslog_addr"`                // syslog 位址
+	SyslogNetwork  string   `json:"syslog_network" yaml:"syslog_network" toml:"syslog_network"`       // udp 或 tcp
+}
+
+// ServerConfig 內嵌 HTTP 伺服器設定
+type ServerConfig struct {
+	Addr         string        `json:"addr" yaml:"addr" toml:"addr"`
+	ControlToken string        `json:"control_token" yaml:"control_token" toml:"control_token"`
+	MaxSyncAge   time.Duration `json:"max_sync_age" yaml:"max_sync_age" toml:"max_sync_age"` // 就緒檢查允許的最久未同步時間
+}
+
+// TracingConfig OpenTelemetry 追蹤設定
+type TracingConfig struct {
+	Exporter    string  `json:"exporter" yaml:"exporter" toml:"exporter"`             // none、otlp 或 stdout
+	Endpoint    string  `json:"endpoint" yaml:"endpoint" toml:"endpoint"`             // OTLP/HTTP 端點
+	SampleRatio float64 `json:"sample_ratio" yaml:"sample_ratio" toml:"sample_ratio"` // 取樣比例，0 到 1
+}
+
+// ReloadConfig 設定檔重新載入設定
+type ReloadConfig struct {
+	WatchInterval time.Duration `json:"watch_interval" yaml:"watch_interval" toml:"watch_interval"` // 檢查設定檔變更的間隔，0 表示只在收到 SIGHUP 時重新載入
+}
+
+// ElasticsearchConfig 模型搜尋索引設定，URL 為空時不建立索引
+type ElasticsearchConfig struct {
+	URL      string        `json:"url" yaml:"url" toml:"url"`
+	Index    string        `json:"index" yaml:"index" toml:"index"` // 索引別名，重建索引時會指向新的實體索引
+	Username string        `json:"username,omitempty" yaml:"username,omitempty" toml:"username,omitempty"`
+	Password string        `json:"password,omitempty" yaml:"password,omitempty" toml:"password,omitempty"`
+	Timeout  time.Duration `json:"timeout" yaml:"timeout" toml:"timeout"`
+}
+
+// EventsConfig 模型變更事件設定，WebhookURL 為空且未啟用訂閱時不寫入事件
+type EventsConfig struct {
+	WebhookURL     string        `json:"webhook_url" yaml:"webhook_url" toml:"webhook_url"`
+	Subscriptions  bool          `json:"subscriptions" yaml:"subscriptions" toml:"subscriptions"`          // 啟用 webhook 訂閱（/subscriptions API 與簽章投遞）
+	Timeout        time.Duration `json:"timeout" yaml:"timeout" toml:"timeout"`                            // 單次送出逾時
+	MaxAttempts    int           `json:"max_attempts" yaml:"max_attempts" toml:"max_attempts"`             // 最大嘗試次數，超過後標記為 failed
+	RetryBaseDelay time.Duration `json:"retry_base_delay" yaml:"retry_base_delay" toml:"retry_base_delay"` // 第一次重試的等待時間，之後每次加倍
+	PollInterval   time.Duration `json:"poll_interval" yaml:"poll_interval" toml:"poll_interval"`          // 檢查 outbox 的間隔
+}
+
+// Enabled 是否寫入並送出模型事件
+func (e EventsConfig) Enabled() bool {
+	return e.WebhookURL != "" || e.Subscriptions
+}
+
+// NotifyConfig 通知設定（儲存搜尋摘要等），SMTP 與 webhook 可同時啟用
+type NotifyConfig struct {
+	SMTP       SMTPConfig    `json:"smtp" yaml:"smtp" toml:"smtp"`
+	WebhookURL string        `json:"webhook_url" yaml:"webhook_url" toml:"webhook_url"`
+	Timeout    time.Duration `json:"timeout" yaml:"timeout" toml:"timeout"`
 }
 
-// LoadConfig 載入設定
-func LoadConfig() *Config {
-	config := &Config{
+// SMTPConfig SMTP 寄件設定，Host 為空時不寄送 email
+type SMTPConfig struct {
+	Host     string   `json:"host" yaml:"host" toml:"host"`
+	Port     int      `json:"port" yaml:"port" toml:"port"` // 465 使用隱含 TLS，其他連接埠在伺服器支援時使用 STARTTLS
+	Username string   `json:"username,omitempty" yaml:"username,omitempty" toml:"username,omitempty"`
+	Password string   `json:"password,omitempty" yaml:"password,omitempty" toml:"password,omitempty"`
+	From     string   `json:"from" yaml:"from" toml:"from"`
+	To       []string `json:"to,omitempty" yaml:"to,omitempty" toml:"to,omitempty"` // 通知未指定收件人時的預設收件人
+}
+
+// Enabled 是否設定了任何通知方式
+func (n NotifyConfig) Enabled() bool {
+	return n.SMTP.Host != "" || n.WebhookURL != ""
+}
+
+// ReportConfig 同步報告設定，Dir 為空且 Notify 為 false 時不產生報告
+type ReportConfig struct {
+	Dir    string `json:"dir" yaml:"dir" toml:"dir"`          // 每次執行寫入 <開始時間>-<任務>.md 與 .html 的目錄
+	Notify bool   `json:"notify" yaml:"notify" toml:"notify"` // 透過 notify 設定的 notifier 送出報告
+}
+
+// Enabled 是否產生同步報告
+func (r ReportConfig) Enabled() bool {
+	return r.Dir != "" || r.Notify
+}
+
+// DownloadConfig 檔案封存下載設定（download 子命令）
+type DownloadConfig struct {
+	Dir         string        `json:"dir" yaml:"dir" toml:"dir"`                         // 下載目錄
+	Formats     []string      `json:"formats" yaml:"formats" toml:"formats"`             // glb、gltf、usdz、source
+	Concurrency int           `json:"concurrency" yaml:"concurrency" toml:"concurrency"` // 同時下載的模型數
+	Timeout     time.Duration `json:"timeout" yaml:"timeout" toml:"timeout"`             // 單一檔案的下載逾時
+	MaxSizeMB   int           `json:"max_size_mb" yaml:"max_size_mb" toml:"max_size_mb"` // 單一檔案的大小上限（MB），0 表示不限制
+}
+
+// Default 回傳預設設定
+func Default() *Config {
+	return &Config{
+		API: APIConfig{
+			BaseURL:        "https://api.sketchfab.com/v3",
+			Timeout:        30 * time.Second,
+			RetryBaseDelay: 2 * time.Second,
+		},
 		MongoDB: MongoDBConfig{
-			URI:      getEnvOrDefault("MONGODB_URI", "mongodb://localhost:27017"),
-			Database: getEnvOrDefault("MONGODB_DATABASE", "sketchfab_data"),
-			Timeout:  getDurationEnvOrDefault("MONGODB_TIMEOUT", 10*time.Second),
+			URI:      "mongodb://localhost:27017",
+			Database: "sketchfab_data",
+			Timeout:  10 * time.Second,
 		},
-		API: APIConfig{
-			SketchfabAPIKey: getEnvOrDefault("SKETCHFAB_API_KEY", ""),
+		Schedule: ScheduleConfig{
+			Time: "09:00",
+		},
+		Jobs: []JobConfig{
+			{Name: "downloadable", MaxPages: 1, Downloadable: true},
 		},
 		Logstash: LogstashConfig{
-			Host: getEnvOrDefault("LOGSTASH_HOST", "localhost"),
-			Port: getEnvOrDefault("LOGSTASH_PORT", "5000"),
+			Host:          "localhost",
+			Port:          "5000",
+			Level:         "INFO",
+			QueueSize:     1024,
+			BatchSize:     100,
+			FlushInterval: time.Second,
+			DropPolicy:    "drop_newest",
+			SpoolPath:     "logs/spool/sketchfab-logs.ndjson",
+			SpoolMaxMB:    50,
+			ReconnectMax:  time.Minute,
+
+			Sinks:          []string{"tcp"},
+			FilePath:       "logs/sketchfab-fetcher.log",
+			FileMaxMB:      100,
+			FileMaxBackups: 5,
+			GELFAddr:       "localhost:12201",
+			SyslogAddr:     "localhost:514",
+			SyslogNetwork:  "udp",
+		},
+		Server: ServerConfig{
+			Addr:       ":8080",
+			MaxSyncAge: 26 * time.Hour,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "http://localhost:4318",
+			SampleRatio: 1.0,
+		},
+		Reload: ReloadConfig{
+			WatchInterval: 5 * time.Second,
+		},
+		Elasticsearch: ElasticsearchConfig{
+			Index:   "sketchfab-models",
+			Timeout: 30 * time.Second,
+		},
+		Events: EventsConfig{
+			Timeout:        10 * time.Second,
+			MaxAttempts:    10,
+			RetryBaseDelay: 30 * time.Second,
+			PollInterval:   5 * time.Second,
 		},
+		Notify: NotifyConfig{
+			SMTP:    SMTPConfig{Port: 587},
+			Timeout: 30 * time.Second,
+		},
+		Download: DownloadConfig{
+			Dir:         "downloads",
+			Formats:     []string{"glb"},
+			Concurrency: 2,
+			Timeout:     30 * time.Minute,
+		},
+	}
+}
+
+// Load 載入設定：預設值 → 設定檔（path 為空時略過）→ 環境變數 → overrides（命令列參數），最後驗證
+//
+// 環境變數格式錯誤與驗證失敗會彙整成一個 *ValidationError 一次回報。
+func Load(path string, overrides ...func(*Config)) (*Config, error) {
+	config := Default()
+	if path != "" {
+		if err := readFile(path, config); err != nil {
+			return nil, err
+		}
 	}
 
-	return config
+	env := &envReader{secretsDir: secrets.DefaultDir}
+	env.str("SECRETS_DIR", &env.secretsDir)
+	env.apply(config)
+
+	for _, override := range overrides {
+		override(config)
+	}
+
+	problems := append(env.problems, config.validate()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	// 登錄機密值，日誌中出現時會被遮蔽
+	secrets.Register(config.API.SketchfabAPIKey, config.MongoDB.Username, config.MongoDB.Password, config.Server.ControlToken, config.Elasticsearch.Password, config.Events.WebhookURL,
+		config.Notify.SMTP.Password, config.Notify.WebhookURL)
+	secrets.Register(secrets.URIPassword(config.MongoDB.URI)...)
+	secrets.Register(secrets.URIPassword(config.MongoDB.ConnectionURI())...)
+	return config, nil
+}
+
+// ScheduleTimeOf 取得任務的執行時間，未指定時使用 schedule.time
+func (c *Config) ScheduleTimeOf(job JobConfig) string {
+	if job.Time != "" {
+		return job.Time
+	}
+	return c.Schedule.Time
+}
+
+// envReader 讀取環境變數並記錄格式錯誤
+type envReader struct {
+	secretsDir string // Docker/Kubernetes secrets 掛載目錄
+	problems   []string
 }
 
-// getEnvOrDefault 取得環境變數或預設值
-func getEnvOrDefault(key, defaultValue string) string {
+// apply 以已設定的環境變數覆寫設定
+func (e *envReader) apply(c *Config) {
+	e.secret("SKETCHFAB_API_KEY", &c.API.SketchfabAPIKey)
+	e.str("SKETCHFAB_API_URL", &c.API.BaseURL)
+	e.duration("SKETCHFAB_API_TIMEOUT", &c.API.Timeout)
+	e.int("SKETCHFAB_API_MAX_RETRIES", &c.API.MaxRetries)
+	e.duration("SKETCHFAB_API_RETRY_DELAY", &c.API.RetryBaseDelay)
+	e.duration("SKETCHFAB_API_RATE_LIMIT", &c.API.RateLimit)
+
+	e.secret("MONGODB_URI", &c.MongoDB.URI)
+	e.secret("MONGODB_USERNAME", &c.MongoDB.Username)
+	e.secret("MONGODB_PASSWORD", &c.MongoDB.Password)
+	e.str("MONGODB_DATABASE", &c.MongoDB.Database)
+	e.duration("MONGODB_TIMEOUT", &c.MongoDB.Timeout)
+
+	e.str("SCHEDULE_TIME", &c.Schedule.Time)
+
+	e.str("LOGSTASH_HOST", &c.Logstash.Host)
+	e.str("LOGSTASH_PORT", &c.Logstash.Port)
+	e.str("LOG_LEVEL", &c.Logstash.Level)
+	e.int("LOG_QUEUE_SIZE", &c.Logstash.QueueSize)
+	e.int("LOG_BATCH_SIZE", &c.Logstash.BatchSize)
+	e.duration("LOG_FLUSH_INTERVAL", &c.Logstash.FlushInterval)
+	e.str("LOG_DROP_POLICY", &c.Logstash.DropPolicy)
+	e.str("LOG_SPOOL_PATH", &c.Logstash.SpoolPath)
+	e.int("LOG_SPOOL_MAX_MB", &c.Logstash.SpoolMaxMB)
+	e.duration("LOG_RECONNECT_MAX_DELAY", &c.Logstash.ReconnectMax)
+	e.list("LOG_SINKS", &c.Logstash.Sinks)
+	e.str("LOG_FILE_PATH", &c.Logstash.FilePath)
+	e.int("LOG_FILE_MAX_MB", &c.Logstash.FileMaxMB)
+	e.int("LOG_FILE_MAX_BACKUPS", &c.Logstash.FileMaxBackups)
+	e.str("LOG_GELF_ADDR", &c.Logstash.GELFAddr)
+	e.str("LOG_SYSLOG_ADDR", &c.Logstash.SyslogAddr)
+	e.str("LOG_SYSLOG_NETWORK", &c.Logstash.SyslogNetwork)
+
+	e.str("HTTP_ADDR", &c.Server.Addr)
+	e.secret("CONTROL_API_TOKEN", &c.Server.ControlToken)
+	e.duration("HEALTH_MAX_SYNC_AGE", &c.Server.MaxSyncAge)
+
+	e.str("OTEL_TRACES_EXPORTER", &c.Tracing.Exporter)
+	e.str("OTEL_EXPORTER_OTLP_ENDPOINT", &c.Tracing.Endpoint)
+	e.float("OTEL_TRACES_SAMPLER_ARG", &c.Tracing.SampleRatio)
+
+	e.duration("CONFIG_WATCH_INTERVAL", &c.Reload.WatchInterval)
+
+	e.str("ELASTICSEARCH_URL", &c.Elasticsearch.URL)
+	e.str("ELASTICSEARCH_INDEX", &c.Elasticsearch.Index)
+	e.secret("ELASTICSEARCH_USERNAME", &c.Elasticsearch.Username)
+	e.secret("ELASTICSEARCH_PASSWORD", &c.Elasticsearch.Password)
+	e.duration("ELASTICSEARCH_TIMEOUT", &c.Elasticsearch.Timeout)
+
+	e.secret("EVENTS_WEBHOOK_URL", &c.Events.WebhookURL)
+	e.boolean("EVENTS_SUBSCRIPTIONS", &c.Events.Subscriptions)
+	e.duration("EVENTS_TIMEOUT", &c.Events.Timeout)
+	e.int("EVENTS_MAX_ATTEMPTS", &c.Events.MaxAttempts)
+	e.duration("EVENTS_RETRY_DELAY", &c.Events.RetryBaseDelay)
+	e.duration("EVENTS_POLL_INTERVAL", &c.Events.PollInterval)
+
+	e.str("NOTIFY_SMTP_HOST", &c.Notify.SMTP.Host)
+	e.int("NOTIFY_SMTP_PORT", &c.Notify.SMTP.Port)
+	e.secret("NOTIFY_SMTP_USERNAME", &c.Notify.SMTP.Username)
+	e.secret("NOTIFY_SMTP_PASSWORD", &c.Notify.SMTP.Password)
+	e.str("NOTIFY_SMTP_FROM", &c.Notify.SMTP.From)
+	e.list("NOTIFY_SMTP_TO", &c.Notify.SMTP.To)
+	e.secret("NOTIFY_WEBHOOK_URL", &c.Notify.WebhookURL)
+	e.duration("NOTIFY_TIMEOUT", &c.Notify.Timeout)
+
+	e.str("REPORT_DIR", &c.Report.Dir)
+	e.boolean("REPORT_NOTIFY", &c.Report.Notify)
+
+	e.str("DOWNLOAD_DIR", &c.Download.Dir)
+	e.list("DOWNLOAD_FORMATS", &c.Download.Formats)
+	e.int("DOWNLOAD_CONCURRENCY", &c.Download.Concurrency)
+	e.duration("DOWNLOAD_TIMEOUT", &c.Download.Timeout)
+	e.int("DOWNLOAD_MAX_SIZE_MB", &c.Download.MaxSizeMB)
+}
+
+// str 讀取字串環境變數
+func (e *envReader) str(key string, target *string) {
 	if value := os.Getenv(key); value != "" {
-		return value
+		*target = value
 	}
-	return defaultValue
 }
 
-// getDurationEnvOrDefault 取得時間間隔環境變數或預設值
-func getDurationEnvOrDefault(key string, defaultValue time.Duration) time.Duration {
+// secret 讀取機密設定，優先順序由低到高：掛載目錄中的同名小寫檔案（例如 /run/secrets/sketchfab_api_key）、
+// 環境變數 KEY、KEY_FILE 指向的檔案；KEY 與 KEY_FILE 不可同時設定
+func (e *envReader) secret(key string, target *string) {
+	if value, ok, err := secrets.FromDir(e.secretsDir, strings.ToLower(key)); err != nil {
+		e.problems = append(e.problems, fmt.Sprintf("%s: %v", key, err))
+	} else if ok {
+		*target = value
+	}
+
+	fileKey := key + "_FILE"
+	path := os.Getenv(fileKey)
+	if path == "" {
+		e.str(key, target)
+		return
+	}
+	if os.Getenv(key) != "" {
+		e.problems = append(e.problems, fmt.Sprintf("%s 與 %s 不可同時設定", key, fileKey))
+		return
+	}
+	value, err := secrets.ReadFile(path)
+	if err != nil {
+		e.problems = append(e.problems, fmt.Sprintf("%s: %v", fileKey, err))
+		return
+	}
+	*target = value
+}
+
+// list 讀取以逗號分隔的環境變數
+func (e *envReader) list(key string, target *[]string) {
+	value := os.Getenv(key)
+	if value == "" {
+		return
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*target = list
+}
+
+// boolean 讀取布林環境變數，接受 true/false、1/0 等格式
+func (e *envReader) boolean(key string, target *bool) {
+	if value := os.Getenv(key); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			e.problems = append(e.problems, fmt.Sprintf("%s: 無效的布林值 %q", key, value))
+			return
+		}
+		*target = b
+	}
+}
+
+// int 讀取整數環境變數
+func (e *envReader) int(key string, target *int) {
 	if value := os.Getenv(key); value != "" {
-		if seconds, err := strconv.Atoi(value); err == nil {
-			return time.Duration(seconds) * time.Second
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			e.problems = append(e.problems, fmt.Sprintf("%s: 無效的整數 %q", key, value))
+			return
 		}
+		*target = n
+	}
+}
+
+// float 讀取浮點數環境變數
+func (e *envReader) float(key string, target *float64) {
+	if value := os.Getenv(key); value != "" {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			e.problems = append(e.problems, fmt.Sprintf("%s: 無效的數字 %q", key, value))
+			return
+		}
+		*target = f
+	}
+}
+
+// duration 讀取時間間隔環境變數，純數字視為秒數，也接受 30s、5m 等格式
+func (e *envReader) duration(key string, target *time.Duration) {
+	if value := os.Getenv(key); value != "" {
+		d, err := ParseDuration(value)
+		if err != nil {
+			e.problems = append(e.problems, fmt.Sprintf("%s: 無效的時間間隔 %q（請使用秒數或 30s、5m 等格式）", key, value))
+			return
+		}
+		*target = d
+	}
+}
+
+// ParseDuration 解析時間間隔，純數字視為秒數
+func ParseDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
 	}
-	return defaultValue
+	return time.ParseDuration(value)
 }
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// readFile 讀取 YAML 或 TOML 設定檔（依副檔名判斷）並覆寫 config 中出現的欄位
+//
+// 時間間隔以字串表示，例如 "30s"、"1h30m"；未知的欄位視為錯誤，避免拼錯的設定被默默忽略。
+func readFile(path string, config *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("讀取設定檔失敗: %v", err)
+	}
+
+	// 設定檔有定義 jobs 時整批取代預設任務，而不是逐筆合併到預設任務上
+	defaultJobs := config.Jobs
+	config.Jobs = nil
+	defer func() {
+		if config.Jobs == nil {
+			config.Jobs = defaultJobs
+		}
+	}()
+
+	switch formatOf(path) {
+	case "yaml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("解析設定檔 %s 失敗: %v", path, err)
+		}
+	case "toml":
+		meta, err := toml.Decode(string(data), config)
+		if err != nil {
+			return fmt.Errorf("解析設定檔 %s 失敗: %v", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			keys := make([]string, len(undecoded))
+			for i, key := range undecoded {
+				keys[i] = key.String()
+			}
+			return fmt.Errorf("設定檔 %s 含有未知的欄位: %s", path, strings.Join(keys, ", "))
+		}
+	default:
+		return fmt.Errorf("不支援的設定檔格式: %s（可用: .yaml、.yml、.toml）", filepath.Ext(path))
+	}
+	return nil
+}
+
+// formatOf 依副檔名判斷設定檔格式
+func formatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	}
+	return ""
+}
+
+// Encode 以 yaml 或 toml 格式輸出設定
+func Encode(w io.Writer, config *Config, format string) error {
+	switch format {
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(config); err != nil {
+			return fmt.Errorf("輸出設定失敗: %v", err)
+		}
+		return encoder.Close()
+	case "toml":
+		if err := toml.NewEncoder(w).Encode(config); err != nil {
+			return fmt.Errorf("輸出設定失敗: %v", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("不支援的輸出格式: %s（可用: yaml、toml）", format)
+}
@@ -0,0 +1,22 @@
+package config
+
+import "fetch-sketchfab-data/internal/secrets"
+
+// Redacted 回傳遮蔽機密後的設定副本，供輸出或記錄日誌使用
+func (c *Config) Redacted() *Config {
+	out := *c
+	out.Jobs = append([]JobConfig(nil), c.Jobs...)
+	out.Logstash.Sinks = append([]string(nil), c.Logstash.Sinks...)
+
+	out.API.SketchfabAPIKey = secrets.Mask(c.API.SketchfabAPIKey)
+	out.MongoDB.URI = secrets.RedactURI(c.MongoDB.URI)
+	out.MongoDB.Username = secrets.Mask(c.MongoDB.Username) // 可能來自 MONGODB_USERNAME_FILE 或 secrets 掛載，與密碼同樣處理
+	out.MongoDB.Password = secrets.Mask(c.MongoDB.Password)
+	out.Server.ControlToken = secrets.Mask(c.Server.ControlToken)
+	out.Elasticsearch.Password = secrets.Mask(c.Elasticsearch.Password)
+	out.Events.WebhookURL = secrets.Mask(c.Events.WebhookURL)
+	out.Notify.SMTP.To = append([]string(nil), c.Notify.SMTP.To...)
+	out.Notify.SMTP.Password = secrets.Mask(c.Notify.SMTP.Password)
+	out.Notify.WebhookURL = secrets.Mask(c.Notify.WebhookURL)
+	return &out
+}
@@ -0,0 +1,269 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// liveFields 可以在執行中套用的設定，其餘欄位變更後需重新啟動才會生效；新增時需同步修改 withLive
+var liveFields = []string{"schedule.", "jobs", "logging.level", "api.rate_limit"}
+
+// Change 一個設定欄位的變更
+type Change struct {
+	Field string // 例如 schedule.time、jobs[lowpoly].tags
+	Old   string // 機密欄位已遮蔽
+	New   string
+	Live  bool // 是否可在執行中套用
+}
+
+// String 以「欄位: 舊值 → 新值」表示變更
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s → %s", c.Field, displayValue(c.Old), displayValue(c.New))
+}
+
+// displayValue 空值以 (空) 表示
+func displayValue(value string) string {
+	if value == "" {
+		return "(空)"
+	}
+	return value
+}
+
+// Diff 比較兩份設定，回傳依欄位名稱排序的變更清單；任務以名稱比對，機密欄位只顯示遮蔽後的值
+func Diff(old, next *Config) []Change {
+	before, after := flatten(old), flatten(next)
+	shownBefore, shownAfter := flatten(old.Redacted()), flatten(next.Redacted())
+
+	// 新增或移除的任務以一筆變更表示，不逐欄列出
+	oldJobs, nextJobs := jobNames(old), jobNames(next)
+	var changes []Change
+	for name := range nextJobs {
+		if !oldJobs[name] {
+			changes = append(changes, newChange(jobField(name), "", "(新增任務)"))
+		}
+	}
+	for name := range oldJobs {
+		if !nextJobs[name] {
+			changes = append(changes, newChange(jobField(name), "(原有任務)", "(已移除)"))
+		}
+	}
+	skip := func(field string) bool {
+		if name, ok := jobOf(field); ok {
+			return !oldJobs[name] || !nextJobs[name]
+		}
+		return false
+	}
+
+	for field, value := range after {
+		if skip(field) {
+			continue
+		}
+		if prev, ok := before[field]; !ok || prev != value {
+			changes = append(changes, newChange(field, shownBefore[field], shownAfter[field]))
+		}
+	}
+	for field := range before {
+		if _, ok := after[field]; !ok && !skip(field) {
+			changes = append(changes, newChange(field, shownBefore[field], ""))
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// jobNames 取得設定中所有任務的名稱
+func jobNames(config *Config) map[string]bool {
+	names := make(map[string]bool, len(config.Jobs))
+	for _, job := range config.Jobs {
+		names[job.Name] = true
+	}
+	return names
+}
+
+// jobField 任務在變更清單中的欄位名稱
+func jobField(name string) string {
+	return "jobs[" + name + "]"
+}
+
+// jobOf 從 jobs[name].field 形式的欄位取出任務名稱
+func jobOf(field string) (string, bool) {
+	rest, ok := strings.CutPrefix(field, "jobs[")
+	if !ok {
+		return "", false
+	}
+	name, _, ok := strings.Cut(rest, "].")
+	return name, ok
+}
+
+// newChange 建立變更並判斷是否可即時套用
+func newChange(field, old, next string) Change {
+	change := Change{Field: field, Old: old, New: next}
+	for _, prefix := range liveFields {
+		if strings.HasPrefix(field, prefix) {
+			change.Live = true
+			break
+		}
+	}
+	return change
+}
+
+// flatten 將設定展開為「欄位路徑 → 值」，欄位名稱使用設定檔中的名稱
+func flatten(config *Config) map[string]string {
+	out := map[string]string{}
+	flattenValue(out, "", reflect.ValueOf(*config))
+	return out
+}
+
+// flattenValue 遞迴展開結構、任務清單與一般值
+func flattenValue(out map[string]string, prefix string, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Struct:
+		t := value.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			flattenValue(out, name, value.Field(i))
+		}
+	case reflect.Slice:
+		if jobs, ok := value.Interface().([]JobConfig); ok {
+			for _, job := range jobs {
+				flattenValue(out, jobField(job.Name), reflect.ValueOf(job))
+			}
+			return
+		}
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(value.Index(i).Interface())
+		}
+		out[prefix] = strings.Join(items, ",")
+	default:
+		if d, ok := value.Interface().(time.Duration); ok {
+			out[prefix] = d.String()
+			return
+		}
+		out[prefix] = fmt.Sprint(value.Interface())
+	}
+}
+
+// Reloader 重新載入設定檔並將新設定交給 apply 套用
+//
+// 交給 apply 與 Current 回傳的設定只有可即時套用的欄位會更新，其餘欄位維持啟動時的值；
+// 新設定驗證失敗時保留目前的設定；Reload 與 Watch 可同時使用，套用會依序進行。
+type Reloader struct {
+	path      string
+	overrides []func(*Config)
+	apply     func(old, next *Config, changes []Change)
+
+	mu      sync.Mutex
+	current *Config
+	stamp   fileStamp
+}
+
+// fileStamp 設定檔的修改時間與大小，用於偵測變更
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader 建立設定重新載入器，overrides 與 Load 相同（命令列參數在重新載入後仍優先）
+func NewReloader(path string, current *Config, apply func(old, next *Config, changes []Change), overrides ...func(*Config)) *Reloader {
+	r := &Reloader{path: path, overrides: overrides, apply: apply, current: current}
+	r.stamp, _ = statFile(path)
+	return r
+}
+
+// Current 取得目前生效的設定
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload 重新載入設定檔，驗證失敗時回傳錯誤並保留目前的設定；設定沒有變更時不會呼叫 apply
+func (r *Reloader) Reload() ([]Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.path == "" {
+		return nil, fmt.Errorf("未指定設定檔，無法重新載入")
+	}
+	r.stamp, _ = statFile(r.path)
+
+	next, err := Load(r.path, r.overrides...)
+	if err != nil {
+		return nil, err
+	}
+	changes := Diff(r.current, next)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	// 只推進可即時套用的欄位，需要重新啟動的變更在重新啟動前每次重新載入都會再列出
+	old := r.current
+	r.current = withLive(old, next)
+	r.apply(old, r.current, changes)
+	return changes, nil
+}
+
+// withLive 複製 current 並換上 next 中可即時套用的欄位（對應 liveFields）
+func withLive(current, next *Config) *Config {
+	applied := *current
+	applied.Schedule = next.Schedule
+	applied.Jobs = next.Jobs
+	applied.Logstash.Level = next.Logstash.Level
+	applied.API.RateLimit = next.API.RateLimit
+	return &applied
+}
+
+// Watch 每隔 interval 檢查設定檔的修改時間與大小，有變更時重新載入並將結果交給 handle，直到 ctx 結束
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, handle func(changes []Change, err error)) {
+	if r.path == "" || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stamp, err := statFile(r.path)
+			if err != nil {
+				// 編輯器儲存時可能短暫刪除檔案，下次再檢查
+				continue
+			}
+			r.mu.Lock()
+			changed := !stamp.equal(r.stamp)
+			r.mu.Unlock()
+			if changed {
+				handle(r.Reload())
+			}
+		}
+	}
+}
+
+// equal 比較兩個檔案狀態是否相同
+func (s fileStamp) equal(other fileStamp) bool {
+	return s.modTime.Equal(other.modTime) && s.size == other.size
+}
+
+// statFile 取得檔案的修改時間與大小
+func statFile(path string) (fileStamp, error) {
+	if path == "" {
+		return fileStamp{}, fmt.Errorf("未指定設定檔")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
@@ -0,0 +1,232 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError 彙整所有設定錯誤，一次回報
+type ValidationError struct {
+	Problems []string
+}
+
+// Error 實作 error，每個問題一行
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("設定有 %d 個錯誤:\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// Validate 檢查設定是否有效，回傳 *ValidationError 或 nil
+func (c *Config) Validate() error {
+	if problems := c.validate(); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validate 檢查所有欄位並回傳問題清單
+func (c *Config) validate() []string {
+	v := &validator{}
+
+	// API
+	v.url("api.base_url", c.API.BaseURL)
+	v.positive("api.timeout", c.API.Timeout)
+	v.check(c.API.MaxRetries >= 0, "api.max_retries 不可為負數")
+	v.check(c.API.RetryBaseDelay >= 0, "api.retry_base_delay 不可為負數")
+	v.check(c.API.RateLimit >= 0, "api.rate_limit 不可為負數")
+
+	// MongoDB
+	v.check(strings.HasPrefix(c.MongoDB.URI, "mongodb://") || strings.HasPrefix(c.MongoDB.URI, "mongodb+srv://"),
+		"mongodb.uri 必須以 mongodb:// 或 mongodb+srv:// 開頭")
+	v.check(c.MongoDB.Database != "", "mongodb.database 不可為空")
+	v.positive("mongodb.timeout", c.MongoDB.Timeout)
+
+	// 排程與任務
+	v.clock("schedule.time", c.Schedule.Time)
+	v.check(len(c.Jobs) > 0, "jobs 至少需要一個任務")
+	names := map[string]bool{}
+	for i, job := range c.Jobs {
+		field := fmt.Sprintf("jobs[%d]", i)
+		if job.Name == "" {
+			v.add("%s.name 不可為空", field)
+		} else if names[job.Name] {
+			v.add("%s.name 重複: %s", field, job.Name)
+		}
+		names[job.Name] = true
+		if job.Time != "" {
+			v.clock(field+".time", job.Time)
+		}
+		v.check(job.MaxPages >= -1, field+".max_pages 必須大於等於 -1（-1 表示取到最後一頁）")
+		v.check(job.Count >= 0 && job.Count <= 100, field+".count 必須介於 0 到 100")
+	}
+
+	// 日誌
+	switch strings.ToUpper(c.Logstash.Level) {
+	case "DEBUG", "INFO", "WARN", "ERROR":
+	default:
+		v.add("logging.level 無效: %q（可用: DEBUG、INFO、WARN、ERROR）", c.Logstash.Level)
+	}
+	v.check(c.Logstash.QueueSize > 0, "logging.queue_size 必須大於 0")
+	v.check(c.Logstash.BatchSize > 0, "logging.batch_size 必須大於 0")
+	v.positive("logging.flush_interval", c.Logstash.FlushInterval)
+	v.oneOf("logging.drop_policy", c.Logstash.DropPolicy, "drop_newest", "drop_oldest", "block")
+	v.positive("logging.reconnect_max", c.Logstash.ReconnectMax)
+	v.check(len(c.Logstash.Sinks) > 0, "logging.sinks 至少需要一個 sink")
+	for _, sink := range c.Logstash.Sinks {
+		switch sink {
+		case "tcp", "udp":
+			v.hostPort("logging.host/port", net.JoinHostPort(c.Logstash.Host, c.Logstash.Port))
+			if sink == "tcp" {
+				v.check(c.Logstash.SpoolMaxMB >= 0, "logging.spool_max_mb 不可為負數")
+				v.check(c.Logstash.SpoolMaxMB == 0 || c.Logstash.SpoolPath != "", "logging.spool_path 不可為空")
+			}
+		case "file":
+			v.check(c.Logstash.FilePath != "", "logging.file_path 不可為空")
+			v.check(c.Logstash.FileMaxMB >= 0, "logging.file_max_mb 不可為負數")
+			v.check(c.Logstash.FileMaxBackups >= 0, "logging.file_max_backups 不可為負數")
+		case "gelf":
+			v.hostPort("logging.gelf_addr", c.Logstash.GELFAddr)
+		case "syslog":
+			v.hostPort("logging.syslog_addr", c.Logstash.SyslogAddr)
+			v.oneOf("logging.syslog_network", c.Logstash.SyslogNetwork, "udp", "tcp")
+		case "console", "json":
+		default:
+			v.add("logging.sinks 含有未知的 sink: %q（可用: console、json、file、tcp、udp、gelf、syslog）", sink)
+		}
+	}
+
+	// HTTP 伺服器
+	v.hostPort("server.addr", c.Server.Addr)
+	v.positive("server.max_sync_age", c.Server.MaxSyncAge)
+
+	// 追蹤
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, "none", "otlp", "stdout")
+	if c.Tracing.Exporter == "otlp" {
+		v.url("tracing.endpoint", c.Tracing.Endpoint)
+	}
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio 必須介於 0 到 1")
+
+	// 重新載入
+	v.check(c.Reload.WatchInterval >= 0, "reload.watch_interval 不可為負數")
+
+	// 搜尋索引
+	if c.Elasticsearch.URL != "" {
+		v.url("elasticsearch.url", c.Elasticsearch.URL)
+		v.check(c.Elasticsearch.Index != "" && c.Elasticsearch.Index == strings.ToLower(c.Elasticsearch.Index),
+			"elasticsearch.index 不可為空且必須為小寫")
+		v.positive("elasticsearch.timeout", c.Elasticsearch.Timeout)
+	}
+
+	// 模型變更事件
+	if c.Events.Enabled() {
+		if c.Events.WebhookURL != "" {
+			v.url("events.webhook_url", c.Events.WebhookURL)
+		}
+		v.positive("events.timeout", c.Events.Timeout)
+		v.check(c.Events.MaxAttempts > 0, "events.max_attempts 必須大於 0")
+		v.positive("events.retry_base_delay", c.Events.RetryBaseDelay)
+		v.positive("events.poll_interval", c.Events.PollInterval)
+	}
+
+	// 通知
+	if c.Notify.SMTP.Host != "" {
+		v.check(c.Notify.SMTP.Port > 0 && c.Notify.SMTP.Port <= 65535, "notify.smtp.port 必須介於 1 到 65535")
+		v.email("notify.smtp.from", c.Notify.SMTP.From)
+		for _, to := range c.Notify.SMTP.To {
+			v.email("notify.smtp.to", to)
+		}
+	}
+	if c.Notify.WebhookURL != "" {
+		v.url("notify.webhook_url", c.Notify.WebhookURL)
+	}
+	if c.Notify.Enabled() {
+		v.positive("notify.timeout", c.Notify.Timeout)
+	}
+
+	// 同步報告
+	v.check(!c.Report.Notify || c.Notify.Enabled(), "report.notify 需要設定 notify.smtp.host 或 notify.webhook_url")
+
+	// 檔案封存下載
+	v.check(c.Download.Dir != "", "download.dir 不可為空")
+	v.check(len(c.Download.Formats) > 0, "download.formats 至少需要一種格式")
+	for _, format := range c.Download.Formats {
+		v.oneOf("download.formats", format, "glb", "gltf", "usdz", "source")
+	}
+	v.check(c.Download.Concurrency > 0, "download.concurrency 必須大於 0")
+	v.positive("download.timeout", c.Download.Timeout)
+	v.check(c.Download.MaxSizeMB >= 0, "download.max_size_mb 不可為負數")
+
+	return v.problems
+}
+
+// validator 收集驗證問題
+type validator struct {
+	problems []string
+}
+
+// add 加入一個問題
+func (v *validator) add(format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// check 條件不成立時加入問題
+func (v *validator) check(ok bool, problem string) {
+	if !ok {
+		v.problems = append(v.problems, problem)
+	}
+}
+
+// positive 檢查時間間隔大於 0
+func (v *validator) positive(field string, d time.Duration) {
+	if d <= 0 {
+		v.add("%s 必須大於 0（目前為 %s）", field, d)
+	}
+}
+
+// oneOf 檢查值是否在允許清單中
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.add("%s 無效: %q（可用: %s）", field, value, strings.Join(allowed, "、"))
+}
+
+// clock 檢查 HH:MM 格式的時間
+func (v *validator) clock(field, value string) {
+	if _, err := time.Parse("15:04", value); err != nil {
+		v.add("%s 格式錯誤: %q（請使用 HH:MM，24 小時制）", field, value)
+	}
+}
+
+// email 檢查 email 地址（可包含顯示名稱）
+func (v *validator) email(field, value string) {
+	if _, err := mail.ParseAddress(value); err != nil {
+		v.add("%s 不是有效的 email 地址: %q", field, value)
+	}
+}
+
+// url 檢查絕對 http(s) URL
+func (v *validator) url(field, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add("%s 不是有效的 http(s) URL: %q", field, value)
+	}
+}
+
+// hostPort 檢查 host:port 格式（host 可省略）
+func (v *validator) hostPort(field, value string) {
+	_, port, err := net.SplitHostPort(value)
+	if err != nil {
+		v.add("%s 格式錯誤: %q（請使用 host:port）", field, value)
+		return
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		v.add("%s 的連接埠無效: %q", field, port)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
+	"fetch-sketchfab-data/internal/secrets"
+
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -48,16 +51,16 @@ func NewMongoDBClient(config *MongoDBConfig) (*MongoDBClient, error) {
 
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		return nil, fmt.Errorf("無法連線到MongoDB: %v", err)
+		return nil, fmt.Errorf("無法連線到MongoDB: %s", redactError(config.URI, err))
 	}
 
 	// 測試連線
 	err = client.Ping(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("MongoDB連線測試失敗: %v", err)
+		return nil, fmt.Errorf("MongoDB連線測試失敗: %s", redactError(config.URI, err))
 	}
 
-	log.Printf("成功連線到MongoDB: %s", config.URI)
+	log.Printf("成功連線到MongoDB: %s", secrets.RedactURI(config.URI))
 
 	return &MongoDBClient{
 		client:   client,
@@ -99,12 +102,31 @@ func (m *MongoDBClient) IsConnected() bool {
 	return err == nil
 }
 
-// GetConnectionInfo 取得連線資訊
+// Ping 在指定的 context 內測試連線
+func (m *MongoDBClient) Ping(ctx context.Context) error {
+	if err := m.client.Ping(ctx, nil); err != nil {
+		return fmt.Errorf("MongoDB連線測試失敗: %v", err)
+	}
+	return nil
+}
+
+// GetConnectionInfo 取得連線資訊，URI 中的密碼會被遮蔽
 func (m *MongoDBClient) GetConnectionInfo() map[string]interface{} {
 	return map[string]interface{}{
-		"uri":       m.config.URI,
+		"uri":       secrets.RedactURI(m.config.URI),
 		"database":  m.config.Database,
 		"timeout":   m.config.Timeout.String(),
 		"connected": m.IsConnected(),
 	}
 }
+
+// redactError 遮蔽錯誤訊息中的連線字串與密碼（驅動程式的 URI 解析錯誤可能包含原始 URI）
+func redactError(uri string, err error) string {
+	message := strings.ReplaceAll(err.Error(), uri, secrets.RedactURI(uri))
+	for _, password := range secrets.URIPassword(uri) {
+		if password != "" {
+			message = strings.ReplaceAll(message, password, secrets.Redacted)
+		}
+	}
+	return message
+}
@@ -0,0 +1,460 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fetch-sketchfab-data/internal/api"
+	"fetch-sketchfab-data/internal/metrics"
+	"fetch-sketchfab-data/internal/service"
+)
+
+// Formats 下載 API 提供的檔案封存格式
+var Formats = []string{"glb", "gltf", "usdz", "source"}
+
+// defaultExtensions 下載網址沒有副檔名時使用的副檔名
+var defaultExtensions = map[string]string{
+	"glb":    ".glb",
+	"gltf":   ".zip",
+	"usdz":   ".usdz",
+	"source": ".zip",
+}
+
+// errSkipped 表示此格式不需下載（沒有提供或超過大小上限），不計為失敗
+var errSkipped = errors.New("略過")
+
+// errAlreadyDownloaded 表示先前的執行已完成下載且檔案仍完整，計為略過
+var errAlreadyDownloaded = errors.New("已下載")
+
+// Config 下載設定
+type Config struct {
+	Dir         string        // 下載目錄，檔案寫入 <Dir>/<uid>/<uid>-<格式>.<副檔名>
+	Formats     []string      // 要下載的格式
+	Concurrency int           // 同時下載的模型數
+	Timeout     time.Duration // 單一檔案的下載逾時
+	MaxSize     int64         // 單一檔案的大小上限（位元組），0 表示不限制
+	Force       bool          // 已完成的檔案也重新下載
+}
+
+// Result 一次下載的統計
+type Result struct {
+	Models    int   `json:"models"`
+	Completed int   `json:"completed"`
+	Skipped   int   `json:"skipped"`
+	Failed    int   `json:"failed"`
+	Bytes     int64 `json:"bytes"` // 本次寫入的位元組數（不含續傳前已下載的部分）
+}
+
+// task 一個待下載的模型，只保留下載需要的欄位，避免在下載期間佔住資料庫游標
+type task struct {
+	id       string
+	name     string
+	archives map[string]int64 // 格式 → Archive.Size
+}
+
+// Downloader 透過 Sketchfab 下載 API 下載模型的檔案封存，支援續傳並記錄每個模型的下載狀態
+type Downloader struct {
+	client     *api.SketchfabClient
+	downloads  *service.DownloadsService
+	logService *service.LogService
+	httpClient *http.Client
+	config     Config
+
+	mu     sync.Mutex
+	result Result
+}
+
+// NewDownloader 建立新的下載器
+func NewDownloader(client *api.SketchfabClient, downloads *service.DownloadsService, logService *service.LogService, config Config) *Downloader {
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	return &Downloader{
+		client:     client,
+		downloads:  downloads,
+		logService: logService.With("component", "download"),
+		// 檔案大小不一，逾時由每個檔案的 context 控制
+		httpClient: &http.Client{},
+		config:     config,
+	}
+}
+
+// Run 下載符合條件的可下載模型，同時最多下載 Concurrency 個模型
+//
+// 先讀出所有符合的模型再開始下載；中斷時已下載的部分會保留為 .part 檔，下次執行時以 Range 請求續傳。
+func (d *Downloader) Run(ctx context.Context, modelsService *service.ModelsService, filter *service.ModelFilter) (*Result, error) {
+	if d.client.APIKey == "" {
+		return nil, api.ErrNoAPIKey
+	}
+	downloadable := true
+	filter.Downloadable = &downloadable
+	filter.WithRawData = true
+
+	var tasks []*task
+	_, err := modelsService.StreamModels(ctx, filter, func(model *service.SketchfabModel) error {
+		t := &task{id: model.ID, name: model.Name, archives: map[string]int64{}}
+		for _, archive := range model.ArchiveList() {
+			t.archives[archive.Format] = int64(archive.Size)
+		}
+		tasks = append(tasks, t)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查詢模型失敗: %v", err)
+	}
+	d.result = Result{Models: len(tasks)}
+	d.logService.Info(fmt.Sprintf("⬇️ 開始下載 %d 個模型", len(tasks)), "formats", d.config.Formats, "concurrency", d.config.Concurrency)
+
+	queue := make(chan *task)
+	var wg sync.WaitGroup
+	for i := 0; i < d.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range queue {
+				d.downloadModel(ctx, t)
+			}
+		}()
+	}
+feed:
+	for _, t := range tasks {
+		select {
+		case queue <- t:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	result := d.result
+	return &result, ctx.Err()
+}
+
+// downloadModel 下載一個模型的所有指定格式
+func (d *Downloader) downloadModel(ctx context.Context, t *task) {
+	modelLog := d.logService.With("uid", t.id)
+	existing, err := d.downloads.GetDownload(ctx, t.id)
+	if err != nil {
+		modelLog.Warn("⚠️ 讀取下載紀錄失敗", "error", err)
+	}
+
+	for _, format := range d.config.Formats {
+		if ctx.Err() != nil {
+			return
+		}
+		record := &service.ArchiveDownload{Size: t.archives[format]}
+		if existing != nil && existing.Archives[format] != nil {
+			record = existing.Archives[format]
+			if size, ok := t.archives[format]; ok {
+				record.Size = size
+			}
+		}
+
+		bytes, err := d.downloadArchive(ctx, t, format, record)
+		d.count(format, bytes, err)
+		switch {
+		case errors.Is(err, errAlreadyDownloaded):
+			modelLog.Debug("檔案封存已下載", "format", format, "path", record.Path)
+		case errors.Is(err, errSkipped):
+			modelLog.Debug("略過檔案封存", "format", format, "reason", record.Error)
+		case err != nil:
+			modelLog.Error("❌ 下載檔案封存失敗", "format", format, "error", err)
+		case bytes > 0:
+			modelLog.Info("✅ 已下載檔案封存", "format", format, "path", record.Path, "size", record.Size)
+		}
+	}
+}
+
+// count 累計下載結果
+func (d *Downloader) count(format string, bytes int64, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.result.Bytes += bytes
+	metrics.ArchiveDownloadBytes.WithLabelValues(format).Add(float64(bytes))
+	outcome := service.DownloadCompleted
+	switch {
+	case errors.Is(err, errSkipped), errors.Is(err, errAlreadyDownloaded):
+		d.result.Skipped++
+		outcome = service.DownloadSkipped
+	case err != nil:
+		d.result.Failed++
+		outcome = service.DownloadFailed
+	default:
+		d.result.Completed++
+	}
+	metrics.ArchiveDownloads.WithLabelValues(format, outcome).Inc()
+}
+
+// downloadArchive 下載單一格式並更新下載紀錄，回傳本次寫入的位元組數
+//
+// 連線中斷或下載網址失效時重新取得下載網址並從部分檔案的結尾續傳，最多嘗試 client.MaxRetries+1 次。
+func (d *Downloader) downloadArchive(ctx context.Context, t *task, format string, record *service.ArchiveDownload) (int64, error) {
+	// 狀態寫入不受中斷影響，確保中斷時也留下續傳紀錄
+	saveCtx := context.WithoutCancel(ctx)
+	save := func() {
+		if err := d.downloads.SaveArchive(saveCtx, t.id, t.name, format, record); err != nil {
+			d.logService.Warn("⚠️ 寫入下載紀錄失敗", "uid", t.id, "format", format, "error", err)
+		}
+	}
+	skip := func(reason string) (int64, error) {
+		record.Status = service.DownloadSkipped
+		record.Error = reason
+		save()
+		return 0, errSkipped
+	}
+
+	// uid 會成為下載目錄下的路徑，不可跳出下載目錄
+	if !service.ValidModelID(t.id) {
+		return 0, d.fail(record, &permanentError{fmt.Errorf("模型 ID 不可作為檔名: %q", t.id)}, save)
+	}
+	if record.Status == service.DownloadCompleted && !d.config.Force && d.fileComplete(record) {
+		return 0, errAlreadyDownloaded
+	}
+	if d.config.MaxSize > 0 && record.Size > d.config.MaxSize {
+		return skip(fmt.Sprintf("大小 %d 超過上限 %d", record.Size, d.config.MaxSize))
+	}
+
+	var written int64
+	var lastErr error
+	for attempt := 0; attempt <= d.client.MaxRetries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(d.client.RetryBaseDelay << (attempt - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return written, d.fail(record, ctx.Err(), save)
+			case <-timer.C:
+			}
+		}
+
+		links, err := d.client.GetDownloadLinks(ctx, t.id)
+		if err != nil {
+			// API 已自行重試，不再重複
+			return written, d.fail(record, fmt.Errorf("取得下載網址失敗: %v", err), save)
+		}
+		link := links.Link(format)
+		if link == nil {
+			return skip("模型沒有提供此格式")
+		}
+		expected := record.Size
+		if expected == 0 {
+			expected = int64(link.Size)
+		}
+		if link.Size > 0 && int64(link.Size) != expected {
+			return written, d.fail(record, fmt.Errorf("下載網址的大小 %d 與 Archive.Size %d 不符，請重新同步模型資料", link.Size, expected), save)
+		}
+		if d.config.MaxSize > 0 && expected > d.config.MaxSize {
+			return skip(fmt.Sprintf("大小 %d 超過上限 %d", expected, d.config.MaxSize))
+		}
+
+		record.Size = expected
+		record.Path = filepath.ToSlash(filepath.Join(t.id, t.id+"-"+format+extension(format, link.URL)))
+		record.Status = service.DownloadInProgress
+		record.Attempts++
+		record.Error = ""
+		record.CompletedAt = nil
+		save()
+
+		target := filepath.Join(d.config.Dir, filepath.FromSlash(record.Path))
+		n, err := d.fetch(ctx, link.URL, target, expected)
+		written += n
+		record.Bytes = fileSize(target + ".part")
+		if err == nil {
+			now := time.Now()
+			record.Status = service.DownloadCompleted
+			record.Bytes = expected
+			record.CompletedAt = &now
+			save()
+			return written, nil
+		}
+		lastErr = err
+		var permanent *permanentError
+		if errors.As(err, &permanent) || ctx.Err() != nil {
+			break
+		}
+	}
+	return written, d.fail(record, lastErr, save)
+}
+
+// fail 記錄下載失敗並回傳錯誤；被中斷時保留下載中狀態，下次執行會續傳
+func (d *Downloader) fail(record *service.ArchiveDownload, err error, save func()) error {
+	record.Status = service.DownloadFailed
+	if errors.Is(err, context.Canceled) {
+		record.Status = service.DownloadInProgress
+	}
+	record.Error = err.Error()
+	save()
+	return err
+}
+
+// fileComplete 檢查已完成的檔案是否仍存在且大小正確
+func (d *Downloader) fileComplete(record *service.ArchiveDownload) bool {
+	if record.Path == "" {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(d.config.Dir, filepath.FromSlash(record.Path)))
+	return err == nil && (record.Size == 0 || info.Size() == record.Size)
+}
+
+// permanentError 重試也不會成功的下載錯誤
+type permanentError struct {
+	err error
+}
+
+// Error 實作 error
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// fetch 將 rawURL 下載到 target；已有 target.part 時以 Range 請求續傳，完整且大小正確後才改名為 target
+//
+// expected 大於 0 時，寫入的大小超過 expected 即停止，完成時大小必須等於 expected。回傳本次寫入的位元組數。
+func (d *Downloader) fetch(ctx context.Context, rawURL, target string, expected int64) (int64, error) {
+	if d.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.config.Timeout)
+		defer cancel()
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return 0, &permanentError{fmt.Errorf("建立下載目錄失敗: %v", err)}
+	}
+	part := target + ".part"
+
+	offset := fileSize(part)
+	if expected > 0 && offset > expected {
+		// 部分檔案比預期大，無法續傳
+		os.Remove(part)
+		offset = 0
+	}
+	if expected > 0 && offset == expected {
+		return 0, finish(part, target)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return 0, &permanentError{fmt.Errorf("無法建立 HTTP 請求: %v", err)}
+	}
+	req.Header.Set("User-Agent", "fetch-sketchfab-data/1.0")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("HTTP 請求失敗: %v", err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			return 0, fmt.Errorf("續傳回應的範圍不符: %s", resp.Header.Get("Content-Range"))
+		}
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		// 伺服器不支援續傳或沒有部分檔案，從頭下載
+		offset = 0
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// 部分檔案已超出檔案結尾，下次從頭下載
+		os.Remove(part)
+		return 0, fmt.Errorf("續傳位置 %d 超出檔案大小", offset)
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return 0, fmt.Errorf("下載失敗，狀態碼: %d, 回應: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	file, err := os.OpenFile(part, flags, 0o644)
+	if err != nil {
+		return 0, &permanentError{fmt.Errorf("開啟下載檔案失敗: %v", err)}
+	}
+	var body io.Reader = resp.Body
+	if expected > 0 {
+		// 多讀一個位元組以偵測超過預期大小的回應
+		body = io.LimitReader(resp.Body, expected-offset+1)
+	}
+	n, copyErr := io.Copy(file, body)
+	syncErr := file.Sync()
+	closeErr := file.Close()
+	switch {
+	case copyErr != nil:
+		return n, fmt.Errorf("下載中斷（已下載 %d 位元組）: %v", offset+n, copyErr)
+	case syncErr != nil:
+		return n, &permanentError{fmt.Errorf("寫入下載檔案失敗: %v", syncErr)}
+	case closeErr != nil:
+		return n, &permanentError{fmt.Errorf("寫入下載檔案失敗: %v", closeErr)}
+	}
+
+	total := offset + n
+	if expected > 0 && total != expected {
+		if total > expected {
+			os.Remove(part)
+			return n, &permanentError{fmt.Errorf("檔案大小超過 Archive.Size %d", expected)}
+		}
+		return n, fmt.Errorf("下載不完整: %d / %d 位元組", total, expected)
+	}
+	return n, finish(part, target)
+}
+
+// finish 將完整的部分檔案改名為最終檔名
+func finish(part, target string) error {
+	if err := os.Rename(part, target); err != nil {
+		return &permanentError{fmt.Errorf("建立下載檔案失敗: %v", err)}
+	}
+	return nil
+}
+
+// fileSize 取得檔案大小，不存在時為 0
+func fileSize(name string) int64 {
+	info, err := os.Stat(name)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// contentRangeStart 解析 Content-Range（bytes <start>-<end>/<size>）的起始位置
+func contentRangeStart(value string) (int64, bool) {
+	value, ok := strings.CutPrefix(value, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(value, "-")
+	if !ok {
+		return 0, false
+	}
+	offset, err := strconv.ParseInt(start, 10, 64)
+	return offset, err == nil
+}
+
+// extension 取得下載檔案的副檔名：優先使用下載網址的副檔名，沒有時依格式決定
+func extension(format, rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		if ext := path.Ext(u.Path); len(ext) > 1 && len(ext) <= 8 && isAlphanumeric(ext[1:]) {
+			return strings.ToLower(ext)
+		}
+	}
+	return defaultExtensions[format]
+}
+
+// isAlphanumeric 是否只包含英數字
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
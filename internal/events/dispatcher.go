@@ -0,0 +1,126 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"fetch-sketchfab-data/internal/metrics"
+	"fetch-sketchfab-data/internal/service"
+)
+
+// maxRetryDelay 重試等待時間的上限
+const maxRetryDelay = time.Hour
+
+// DispatcherConfig 事件送出設定
+type DispatcherConfig struct {
+	BatchSize      int           // 每次從 outbox 取出的事件數
+	PollInterval   time.Duration // 檢查 outbox 的間隔
+	MaxAttempts    int           // 最大嘗試次數，超過後標記為 failed
+	RetryBaseDelay time.Duration // 第一次重試的等待時間，之後每次加倍
+	Lease          time.Duration // 取出事件後的租約，需大於送出一批事件的時間
+}
+
+// OutboxSweeper 處理停留在 pending_commit 的事件，由 service.ModelsService 實作
+type OutboxSweeper interface {
+	SweepOutbox(ctx context.Context) (committed, discarded int, err error)
+}
+
+// Dispatcher 從 outbox 取出事件交給 publisher，成功後才標記為已送達（至少一次）
+type Dispatcher struct {
+	outbox     *service.OutboxService
+	publisher  Publisher
+	logService *service.LogService
+	cfg        DispatcherConfig
+	sweeper    OutboxSweeper // nil 表示不處理 pending_commit 的事件
+}
+
+// NewDispatcher 建立新的事件 dispatcher
+func NewDispatcher(outbox *service.OutboxService, publisher Publisher, logService *service.LogService, cfg DispatcherConfig) *Dispatcher {
+	return &Dispatcher{
+		outbox:     outbox,
+		publisher:  publisher,
+		logService: logService.With("publisher", publisher.Name()),
+		cfg:        cfg,
+	}
+}
+
+// SetSweeper 設定每次送出前處理 pending_commit 事件的 sweeper
+func (d *Dispatcher) SetSweeper(sweeper OutboxSweeper) {
+	d.sweeper = sweeper
+}
+
+// Run 每隔 PollInterval 送出 outbox 中的事件，直到 ctx 結束
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.DispatchPending(ctx); err != nil && ctx.Err() == nil {
+			d.logService.Error("❌ 送出事件失敗", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchPending 送出目前所有到期的事件，回傳成功送達的筆數
+func (d *Dispatcher) DispatchPending(ctx context.Context) (int, error) {
+	if d.sweeper != nil {
+		committed, discarded, err := d.sweeper.SweepOutbox(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if committed > 0 || discarded > 0 {
+			d.logService.Warn("⚠️ 處理未確認的事件", "committed", committed, "discarded", discarded)
+		}
+	}
+
+	delivered := 0
+	for ctx.Err() == nil {
+		entries, err := d.outbox.Claim(ctx, d.cfg.BatchSize, d.cfg.Lease)
+		if err != nil {
+			return delivered, err
+		}
+		for _, entry := range entries {
+			ok, err := d.dispatch(ctx, entry)
+			if err != nil {
+				return delivered, err
+			}
+			if ok {
+				delivered++
+			}
+		}
+		if len(entries) < d.cfg.BatchSize {
+			break
+		}
+	}
+	return delivered, nil
+}
+
+// dispatch 送出單一事件並更新狀態，回傳是否送達；只有更新 outbox 失敗時回傳錯誤
+func (d *Dispatcher) dispatch(ctx context.Context, entry *service.OutboxEntry) (bool, error) {
+	eventLog := d.logService.With("event_id", entry.ID, "event_type", entry.Type, "uid", entry.ModelID)
+	publishErr := d.publisher.Publish(ctx, &entry.ModelEvent)
+	if publishErr == nil {
+		metrics.EventsPublished.WithLabelValues("delivered").Inc()
+		eventLog.Debug("📤 事件已送出")
+		return true, d.outbox.MarkDelivered(ctx, entry.ID)
+	}
+
+	attempts := entry.Attempts + 1
+	if attempts >= d.cfg.MaxAttempts {
+		metrics.EventsPublished.WithLabelValues("failed").Inc()
+		eventLog.Error("❌ 事件送出失敗，已達最大嘗試次數", "attempts", attempts, "error", publishErr)
+		return false, d.outbox.MarkFailed(ctx, entry.ID, attempts, publishErr.Error())
+	}
+
+	delay := d.cfg.RetryBaseDelay << (attempts - 1)
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	metrics.EventsPublished.WithLabelValues("retry").Inc()
+	eventLog.Warn("⚠️ 事件送出失敗，稍後重試", "attempts", attempts, "retry_in", delay.String(), "error", publishErr)
+	return false, d.outbox.MarkRetry(ctx, entry.ID, attempts, time.Now().Add(delay), publishErr.Error())
+}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// Publisher 將模型事件送到下游，回傳錯誤時事件會依退避時間重試
+//
+// 同一事件可能送出多次，消費端應以事件 ID 去除重複。
+type Publisher interface {
+	// Name publisher 名稱，用於日誌
+	Name() string
+	// Publish 送出一個事件
+	Publish(ctx context.Context, event *service.ModelEvent) error
+}
+
+// WebhookPublisher 以 HTTP POST 將事件 JSON 送到指定網址，2xx 視為成功
+type WebhookPublisher struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewWebhookPublisher 建立新的 webhook publisher
+func NewWebhookPublisher(url string, timeout time.Duration) *WebhookPublisher {
+	return &WebhookPublisher{
+		URL:        url,
+		HTTPClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name 實作 Publisher
+func (p *WebhookPublisher) Name() string {
+	return "webhook"
+}
+
+// Publish 實作 Publisher，事件類型與 ID 也放在 X-Sketchfab-Event 與 X-Sketchfab-Event-Id 標頭
+func (p *WebhookPublisher) Publish(ctx context.Context, event *service.ModelEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("編碼事件失敗: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "fetch-sketchfab")
+	req.Header.Set("X-Sketchfab-Event", event.Type)
+	req.Header.Set("X-Sketchfab-Event-Id", event.ID)
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// MultiPublisher 將事件依序送到多個 publisher，任一失敗時整個事件重試
+//
+// 重試時已成功的 publisher 會再次收到同一事件，因此每個 publisher 都必須容忍重複。
+type MultiPublisher []Publisher
+
+// Name 實作 Publisher
+func (p MultiPublisher) Name() string {
+	names := make([]string, len(p))
+	for i, publisher := range p {
+		names[i] = publisher.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+// Publish 實作 Publisher
+func (p MultiPublisher) Publish(ctx context.Context, event *service.ModelEvent) error {
+	var problems []string
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, event); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", publisher.Name(), err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
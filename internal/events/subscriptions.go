@@ -0,0 +1,256 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fetch-sketchfab-data/internal/metrics"
+	"fetch-sketchfab-data/internal/service"
+)
+
+// subscriptionCacheTTL 訂閱清單的快取時間，新增或修改的訂閱最晚在此時間後生效
+const subscriptionCacheTTL = 30 * time.Second
+
+// SubscriptionPublisher 將事件依訂閱條件加入各訂閱的投遞佇列，實際送出由 DeliveryWorker 負責
+//
+// 同一事件重複發佈時不會重複加入（投遞 ID 為訂閱 ID 與事件 ID）。
+type SubscriptionPublisher struct {
+	subscriptions *service.SubscriptionsService
+
+	mu       sync.Mutex
+	cached   []*service.Subscription
+	cachedAt time.Time
+}
+
+// NewSubscriptionPublisher 建立新的訂閱 publisher
+func NewSubscriptionPublisher(subscriptions *service.SubscriptionsService) *SubscriptionPublisher {
+	return &SubscriptionPublisher{subscriptions: subscriptions}
+}
+
+// Name 實作 Publisher
+func (p *SubscriptionPublisher) Name() string {
+	return "subscriptions"
+}
+
+// Publish 實作 Publisher，將事件加入所有符合條件的訂閱
+func (p *SubscriptionPublisher) Publish(ctx context.Context, event *service.ModelEvent) error {
+	subscriptions, err := p.activeSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	var deliveries []*service.WebhookDelivery
+	var payload []byte
+	for _, subscription := range subscriptions {
+		if !subscription.Wants(event) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return fmt.Errorf("編碼事件失敗: %v", err)
+			}
+		}
+		deliveries = append(deliveries, &service.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			ModelID:        event.ModelID,
+			Payload:        string(payload),
+		})
+	}
+	return p.subscriptions.EnqueueDeliveries(ctx, deliveries)
+}
+
+// activeSubscriptions 取得啟用中的訂閱（快取 subscriptionCacheTTL）
+func (p *SubscriptionPublisher) activeSubscriptions(ctx context.Context) ([]*service.Subscription, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cached != nil && time.Since(p.cachedAt) < subscriptionCacheTTL {
+		return p.cached, nil
+	}
+	subscriptions, err := p.subscriptions.ListSubscriptions(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	p.cached, p.cachedAt = subscriptions, time.Now()
+	return subscriptions, nil
+}
+
+// Sign 計算 X-Sketchfab-Signature 標頭：t=<Unix 時間>,v1=<HMAC-SHA256(secret, "<t>.<body>") 的十六進位>
+//
+// 接收端以相同方式計算並比對 v1，並檢查 t 與目前時間的差距以防止重放。
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeliveryWorker 送出訂閱的投遞，失敗時依退避時間重試，超過最大嘗試次數後轉為 dead letter
+type DeliveryWorker struct {
+	subscriptions *service.SubscriptionsService
+	logService    *service.LogService
+	httpClient    *http.Client
+	cfg           DispatcherConfig
+}
+
+// NewDeliveryWorker 建立新的投遞 worker，timeout 為單次送出逾時
+func NewDeliveryWorker(subscriptions *service.SubscriptionsService, logService *service.LogService, timeout time.Duration, cfg DispatcherConfig) *DeliveryWorker {
+	return &DeliveryWorker{
+		subscriptions: subscriptions,
+		logService:    logService.With("publisher", "subscriptions"),
+		httpClient:    &http.Client{Timeout: timeout},
+		cfg:           cfg,
+	}
+}
+
+// Run 每隔 PollInterval 送出到期的投遞，直到 ctx 結束
+func (w *DeliveryWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.DeliverPending(ctx); err != nil && ctx.Err() == nil {
+			w.logService.Error("❌ 送出 webhook 投遞失敗", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverPending 送出目前所有到期的投遞，回傳成功送達的筆數
+func (w *DeliveryWorker) DeliverPending(ctx context.Context) (int, error) {
+	delivered := 0
+	for ctx.Err() == nil {
+		deliveries, err := w.subscriptions.ClaimDeliveries(ctx, w.cfg.BatchSize, w.cfg.Lease)
+		if err != nil {
+			return delivered, err
+		}
+
+		// 同一批次的訂閱只查詢一次
+		subscriptions := map[string]*service.Subscription{}
+		for _, delivery := range deliveries {
+			subscription, ok := subscriptions[delivery.SubscriptionID]
+			if !ok {
+				subscription, err = w.subscriptions.GetSubscription(ctx, delivery.SubscriptionID)
+				if err != nil && !errors.Is(err, service.ErrSubscriptionNotFound) {
+					return delivered, err
+				}
+				subscriptions[delivery.SubscriptionID] = subscription
+			}
+			if subscription == nil || !subscription.Active {
+				// 取出後訂閱才被刪除或停用，直接轉為 dead letter，之後可由 API 重送
+				reason := "訂閱已刪除"
+				if subscription != nil {
+					reason = "訂閱已停用"
+				}
+				if err := w.abandon(ctx, delivery, reason); err != nil {
+					return delivered, err
+				}
+				continue
+			}
+			ok, err := w.deliver(ctx, subscription, delivery)
+			if err != nil {
+				return delivered, err
+			}
+			if ok {
+				delivered++
+			}
+		}
+		if len(deliveries) < w.cfg.BatchSize {
+			break
+		}
+	}
+	return delivered, nil
+}
+
+// deliver 送出單一投遞並記錄結果，回傳是否送達；只有寫入投遞紀錄失敗時回傳錯誤
+func (w *DeliveryWorker) deliver(ctx context.Context, subscription *service.Subscription, delivery *service.WebhookDelivery) (bool, error) {
+	attempt := &service.DeliveryAttempt{
+		DeliveryID:     delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		Attempt:        delivery.Attempts + 1,
+		AttemptedAt:    time.Now(),
+	}
+	statusCode, err := w.post(ctx, subscription, delivery, attempt.AttemptedAt)
+	attempt.StatusCode = statusCode
+	attempt.DurationMs = time.Since(attempt.AttemptedAt).Milliseconds()
+	deliveryLog := w.logService.With("subscription_id", delivery.SubscriptionID, "delivery_id", delivery.ID, "event_type", delivery.EventType, "uid", delivery.ModelID)
+
+	if err == nil {
+		metrics.WebhookDeliveries.WithLabelValues("delivered").Inc()
+		deliveryLog.Debug("📤 webhook 已送出", "status_code", statusCode)
+		return true, w.subscriptions.RecordAttempt(ctx, delivery, attempt, time.Time{}, false)
+	}
+
+	attempt.Error = err.Error()
+	if attempt.Attempt >= w.cfg.MaxAttempts {
+		metrics.WebhookDeliveries.WithLabelValues("dead").Inc()
+		deliveryLog.Error("❌ webhook 送出失敗，已轉為 dead letter", "attempts", attempt.Attempt, "error", err)
+		return false, w.subscriptions.RecordAttempt(ctx, delivery, attempt, time.Time{}, true)
+	}
+
+	delay := w.cfg.RetryBaseDelay << (attempt.Attempt - 1)
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	metrics.WebhookDeliveries.WithLabelValues("retry").Inc()
+	deliveryLog.Warn("⚠️ webhook 送出失敗，稍後重試", "attempts", attempt.Attempt, "retry_in", delay.String(), "error", err)
+	return false, w.subscriptions.RecordAttempt(ctx, delivery, attempt, time.Now().Add(delay), false)
+}
+
+// abandon 不送出投遞，直接轉為 dead letter
+func (w *DeliveryWorker) abandon(ctx context.Context, delivery *service.WebhookDelivery, reason string) error {
+	metrics.WebhookDeliveries.WithLabelValues("dead").Inc()
+	attempt := &service.DeliveryAttempt{
+		DeliveryID:     delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		Attempt:        delivery.Attempts + 1,
+		AttemptedAt:    time.Now(),
+		Error:          reason,
+	}
+	return w.subscriptions.RecordAttempt(ctx, delivery, attempt, time.Time{}, true)
+}
+
+// post 以簽章的 POST 請求送出投遞內容，回傳 HTTP 狀態碼（連線失敗為 0）
+func (w *DeliveryWorker) post(ctx context.Context, subscription *service.Subscription, delivery *service.WebhookDelivery, now time.Time) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "fetch-sketchfab")
+	req.Header.Set("X-Sketchfab-Event", delivery.EventType)
+	req.Header.Set("X-Sketchfab-Event-Id", delivery.EventID)
+	req.Header.Set("X-Sketchfab-Delivery", delivery.ID)
+	req.Header.Set("X-Sketchfab-Signature", Sign(subscription.Secret, now, body))
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return resp.StatusCode, nil
+}
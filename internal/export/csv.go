@@ -0,0 +1,103 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// archiveFormats CSV 中各檔案封存格式的欄位順序
+var archiveFormats = []string{"glb", "gltf", "gltf-ar", "usdz", "source"}
+
+// csvHeader CSV 欄位，巢狀欄位展開為獨立欄位，多值欄位以 ; 分隔
+var csvHeader = []string{
+	"uid", "name", "description", "uri", "viewer_url",
+	"user_uid", "user_username", "user_display_name",
+	"license_uid", "license_label",
+	"tags", "categories",
+	"created_at", "updated_at", "fetched_at",
+	"view_count", "like_count", "is_downloadable",
+	"thumbnail_url",
+	"glb_size", "gltf_size", "gltf_ar_size", "usdz_size", "source_size",
+	"changed_at", "deleted_at",
+}
+
+// csvWriter 以展開欄位輸出 CSV，第一列為欄位名稱
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+// newCSVWriter 建立 CSV 匯出器
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+// Write 寫入一列模型資料
+func (c *csvWriter) Write(model *service.SketchfabModel) error {
+	sizes := map[string]string{}
+	for _, archive := range model.ArchiveList() {
+		sizes[archive.Format] = strconv.Itoa(archive.Size)
+	}
+
+	row := []string{
+		model.ID, model.Name, model.Description, model.URI, model.ViewerURL(),
+		model.UserString("uid"), model.UserString("username"), model.UserString("displayName"),
+		model.LicenseString("uid"), model.LicenseString("label"),
+		strings.Join(model.TagNames(), ";"), strings.Join(model.CategoryNames(), ";"),
+		formatTime(model.CreatedAt), formatTime(model.UpdatedAt), formatTime(model.FetchedAt),
+		strconv.Itoa(model.ViewCount), strconv.Itoa(model.LikeCount), strconv.FormatBool(model.IsDownloadable),
+		model.ThumbnailURL(1024),
+	}
+	for _, format := range archiveFormats {
+		row = append(row, sizes[format])
+	}
+	row = append(row, formatTime(model.ChangedAt), "")
+	return c.writeRow(row)
+}
+
+// WriteTombstone 寫入一列刪除紀錄，只有 uid、name 與 deleted_at 有值
+func (c *csvWriter) WriteTombstone(tombstone *service.Tombstone) error {
+	row := make([]string, len(csvHeader))
+	row[0] = tombstone.ID
+	row[1] = tombstone.Name
+	row[len(row)-1] = formatTime(tombstone.DeletedAt)
+	return c.writeRow(row)
+}
+
+// writeRow 寫入一列，第一次寫入前先輸出欄位名稱
+func (c *csvWriter) writeRow(row []string) error {
+	if !c.wroteHeader {
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+		c.wroteHeader = true
+	}
+	if err := c.w.Write(row); err != nil {
+		return err
+	}
+	return c.w.Error()
+}
+
+// Close 寫出緩衝中的資料，沒有任何模型時仍輸出欄位名稱
+func (c *csvWriter) Close() error {
+	if !c.wroteHeader {
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// formatTime 以 RFC3339 表示時間，零值輸出空字串
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,36 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// Formats 支援的匯出格式
+var Formats = []string{"csv", "ndjson", "parquet"}
+
+// Writer 逐筆寫入模型的匯出器，Close 時寫出剩餘的緩衝與檔案結尾（不會關閉底層的 io.Writer）
+//
+// 增量匯出時已移除的模型以 WriteTombstone 寫成獨立的刪除紀錄：CSV 與 Parquet 只填 uid、name 與 deleted_at，
+// NDJSON 則輸出 {"id": ..., "deleted": true, ...}。
+type Writer interface {
+	Write(model *service.SketchfabModel) error
+	WriteTombstone(tombstone *service.Tombstone) error
+	Close() error
+}
+
+// NewWriter 依格式建立匯出器
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case "csv":
+		return newCSVWriter(w), nil
+	case "ndjson":
+		return newNDJSONWriter(w), nil
+	case "parquet":
+		return newParquetWriter(w), nil
+	default:
+		return nil, fmt.Errorf("不支援的匯出格式: %q（可用: %s）", format, strings.Join(Formats, "、"))
+	}
+}
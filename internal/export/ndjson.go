@@ -0,0 +1,44 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// ndjsonWriter 每行輸出一個完整的模型文件（包含原始 API 資料）
+type ndjsonWriter struct {
+	buf     *bufio.Writer
+	encoder *json.Encoder
+}
+
+// newNDJSONWriter 建立 NDJSON 匯出器
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	return &ndjsonWriter{buf: buf, encoder: encoder}
+}
+
+// Write 寫入一行模型文件
+func (n *ndjsonWriter) Write(model *service.SketchfabModel) error {
+	return n.encoder.Encode(model)
+}
+
+// ndjsonTombstone NDJSON 的刪除紀錄
+type ndjsonTombstone struct {
+	*service.Tombstone
+	Deleted bool `json:"deleted"`
+}
+
+// WriteTombstone 寫入一行刪除紀錄
+func (n *ndjsonWriter) WriteTombstone(tombstone *service.Tombstone) error {
+	return n.encoder.Encode(ndjsonTombstone{Tombstone: tombstone, Deleted: true})
+}
+
+// Close 寫出緩衝中的資料
+func (n *ndjsonWriter) Close() error {
+	return n.buf.Flush()
+}
@@ -0,0 +1,197 @@
+package export
+
+import (
+	"io"
+	"time"
+
+	"fetch-sketchfab-data/internal/service"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	// parquetBatchSize 每次寫入 Parquet 的列數
+	parquetBatchSize = 1000
+	// parquetRowGroupSize 每個 row group 的列數，寫滿即輸出，避免整份資料留在記憶體中
+	parquetRowGroupSize = 50000
+)
+
+// parquetModel Parquet 的模型結構，標籤、分類與檔案封存為巢狀欄位，未知的時間為 null
+type parquetModel struct {
+	UID            string           `parquet:"uid"`
+	Name           string           `parquet:"name"`
+	Description    string           `parquet:"description"`
+	URI            string           `parquet:"uri"`
+	ViewerURL      string           `parquet:"viewer_url"`
+	User           parquetUser      `parquet:"user"`
+	License        parquetLicense   `parquet:"license"`
+	Tags           []parquetTag     `parquet:"tags,list"`
+	Categories     []string         `parquet:"categories,list"`
+	CreatedAt      *time.Time       `parquet:"created_at,optional"`
+	UpdatedAt      *time.Time       `parquet:"updated_at,optional"`
+	FetchedAt      *time.Time       `parquet:"fetched_at,optional"`
+	ChangedAt      *time.Time       `parquet:"changed_at,optional"`
+	DeletedAt      *time.Time       `parquet:"deleted_at,optional"` // 只有刪除紀錄有值
+	ViewCount      int64            `parquet:"view_count"`
+	LikeCount      int64            `parquet:"like_count"`
+	IsDownloadable bool             `parquet:"is_downloadable"`
+	ThumbnailURL   string           `parquet:"thumbnail_url"`
+	Archives       []parquetArchive `parquet:"archives,list"`
+}
+
+// parquetUser 模型作者
+type parquetUser struct {
+	UID         string `parquet:"uid"`
+	Username    string `parquet:"username"`
+	DisplayName string `parquet:"display_name"`
+	ProfileURL  string `parquet:"profile_url"`
+}
+
+// parquetLicense 模型授權
+type parquetLicense struct {
+	UID   string `parquet:"uid"`
+	Label string `parquet:"label"`
+}
+
+// parquetTag 模型標籤
+type parquetTag struct {
+	Name string `parquet:"name"`
+	Slug string `parquet:"slug"`
+}
+
+// parquetArchive 模型的檔案封存，API 未提供的數值為 null
+type parquetArchive struct {
+	Format               string `parquet:"format"`
+	Type                 string `parquet:"type"`
+	Size                 int64  `parquet:"size"`
+	TextureCount         *int64 `parquet:"texture_count,optional"`
+	TextureMaxResolution *int64 `parquet:"texture_max_resolution,optional"`
+	FaceCount            *int64 `parquet:"face_count,optional"`
+	VertexCount          *int64 `parquet:"vertex_count,optional"`
+}
+
+// parquetWriter 分批寫入 Parquet，每 parquetRowGroupSize 列輸出一個 row group
+type parquetWriter struct {
+	w       *parquet.GenericWriter[parquetModel]
+	batch   []parquetModel
+	inGroup int
+}
+
+// newParquetWriter 建立 Parquet 匯出器
+func newParquetWriter(w io.Writer) *parquetWriter {
+	return &parquetWriter{
+		w:     parquet.NewGenericWriter[parquetModel](w, parquet.Compression(&parquet.Snappy)),
+		batch: make([]parquetModel, 0, parquetBatchSize),
+	}
+}
+
+// Write 加入一列模型資料，批次寫滿時寫入 Parquet
+func (p *parquetWriter) Write(model *service.SketchfabModel) error {
+	return p.add(toParquetModel(model))
+}
+
+// WriteTombstone 加入一列刪除紀錄，只有 uid、name 與 deleted_at 有值
+func (p *parquetWriter) WriteTombstone(tombstone *service.Tombstone) error {
+	return p.add(parquetModel{
+		UID:       tombstone.ID,
+		Name:      tombstone.Name,
+		DeletedAt: optionalTime(tombstone.DeletedAt),
+	})
+}
+
+// add 加入一列，批次寫滿時寫入 Parquet
+func (p *parquetWriter) add(row parquetModel) error {
+	p.batch = append(p.batch, row)
+	if len(p.batch) < parquetBatchSize {
+		return nil
+	}
+	return p.flushBatch()
+}
+
+// Close 寫入剩餘的資料與檔案結尾
+func (p *parquetWriter) Close() error {
+	if err := p.flushBatch(); err != nil {
+		return err
+	}
+	return p.w.Close()
+}
+
+// flushBatch 寫入目前的批次，row group 寫滿時輸出
+func (p *parquetWriter) flushBatch() error {
+	if len(p.batch) == 0 {
+		return nil
+	}
+	if _, err := p.w.Write(p.batch); err != nil {
+		return err
+	}
+	p.inGroup += len(p.batch)
+	p.batch = p.batch[:0]
+
+	if p.inGroup >= parquetRowGroupSize {
+		p.inGroup = 0
+		return p.w.Flush()
+	}
+	return nil
+}
+
+// toParquetModel 將資料庫模型轉為 Parquet 結構
+func toParquetModel(model *service.SketchfabModel) parquetModel {
+	row := parquetModel{
+		UID:         model.ID,
+		Name:        model.Name,
+		Description: model.Description,
+		URI:         model.URI,
+		ViewerURL:   model.ViewerURL(),
+		User: parquetUser{
+			UID:         model.UserString("uid"),
+			Username:    model.UserString("username"),
+			DisplayName: model.UserString("displayName"),
+			ProfileURL:  model.UserString("profileUrl"),
+		},
+		License: parquetLicense{
+			UID:   model.LicenseString("uid"),
+			Label: model.LicenseString("label"),
+		},
+		Categories:     model.CategoryNames(),
+		CreatedAt:      optionalTime(model.CreatedAt),
+		UpdatedAt:      optionalTime(model.UpdatedAt),
+		FetchedAt:      optionalTime(model.FetchedAt),
+		ChangedAt:      optionalTime(model.ChangedAt),
+		ViewCount:      int64(model.ViewCount),
+		LikeCount:      int64(model.LikeCount),
+		IsDownloadable: model.IsDownloadable,
+		ThumbnailURL:   model.ThumbnailURL(1024),
+	}
+	for _, tag := range model.Tags {
+		row.Tags = append(row.Tags, parquetTag{Name: tag["name"], Slug: tag["slug"]})
+	}
+	for _, archive := range model.ArchiveList() {
+		row.Archives = append(row.Archives, parquetArchive{
+			Format:               archive.Format,
+			Type:                 archive.Type,
+			Size:                 int64(archive.Size),
+			TextureCount:         optionalInt(archive.TextureCount),
+			TextureMaxResolution: optionalInt(archive.TextureMaxResolution),
+			FaceCount:            optionalInt(archive.FaceCount),
+			VertexCount:          optionalInt(archive.VertexCount),
+		})
+	}
+	return row
+}
+
+// optionalInt 將可為 nil 的 int 轉為 int64 指標
+func optionalInt(value *int) *int64 {
+	if value == nil {
+		return nil
+	}
+	n := int64(*value)
+	return &n
+}
+
+// optionalTime 零值時間轉為 nil
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,74 @@
+package graphql
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"fetch-sketchfab-data/internal/service"
+
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// Handler GraphQL HTTP 端點
+type Handler struct {
+	schema        *gql.Schema
+	modelsService *service.ModelsService
+}
+
+// request GraphQL 請求內容
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewHandler 建立新的 GraphQL 端點
+func NewHandler(modelsService *service.ModelsService) (*Handler, error) {
+	schema, err := gql.ParseSchema(schemaSDL, &Resolver{modelsService: modelsService}, gql.MaxDepth(8))
+	if err != nil {
+		return nil, fmt.Errorf("解析 GraphQL schema 失敗: %v", err)
+	}
+
+	return &Handler{
+		schema:        schema,
+		modelsService: modelsService,
+	}, nil
+}
+
+// ServeHTTP 處理 GraphQL 查詢，支援 POST JSON 與 GET ?query=
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				http.Error(w, "無法解析 variables", http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, "無法解析 GraphQL 請求", http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "不支援的方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 每個請求使用獨立的批次載入器
+	ctx := withLoaders(r.Context(), h.modelsService)
+	response := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,93 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// batchLoader 請求範圍內的批次載入器
+//
+// 父層解析器在建立子解析器時先以 Register 登記鍵值，子解析器第一次 Load 時
+// 會以單一查詢取回所有已登記的鍵值，避免 N+1 查詢。
+type batchLoader[K comparable, V any] struct {
+	mu      sync.Mutex
+	pending map[K]struct{}
+	cache   map[K]V
+	fetch   func(keys []K) (map[K]V, error)
+}
+
+// newBatchLoader 建立新的批次載入器
+func newBatchLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{
+		pending: make(map[K]struct{}),
+		cache:   make(map[K]V),
+		fetch:   fetch,
+	}
+}
+
+// Register 登記稍後會載入的鍵值
+func (l *batchLoader[K, V]) Register(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.cache[key]; !ok {
+		l.pending[key] = struct{}{}
+	}
+}
+
+// Load 載入鍵值，尚未快取時會連同所有已登記的鍵值一起查詢
+func (l *batchLoader[K, V]) Load(key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if value, ok := l.cache[key]; ok {
+		return value, nil
+	}
+
+	l.pending[key] = struct{}{}
+	keys := make([]K, 0, len(l.pending))
+	for k := range l.pending {
+		keys = append(keys, k)
+	}
+	l.pending = make(map[K]struct{})
+
+	values, err := l.fetch(keys)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	// 查無資料的鍵值也要快取零值，避免重複查詢
+	for _, k := range keys {
+		l.cache[k] = values[k]
+	}
+	return l.cache[key], nil
+}
+
+// maxUserModels 每位作者最多載入的模型數
+const maxUserModels = 100
+
+// loaders 單一請求使用的所有載入器
+type loaders struct {
+	users      *batchLoader[string, *service.UserStats]
+	userModels *batchLoader[string, []*service.SketchfabModel]
+	history    *batchLoader[string, []*service.ModelSnapshot]
+}
+
+type loadersKey struct{}
+
+// withLoaders 為請求建立新的載入器
+func withLoaders(ctx context.Context, modelsService *service.ModelsService) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{
+		users: newBatchLoader(modelsService.GetUserStats),
+		userModels: newBatchLoader(func(keys []string) (map[string][]*service.SketchfabModel, error) {
+			return modelsService.GetModelsByUsers(keys, maxUserModels)
+		}),
+		history: newBatchLoader(modelsService.GetModelHistories),
+	})
+}
+
+// loadersFrom 取得請求的載入器
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,166 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"fetch-sketchfab-data/internal/service"
+
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+// Resolver GraphQL 根解析器
+type Resolver struct {
+	modelsService *service.ModelsService
+}
+
+// modelFilterInput 對應 schema 中的 ModelFilter
+type modelFilterInput struct {
+	Tag           *string
+	Category      *string
+	License       *string
+	User          *string
+	Downloadable  *bool
+	CreatedAfter  *gql.Time
+	CreatedBefore *gql.Time
+	MinViews      *int32
+	MaxViews      *int32
+	MinLikes      *int32
+	MaxLikes      *int32
+}
+
+// toFilter 轉換為服務層的查詢條件
+func (in *modelFilterInput) toFilter() *service.ModelFilter {
+	filter := &service.ModelFilter{WithRawData: true}
+	if in == nil {
+		return filter
+	}
+	filter.Tag = deref(in.Tag)
+	filter.Category = deref(in.Category)
+	filter.License = deref(in.License)
+	filter.User = deref(in.User)
+	filter.Downloadable = in.Downloadable
+	if in.CreatedAfter != nil {
+		filter.CreatedAfter = &in.CreatedAfter.Time
+	}
+	if in.CreatedBefore != nil {
+		filter.CreatedBefore = &in.CreatedBefore.Time
+	}
+	filter.MinViews = toIntPtr(in.MinViews)
+	filter.MaxViews = toIntPtr(in.MaxViews)
+	filter.MinLikes = toIntPtr(in.MinLikes)
+	filter.MaxLikes = toIntPtr(in.MaxLikes)
+	return filter
+}
+
+// Models 依條件查詢模型
+func (r *Resolver) Models(ctx context.Context, args struct {
+	Filter *modelFilterInput
+	Sort   *string
+	First  *int32
+	After  *string
+}) (*modelConnectionResolver, error) {
+	filter := args.Filter.toFilter()
+	filter.Sort = deref(args.Sort)
+	filter.Cursor = deref(args.After)
+	if args.First != nil {
+		filter.Limit = int(*args.First)
+	}
+
+	page, err := r.modelsService.QueryModels(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &modelConnectionResolver{
+		nodes:      newModelResolvers(ctx, page.Models),
+		nextCursor: page.NextCursor,
+	}, nil
+}
+
+// Model 取得單一模型
+func (r *Resolver) Model(ctx context.Context, args struct{ UID gql.ID }) (*modelResolver, error) {
+	model, err := r.modelsService.GetModelByID(string(args.UID))
+	if err != nil {
+		if errors.Is(err, service.ErrModelNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return newModelResolvers(ctx, []*service.SketchfabModel{model})[0], nil
+}
+
+// Users 依條件列出作者
+func (r *Resolver) Users(ctx context.Context, args struct {
+	MinTotalLikes *int32
+	MinModels     *int32
+	First         *int32
+}) ([]*userResolver, error) {
+	filter := &service.UserFilter{
+		MinTotalLikes: toIntPtr(args.MinTotalLikes),
+		MinModels:     toIntPtr(args.MinModels),
+	}
+	if args.First != nil {
+		filter.Limit = int(*args.First)
+	}
+
+	users, err := r.modelsService.QueryUsers(filter)
+	if err != nil {
+		return nil, err
+	}
+	return newUserResolvers(ctx, users), nil
+}
+
+// User 取得單一作者
+func (r *Resolver) User(ctx context.Context, args struct{ UID gql.ID }) (*userResolver, error) {
+	stats, err := loadersFrom(ctx).users.Load(string(args.UID))
+	if err != nil || stats == nil {
+		return nil, err
+	}
+	return newUserResolvers(ctx, []*service.UserStats{stats})[0], nil
+}
+
+// Tags 標籤計數
+func (r *Resolver) Tags(args struct{ First *int32 }) ([]*countResolver, error) {
+	buckets, err := r.modelsService.CountTags(int(deref(args.First)))
+	if err != nil {
+		return nil, err
+	}
+	return newCountResolvers(buckets), nil
+}
+
+// Categories 分類計數
+func (r *Resolver) Categories(args struct{ First *int32 }) ([]*countResolver, error) {
+	buckets, err := r.modelsService.CountCategories(int(deref(args.First)))
+	if err != nil {
+		return nil, err
+	}
+	return newCountResolvers(buckets), nil
+}
+
+// Licenses 授權計數
+func (r *Resolver) Licenses() ([]*countResolver, error) {
+	buckets, err := r.modelsService.CountLicenses()
+	if err != nil {
+		return nil, err
+	}
+	return newCountResolvers(buckets), nil
+}
+
+// deref 取得指標的值，nil 時回傳零值
+func deref[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
+
+// toIntPtr 將 *int32 轉換為 *int
+func toIntPtr(p *int32) *int {
+	if p == nil {
+		return nil
+	}
+	n := int(*p)
+	return &n
+}
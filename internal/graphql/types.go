@@ -0,0 +1,228 @@
+package graphql
+
+import (
+	"context"
+
+	"fetch-sketchfab-data/internal/service"
+
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+// modelConnectionResolver 模型分頁結果
+type modelConnectionResolver struct {
+	nodes      []*modelResolver
+	nextCursor string
+}
+
+func (r *modelConnectionResolver) Nodes() []*modelResolver { return r.nodes }
+
+func (r *modelConnectionResolver) NextCursor() *string {
+	if r.nextCursor == "" {
+		return nil
+	}
+	return &r.nextCursor
+}
+
+// modelResolver 模型解析器
+type modelResolver struct {
+	m *service.SketchfabModel
+}
+
+// newModelResolvers 建立模型解析器，並登記作者與歷史的批次載入鍵值
+func newModelResolvers(ctx context.Context, models []*service.SketchfabModel) []*modelResolver {
+	l := loadersFrom(ctx)
+	resolvers := make([]*modelResolver, len(models))
+	for i, model := range models {
+		l.users.Register(model.UserString("uid"))
+		l.history.Register(model.ID)
+		resolvers[i] = &modelResolver{m: model}
+	}
+	return resolvers
+}
+
+func (r *modelResolver) UID() gql.ID          { return gql.ID(r.m.ID) }
+func (r *modelResolver) Name() string         { return r.m.Name }
+func (r *modelResolver) Description() string  { return r.m.Description }
+func (r *modelResolver) URI() string          { return r.m.URI }
+func (r *modelResolver) ViewerURL() string    { return r.m.ViewerURL() }
+func (r *modelResolver) CreatedAt() gql.Time  { return gql.Time{Time: r.m.CreatedAt} }
+func (r *modelResolver) UpdatedAt() gql.Time  { return gql.Time{Time: r.m.UpdatedAt} }
+func (r *modelResolver) FetchedAt() gql.Time  { return gql.Time{Time: r.m.FetchedAt} }
+func (r *modelResolver) ViewCount() int32     { return int32(r.m.ViewCount) }
+func (r *modelResolver) LikeCount() int32     { return int32(r.m.LikeCount) }
+func (r *modelResolver) IsDownloadable() bool { return r.m.IsDownloadable }
+
+func (r *modelResolver) ThumbnailURL(args struct{ MaxWidth int32 }) *string {
+	url := r.m.ThumbnailURL(int(args.MaxWidth))
+	if url == "" {
+		return nil
+	}
+	return &url
+}
+
+func (r *modelResolver) Tags() []*tagResolver {
+	tags := make([]*tagResolver, len(r.m.Tags))
+	for i, tag := range r.m.Tags {
+		tags[i] = &tagResolver{name: tag["name"], slug: tag["slug"]}
+	}
+	return tags
+}
+
+func (r *modelResolver) Categories() []string {
+	categories := make([]string, len(r.m.Categories))
+	for i, category := range r.m.Categories {
+		categories[i] = category["name"]
+	}
+	return categories
+}
+
+func (r *modelResolver) License() *licenseResolver {
+	if r.m.License == nil {
+		return nil
+	}
+	return &licenseResolver{uid: r.m.LicenseString("uid"), label: r.m.LicenseString("label")}
+}
+
+func (r *modelResolver) User(ctx context.Context) (*userResolver, error) {
+	stats, err := loadersFrom(ctx).users.Load(r.m.UserString("uid"))
+	if err != nil || stats == nil {
+		return nil, err
+	}
+	return newUserResolvers(ctx, []*service.UserStats{stats})[0], nil
+}
+
+func (r *modelResolver) Archives() []*archiveResolver {
+	archives := r.m.ArchiveList()
+	resolvers := make([]*archiveResolver, len(archives))
+	for i := range archives {
+		resolvers[i] = &archiveResolver{a: archives[i]}
+	}
+	return resolvers
+}
+
+func (r *modelResolver) TotalArchiveSize() float64 {
+	var total float64
+	for _, archive := range r.m.ArchiveList() {
+		total += float64(archive.Size)
+	}
+	return total
+}
+
+func (r *modelResolver) History(ctx context.Context) ([]*snapshotResolver, error) {
+	snapshots, err := loadersFrom(ctx).history.Load(r.m.ID)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*snapshotResolver, len(snapshots))
+	for i, snapshot := range snapshots {
+		resolvers[i] = &snapshotResolver{s: snapshot}
+	}
+	return resolvers, nil
+}
+
+// tagResolver 標籤解析器
+type tagResolver struct {
+	name string
+	slug string
+}
+
+func (r *tagResolver) Name() string { return r.name }
+func (r *tagResolver) Slug() string { return r.slug }
+
+// licenseResolver 授權解析器
+type licenseResolver struct {
+	uid   string
+	label string
+}
+
+func (r *licenseResolver) UID() string   { return r.uid }
+func (r *licenseResolver) Label() string { return r.label }
+
+// userResolver 作者解析器
+type userResolver struct {
+	u *service.UserStats
+}
+
+// newUserResolvers 建立作者解析器，並登記作者模型的批次載入鍵值
+func newUserResolvers(ctx context.Context, users []*service.UserStats) []*userResolver {
+	l := loadersFrom(ctx)
+	resolvers := make([]*userResolver, len(users))
+	for i, user := range users {
+		l.userModels.Register(user.UID)
+		resolvers[i] = &userResolver{u: user}
+	}
+	return resolvers
+}
+
+func (r *userResolver) UID() gql.ID         { return gql.ID(r.u.UID) }
+func (r *userResolver) Username() string    { return r.u.Username }
+func (r *userResolver) DisplayName() string { return r.u.DisplayName }
+func (r *userResolver) ModelCount() int32   { return int32(r.u.ModelCount) }
+func (r *userResolver) TotalViews() float64 { return float64(r.u.TotalViews) }
+func (r *userResolver) TotalLikes() int32   { return int32(r.u.TotalLikes) }
+
+func (r *userResolver) ProfileURL() *string {
+	if r.u.ProfileURL == "" {
+		return nil
+	}
+	return &r.u.ProfileURL
+}
+
+func (r *userResolver) Models(ctx context.Context, args struct{ First int32 }) ([]*modelResolver, error) {
+	models, err := loadersFrom(ctx).userModels.Load(r.u.UID)
+	if err != nil {
+		return nil, err
+	}
+	if int(args.First) < len(models) {
+		models = models[:args.First]
+	}
+	return newModelResolvers(ctx, models), nil
+}
+
+// archiveResolver 檔案封存解析器
+type archiveResolver struct {
+	a service.NamedArchive
+}
+
+func (r *archiveResolver) Format() string               { return r.a.Format }
+func (r *archiveResolver) Type() string                 { return r.a.Type }
+func (r *archiveResolver) Size() float64                { return float64(r.a.Size) }
+func (r *archiveResolver) TextureCount() *int32         { return toInt32Ptr(r.a.TextureCount) }
+func (r *archiveResolver) TextureMaxResolution() *int32 { return toInt32Ptr(r.a.TextureMaxResolution) }
+func (r *archiveResolver) FaceCount() *int32            { return toInt32Ptr(r.a.FaceCount) }
+func (r *archiveResolver) VertexCount() *int32          { return toInt32Ptr(r.a.VertexCount) }
+
+// snapshotResolver 歷史快照解析器
+type snapshotResolver struct {
+	s *service.ModelSnapshot
+}
+
+func (r *snapshotResolver) FetchedAt() gql.Time { return gql.Time{Time: r.s.FetchedAt} }
+func (r *snapshotResolver) ViewCount() int32    { return int32(r.s.ViewCount) }
+func (r *snapshotResolver) LikeCount() int32    { return int32(r.s.LikeCount) }
+
+// countResolver 分組計數解析器，同時用於標籤、分類與授權
+type countResolver struct {
+	b service.CountBucket
+}
+
+func newCountResolvers(buckets []service.CountBucket) []*countResolver {
+	resolvers := make([]*countResolver, len(buckets))
+	for i, bucket := range buckets {
+		resolvers[i] = &countResolver{b: bucket}
+	}
+	return resolvers
+}
+
+func (r *countResolver) Name() string  { return r.b.Key }
+func (r *countResolver) Label() string { return r.b.Key }
+func (r *countResolver) Count() int32  { return int32(r.b.Count) }
+
+// toInt32Ptr 將 *int 轉換為 *int32
+func toInt32Ptr(p *int) *int32 {
+	if p == nil {
+		return nil
+	}
+	n := int32(*p)
+	return &n
+}
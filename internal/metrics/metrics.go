@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "sketchfab_fetcher"
+
+var (
+	// APIRequests Sketchfab API 請求次數（依狀態碼）
+	APIRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_requests_total",
+		Help:      "Sketchfab API 請求次數",
+	}, []string{"status"})
+
+	// APIRequestDuration Sketchfab API 請求耗時（依狀態碼）
+	APIRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "api_request_duration_seconds",
+		Help:      "Sketchfab API 請求耗時（秒）",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"status"})
+
+	// APIRetries Sketchfab API 重試次數
+	APIRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_retries_total",
+		Help:      "Sketchfab API 請求重試次數",
+	})
+
+	// RateLimiterWait 請求前在速率限制器等待的時間
+	RateLimiterWait = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rate_limiter_wait_seconds",
+		Help:      "請求前在速率限制器等待的時間（秒）",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 10),
+	})
+
+	// PagesFetched 成功取得的 API 分頁數
+	PagesFetched = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pages_fetched_total",
+		Help:      "成功取得的模型列表分頁數",
+	})
+
+	// ModelsUpserted 模型 upsert 結果（inserted / updated / unchanged）
+	ModelsUpserted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "models_upserted_total",
+		Help:      "模型 upsert 結果計數",
+	}, []string{"outcome"})
+
+	// MongoOperationDuration MongoDB 操作耗時
+	MongoOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_operation_duration_seconds",
+		Help:      "MongoDB 操作耗時（秒）",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"operation", "status"})
+
+	// LastSuccessfulSync 最後一次同步成功的時間
+	LastSuccessfulSync = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_successful_sync_timestamp_seconds",
+		Help:      "最後一次同步成功的 Unix 時間",
+	}, []string{"job"})
+
+	// LogsDropped 因日誌佇列已滿而丟棄的日誌筆數
+	LogsDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logs_dropped_total",
+		Help:      "因日誌佇列已滿而丟棄的日誌筆數",
+	})
+
+	// LogSpoolBytes 本機日誌暫存檔中尚未送出的位元組數
+	LogSpoolBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "log_spool_bytes",
+		Help:      "本機日誌暫存檔中尚未送出的位元組數",
+	})
+
+	// EventsPublished 模型事件送出結果（delivered / retry / failed）
+	EventsPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_published_total",
+		Help:      "模型事件送出結果計數",
+	}, []string{"outcome"})
+
+	// WebhookDeliveries 訂閱 webhook 投遞結果（delivered / retry / dead）
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "訂閱 webhook 投遞結果計數",
+	}, []string{"outcome"})
+
+	// ArchiveDownloads 檔案封存下載結果（依格式，completed / skipped / failed）
+	ArchiveDownloads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "archive_downloads_total",
+		Help:      "檔案封存下載結果計數",
+	}, []string{"format", "outcome"})
+
+	// ArchiveDownloadBytes 檔案封存下載寫入的位元組數（依格式）
+	ArchiveDownloadBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "archive_download_bytes_total",
+		Help:      "檔案封存下載寫入的位元組數",
+	}, []string{"format"})
+
+	// SchedulerNextRun 排程器下次執行的時間
+	SchedulerNextRun = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "scheduler_next_run_timestamp_seconds",
+		Help:      "排程任務下次執行的 Unix 時間",
+	}, []string{"job"})
+)
+
+// Handler 回傳 /metrics 端點
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveAPIRequest 記錄一次 API 請求，statusCode 為 0 表示連線錯誤
+func ObserveAPIRequest(statusCode int, duration time.Duration) {
+	status := "error"
+	if statusCode > 0 {
+		status = strconv.Itoa(statusCode)
+	}
+	APIRequests.WithLabelValues(status).Inc()
+	APIRequestDuration.WithLabelValues(status).Observe(duration.Seconds())
+}
+
+// ObserveMongo 記錄一次 MongoDB 操作的耗時
+func ObserveMongo(operation string, start time.Time, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	MongoOperationDuration.WithLabelValues(operation, status).Observe(time.Since(start).Seconds())
+}
@@ -123,3 +123,33 @@ type GetModelsParams struct {
 	Tags             *string `json:"tags,omitempty"`
 	Search           *string `json:"search,omitempty"`
 }
+
+// DownloadLink 代表下載 API 回傳的單一格式下載連結（預先簽署的網址，約數分鐘後失效）
+type DownloadLink struct {
+	URL     string `json:"url"`
+	Size    int    `json:"size"`
+	Expires int    `json:"expires"` // 網址的有效秒數
+}
+
+// DownloadLinks 代表下載 API 的回應，沒有提供的格式為 nil
+type DownloadLinks struct {
+	GLB    *DownloadLink `json:"glb,omitempty"`
+	GLTF   *DownloadLink `json:"gltf,omitempty"`
+	USDZ   *DownloadLink `json:"usdz,omitempty"`
+	Source *DownloadLink `json:"source,omitempty"`
+}
+
+// Link 依格式名稱（glb、gltf、usdz、source）取得下載連結
+func (d *DownloadLinks) Link(format string) *DownloadLink {
+	switch format {
+	case "glb":
+		return d.GLB
+	case "gltf":
+		return d.GLTF
+	case "usdz":
+		return d.USDZ
+	case "source":
+		return d.Source
+	}
+	return nil
+}
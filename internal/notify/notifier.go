@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Message 要送出的通知，Text 與 HTML 為同一內容的兩種格式
+type Message struct {
+	Kind       string      `json:"kind"` // 通知類型，例如 saved_search
+	Subject    string      `json:"subject"`
+	Text       string      `json:"text"`
+	HTML       string      `json:"html,omitempty"`
+	Recipients []string    `json:"recipients,omitempty"` // email 收件人，空值時使用 notifier 的預設收件人
+	Data       interface{} `json:"data,omitempty"`       // 結構化內容，供 webhook 使用
+}
+
+// Notifier 送出通知（email、webhook 等）
+type Notifier interface {
+	// Name notifier 名稱，用於日誌
+	Name() string
+	// Send 送出一則通知
+	Send(ctx context.Context, message *Message) error
+}
+
+// Multi 將通知送到多個 notifier，回傳所有失敗的錯誤
+type Multi []Notifier
+
+// Name 實作 Notifier
+func (m Multi) Name() string {
+	names := make([]string, len(m))
+	for i, notifier := range m {
+		names[i] = notifier.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+// Send 實作 Notifier，其中一個失敗時仍會送到其他 notifier
+func (m Multi) Send(ctx context.Context, message *Message) error {
+	var problems []string
+	for _, notifier := range m {
+		if err := notifier.Send(ctx, message); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", notifier.Name(), err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
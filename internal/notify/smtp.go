@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig SMTP 寄件設定
+type SMTPConfig struct {
+	Host     string
+	Port     int // 465 使用隱含 TLS，其他連接埠在伺服器支援時使用 STARTTLS
+	Username string
+	Password string
+	From     string   // 寄件人，可包含顯示名稱，例如 "Sketchfab <bot@example.com>"
+	To       []string // 通知未指定收件人時的預設收件人
+	Timeout  time.Duration
+}
+
+// SMTPNotifier 以 email 寄送通知，同時包含純文字與 HTML 內容
+type SMTPNotifier struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPNotifier 建立新的 SMTP notifier
+func NewSMTPNotifier(cfg SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{cfg: cfg}
+}
+
+// Name 實作 Notifier
+func (n *SMTPNotifier) Name() string {
+	return "smtp"
+}
+
+// Send 實作 Notifier，沒有任何收件人時略過
+func (n *SMTPNotifier) Send(ctx context.Context, message *Message) error {
+	recipients := message.Recipients
+	if len(recipients) == 0 {
+		recipients = n.cfg.To
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	body, err := n.buildMessage(message, recipients)
+	if err != nil {
+		return fmt.Errorf("建立郵件失敗: %v", err)
+	}
+
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	dialer := &net.Dialer{Timeout: n.cfg.Timeout}
+	var conn net.Conn
+	if n.cfg.Port == 465 {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: n.cfg.Host})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("連線 SMTP 伺服器失敗: %v", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else if n.cfg.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(n.cfg.Timeout))
+	}
+
+	client, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("連線 SMTP 伺服器失敗: %v", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && n.cfg.Port != 465 {
+		if err := client.StartTLS(&tls.Config{ServerName: n.cfg.Host}); err != nil {
+			return fmt.Errorf("SMTP STARTTLS 失敗: %v", err)
+		}
+	}
+	if n.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP 驗證失敗: %v", err)
+		}
+	}
+	from, err := mail.ParseAddress(n.cfg.From)
+	if err != nil {
+		return fmt.Errorf("無效的寄件人 %q: %v", n.cfg.From, err)
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("SMTP 寄件人被拒絕: %v", err)
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("SMTP 收件人 %s 被拒絕: %v", recipient, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("寄送郵件失敗: %v", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("寄送郵件失敗: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("寄送郵件失敗: %v", err)
+	}
+	return client.Quit()
+}
+
+// buildMessage 建立 multipart/alternative 郵件內容
+func (n *SMTPNotifier) buildMessage(message *Message, recipients []string) ([]byte, error) {
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+
+	bodies := []struct{ contentType, content string }{{"text/plain", message.Text}}
+	if message.HTML != "" {
+		bodies = append(bodies, struct{ contentType, content string }{"text/html", message.HTML})
+	}
+	for _, body := range bodies {
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {body.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(body.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
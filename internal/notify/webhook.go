@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WebhookNotifier 以 HTTP POST 將通知 JSON 送到指定網址，2xx 視為成功
+type WebhookNotifier struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewWebhookNotifier 建立新的 webhook notifier
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:        url,
+		HTTPClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name 實作 Notifier
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Send 實作 Notifier，通知類型也放在 X-Sketchfab-Notification 標頭
+func (n *WebhookNotifier) Send(ctx context.Context, message *Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("編碼通知失敗: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "fetch-sketchfab")
+	req.Header.Set("X-Sketchfab-Notification", message.Kind)
+
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package report
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed report.md.tmpl
+var markdownTemplateSource string
+
+//go:embed report.html.tmpl
+var htmlTemplateSource string
+
+// markdownEscaper 跳脫會破壞 Markdown 表格與連結的字元
+var markdownEscaper = strings.NewReplacer(
+	"\\", "\\\\", "|", "\\|", "[", "\\[", "]", "\\]", "*", "\\*", "_", "\\_", "`", "\\`", "<", "&lt;", ">", "&gt;",
+	"\r\n", " ", "\n", " ",
+)
+
+// templateFuncs 報告範本使用的函式
+var templateFuncs = map[string]interface{}{
+	"sub":  func(a, b int) int { return a - b },
+	"join": strings.Join,
+	"md":   markdownEscaper.Replace,
+}
+
+var (
+	markdownTemplate = texttemplate.Must(texttemplate.New("report.md").Funcs(templateFuncs).Parse(markdownTemplateSource))
+	htmlTemplate     = htmltemplate.Must(htmltemplate.New("report.html").Funcs(templateFuncs).Parse(htmlTemplateSource))
+)
+
+// Markdown 產生 Markdown 格式的報告
+func (r *Report) Markdown() (string, error) {
+	var buf bytes.Buffer
+	if err := markdownTemplate.Execute(&buf, r); err != nil {
+		return "", fmt.Errorf("產生 Markdown 報告失敗: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// HTML 產生 HTML 格式的報告
+func (r *Report) HTML() (string, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, r); err != nil {
+		return "", fmt.Errorf("產生 HTML 報告失敗: %v", err)
+	}
+	return buf.String(), nil
+}
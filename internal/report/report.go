@@ -0,0 +1,283 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"fetch-sketchfab-data/internal/scheduler"
+	"fetch-sketchfab-data/internal/service"
+)
+
+const (
+	// maxNewModels 報告列出的新模型上限
+	maxNewModels = 50
+	// maxMovers 瀏覽數與喜歡數成長榜的筆數
+	maxMovers = 10
+)
+
+// ModelSummary 報告與通知中的模型摘要
+type ModelSummary struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	ViewerURL    string   `json:"viewer_url"`
+	ThumbnailURL string   `json:"thumbnail_url,omitempty"`
+	License      string   `json:"license"`
+	Author       string   `json:"author"`
+	Likes        int      `json:"like_count"`
+	Views        int      `json:"view_count"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+// NewModelSummary 由儲存的模型建立摘要
+func NewModelSummary(model *service.SketchfabModel) ModelSummary {
+	return ModelSummary{
+		ID:           model.ID,
+		Name:         model.Name,
+		ViewerURL:    model.ViewerURL(),
+		ThumbnailURL: model.ThumbnailURL(320),
+		License:      model.LicenseString("label"),
+		Author:       authorName(model),
+		Likes:        model.LikeCount,
+		Views:        model.ViewCount,
+		Tags:         model.TagNames(),
+	}
+}
+
+// authorName 取得作者顯示名稱，沒有時使用帳號
+func authorName(model *service.SketchfabModel) string {
+	if name := model.UserString("displayName"); name != "" {
+		return name
+	}
+	return model.UserString("username")
+}
+
+// Mover 本次執行中瀏覽數或喜歡數成長的模型
+type Mover struct {
+	ModelSummary
+	ViewDelta int `json:"view_delta"`
+	LikeDelta int `json:"like_delta"`
+}
+
+// Creator 本次執行中第一次出現的作者
+type Creator struct {
+	UID        string `json:"uid"`
+	Name       string `json:"name"`
+	ProfileURL string `json:"profile_url,omitempty"`
+	Models     int    `json:"models"`
+}
+
+// Health 本次執行的同步狀況
+type Health struct {
+	Status     string        `json:"status"` // done / failed
+	Trigger    string        `json:"trigger"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Duration   time.Duration `json:"duration"`
+	Pages      int           `json:"pages"`
+	Fetched    int           `json:"fetched"`
+	Inserted   int64         `json:"inserted"`
+	Updated    int64         `json:"updated"`
+	Unchanged  int64         `json:"unchanged"`
+	Error      string        `json:"error,omitempty"`
+	SinkErrors []string      `json:"sink_errors,omitempty"`
+}
+
+// Report 一次同步執行的摘要報告
+type Report struct {
+	RunID         string                `json:"run_id"`
+	JobName       string                `json:"job_name"`
+	GeneratedAt   time.Time             `json:"generated_at"`
+	Health        Health                `json:"health"`
+	NewModels     []ModelSummary        `json:"new_models"` // 依喜歡數排序，最多 maxNewModels 筆
+	NewModelCount int                   `json:"new_model_count"`
+	TopViewGains  []Mover               `json:"top_view_gains"`
+	TopLikeGains  []Mover               `json:"top_like_gains"`
+	NewCreators   []Creator             `json:"new_creators"`
+	Licenses      []service.CountBucket `json:"licenses"` // 新模型的授權分布
+}
+
+// Build 以任務結果、合計的 upsert 結果與歷史快照建立報告
+//
+// 成長量為模型目前的數值減去任務開始前最後一筆快照，沒有更早快照的模型不列入。
+func Build(ctx context.Context, modelsService *service.ModelsService, run *scheduler.RunResult, upsert *service.UpsertResult) (*Report, error) {
+	if upsert == nil {
+		upsert = &service.UpsertResult{}
+	}
+	report := &Report{
+		RunID:       run.RunID,
+		JobName:     run.JobName,
+		GeneratedAt: time.Now(),
+		Health: Health{
+			Status:     run.Stage,
+			Trigger:    run.Trigger,
+			StartedAt:  run.StartedAt,
+			FinishedAt: run.FinishedAt,
+			Duration:   run.FinishedAt.Sub(run.StartedAt).Round(time.Second),
+			Pages:      run.Page,
+			Fetched:    run.FetchedCount,
+			Inserted:   upsert.InsertedCount,
+			Updated:    upsert.UpdatedCount,
+			Unchanged:  upsert.UnchangedCount,
+			Error:      run.Error,
+			SinkErrors: upsert.SinkErrors,
+		},
+		NewModelCount: len(upsert.InsertedIDs),
+	}
+
+	if err := report.addNewModels(modelsService, upsert.InsertedIDs); err != nil {
+		return nil, err
+	}
+	if err := report.addMovers(ctx, modelsService, upsert.UpdatedIDs, run.StartedAt); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// addNewModels 加入新模型、新作者與授權分布
+func (r *Report) addNewModels(modelsService *service.ModelsService, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	byID, err := modelsService.GetModelsByIDs(ids)
+	if err != nil {
+		return err
+	}
+	models := make([]*service.SketchfabModel, 0, len(byID))
+	for _, model := range byID {
+		models = append(models, model)
+	}
+	sort.Slice(models, func(i, j int) bool {
+		if models[i].LikeCount != models[j].LikeCount {
+			return models[i].LikeCount > models[j].LikeCount
+		}
+		return models[i].ID < models[j].ID
+	})
+
+	licenses := map[string]int64{}
+	newByUser := map[string]int{}
+	var userIDs []string
+	firstModel := map[string]*service.SketchfabModel{}
+	for i, model := range models {
+		if i < maxNewModels {
+			r.NewModels = append(r.NewModels, NewModelSummary(model))
+		}
+		license := model.LicenseString("label")
+		if license == "" {
+			license = "（未知）"
+		}
+		licenses[license]++
+		if uid := model.UserString("uid"); uid != "" {
+			if newByUser[uid] == 0 {
+				userIDs = append(userIDs, uid)
+				firstModel[uid] = model
+			}
+			newByUser[uid]++
+		}
+	}
+	for label, count := range licenses {
+		r.Licenses = append(r.Licenses, service.CountBucket{Key: label, Count: count})
+	}
+	sort.Slice(r.Licenses, func(i, j int) bool {
+		if r.Licenses[i].Count != r.Licenses[j].Count {
+			return r.Licenses[i].Count > r.Licenses[j].Count
+		}
+		return r.Licenses[i].Key < r.Licenses[j].Key
+	})
+
+	// 資料庫中的模型全部是本次新增的作者視為新作者
+	if len(userIDs) == 0 {
+		return nil
+	}
+	stats, err := modelsService.GetUserStats(userIDs)
+	if err != nil {
+		return err
+	}
+	for _, uid := range userIDs {
+		if stat, ok := stats[uid]; ok && stat.ModelCount > int64(newByUser[uid]) {
+			continue
+		}
+		model := firstModel[uid]
+		r.NewCreators = append(r.NewCreators, Creator{
+			UID:        uid,
+			Name:       authorName(model),
+			ProfileURL: model.UserString("profileUrl"),
+			Models:     newByUser[uid],
+		})
+	}
+	sort.Slice(r.NewCreators, func(i, j int) bool {
+		if r.NewCreators[i].Models != r.NewCreators[j].Models {
+			return r.NewCreators[i].Models > r.NewCreators[j].Models
+		}
+		return r.NewCreators[i].Name < r.NewCreators[j].Name
+	})
+	return nil
+}
+
+// addMovers 以歷史快照計算更新模型的成長量，加入瀏覽數與喜歡數成長榜
+func (r *Report) addMovers(ctx context.Context, modelsService *service.ModelsService, ids []string, since time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	baselines, err := modelsService.GetSnapshotsBefore(ctx, ids, since)
+	if err != nil {
+		return err
+	}
+	if len(baselines) == 0 {
+		return nil
+	}
+	withBaseline := make([]string, 0, len(baselines))
+	for id := range baselines {
+		withBaseline = append(withBaseline, id)
+	}
+	byID, err := modelsService.GetModelsByIDs(withBaseline)
+	if err != nil {
+		return err
+	}
+
+	var movers []Mover
+	for id, model := range byID {
+		baseline := baselines[id]
+		mover := Mover{
+			ModelSummary: NewModelSummary(model),
+			ViewDelta:    model.ViewCount - baseline.ViewCount,
+			LikeDelta:    model.LikeCount - baseline.LikeCount,
+		}
+		if mover.ViewDelta != 0 || mover.LikeDelta != 0 {
+			movers = append(movers, mover)
+		}
+	}
+	r.TopViewGains = topMovers(movers, func(m Mover) int { return m.ViewDelta })
+	r.TopLikeGains = topMovers(movers, func(m Mover) int { return m.LikeDelta })
+	return nil
+}
+
+// topMovers 依 delta 遞減取前 maxMovers 筆成長為正的模型
+func topMovers(movers []Mover, delta func(Mover) int) []Mover {
+	var top []Mover
+	for _, mover := range movers {
+		if delta(mover) > 0 {
+			top = append(top, mover)
+		}
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if delta(top[i]) != delta(top[j]) {
+			return delta(top[i]) > delta(top[j])
+		}
+		return top[i].ID < top[j].ID
+	})
+	if len(top) > maxMovers {
+		top = top[:maxMovers]
+	}
+	return top
+}
+
+// Title 報告標題
+func (r *Report) Title() string {
+	status := "✅"
+	if r.Health.Status == "failed" {
+		status = "❌"
+	}
+	return fmt.Sprintf("%s Sketchfab 同步報告 %s · %s：%d 個新模型", status, r.JobName, r.Health.StartedAt.Format("2006-01-02 15:04"), r.NewModelCount)
+}
@@ -0,0 +1,112 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fetch-sketchfab-data/internal/notify"
+	"fetch-sketchfab-data/internal/scheduler"
+	"fetch-sketchfab-data/internal/service"
+)
+
+// Reporter 每次任務結束後產生報告，寫入目錄及（或）透過 notifier 送出
+type Reporter struct {
+	modelsService *service.ModelsService
+	dir           string          // 空值表示不寫入檔案
+	notifier      notify.Notifier // nil 表示不送出
+	logService    *service.LogService
+}
+
+// NewReporter 建立新的報告產生器
+func NewReporter(modelsService *service.ModelsService, dir string, notifier notify.Notifier, logService *service.LogService) *Reporter {
+	return &Reporter{
+		modelsService: modelsService,
+		dir:           dir,
+		notifier:      notifier,
+		logService:    logService,
+	}
+}
+
+// Name 實作 scheduler.RunHook
+func (r *Reporter) Name() string {
+	return "report"
+}
+
+// AfterRun 實作 scheduler.RunHook
+func (r *Reporter) AfterRun(ctx context.Context, run *scheduler.RunResult, upsert *service.UpsertResult) error {
+	report, err := Build(ctx, r.modelsService, run, upsert)
+	if err != nil {
+		return fmt.Errorf("建立報告失敗: %v", err)
+	}
+	markdown, err := report.Markdown()
+	if err != nil {
+		return err
+	}
+	html, err := report.HTML()
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	if r.dir != "" {
+		paths, err := report.WriteFiles(r.dir, markdown, html)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else {
+			r.logService.Info("📝 已寫入同步報告", "paths", paths)
+		}
+	}
+	if r.notifier != nil {
+		err := r.notifier.Send(ctx, &notify.Message{
+			Kind:    "run_report",
+			Subject: report.Title(),
+			Text:    markdown,
+			HTML:    html,
+			Data:    report,
+		})
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("送出同步報告失敗: %v", err))
+		} else {
+			r.logService.Info("📨 已送出同步報告", "notifier", r.notifier.Name())
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// WriteFiles 將報告寫入 dir/<開始時間>-<任務>.md 與 .html，先寫入暫存檔再改名，回傳寫入的路徑
+func (r *Report) WriteFiles(dir, markdown, html string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("建立報告目錄失敗: %v", err)
+	}
+	base := r.Health.StartedAt.Format("20060102-150405") + "-" + safeFileName(r.JobName)
+	var paths []string
+	for ext, content := range map[string]string{".md": markdown, ".html": html} {
+		path := filepath.Join(dir, base+ext)
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+			return paths, fmt.Errorf("寫入報告失敗: %v", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			os.Remove(tmp)
+			return paths, fmt.Errorf("寫入報告失敗: %v", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// safeFileName 將任務名稱中不適合用於檔名的字元換成 "_"
+func safeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == ' ' || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, name)
+}
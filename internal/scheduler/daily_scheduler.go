@@ -2,82 +2,416 @@ package scheduler
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"fetch-sketchfab-data/internal/api"
+	"fetch-sketchfab-data/internal/metrics"
+	"fetch-sketchfab-data/internal/models"
 	"fetch-sketchfab-data/internal/service"
+	"fetch-sketchfab-data/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// ErrJobRunning 表示已有任務正在執行
+var ErrJobRunning = errors.New("已有任務正在執行中")
+
+// defaultRetryDelay 排程任務遇到執行中的任務時，重新嘗試前的等待時間
+const defaultRetryDelay = 30 * time.Second
+
+// Job 排程任務定義
+type Job struct {
+	Name     string                  `json:"name"`
+	Time     string                  `json:"time"` // 格式: "15:04" (24小時制)
+	Params   *models.GetModelsParams `json:"params,omitempty"`
+	MaxPages int                     `json:"max_pages"` // 0 表示只取第一頁，負數表示取到最後一頁
+}
+
+// JobProgress 執行中任務的進度
+type JobProgress struct {
+	RunID          string    `json:"run_id"`
+	JobName        string    `json:"job_name"`
+	Trigger        string    `json:"trigger"` // scheduled / manual
+	Stage          string    `json:"stage"`   // fetching / saving / done / failed
+	StartedAt      time.Time `json:"started_at"`
+	Page           int       `json:"page"`
+	FetchedCount   int       `json:"fetched_count"`
+	InsertedCount  int64     `json:"inserted_count"`
+	UpdatedCount   int64     `json:"updated_count"`
+	UnchangedCount int64     `json:"unchanged_count"`
+}
+
+// RunResult 已完成任務的結果
+type RunResult struct {
+	JobProgress
+	FinishedAt time.Time `json:"finished_at"`
+	Duration   string    `json:"duration"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// NextRun 任務的下次執行時間
+type NextRun struct {
+	JobName string    `json:"job_name"`
+	At      time.Time `json:"at"`
+}
+
+// Status 排程器狀態
+type Status struct {
+	Paused   bool         `json:"paused"`
+	Running  *JobProgress `json:"running,omitempty"`
+	LastRun  *RunResult   `json:"last_run,omitempty"`
+	NextRuns []NextRun    `json:"next_runs"`
+}
+
+// RunHook 每次任務結束後執行的後續處理（例如儲存搜尋通知），失敗只記錄日誌
+type RunHook interface {
+	// Name hook 名稱，用於日誌
+	Name() string
+	// AfterRun 接收任務結果與本次所有分頁合計的 upsert 結果；任務失敗時 upsert 結果只包含失敗前已儲存的分頁
+	AfterRun(ctx context.Context, run *RunResult, upsert *service.UpsertResult) error
+}
+
 // DailyScheduler 每日排程器
 type DailyScheduler struct {
 	apiClient     *api.SketchfabClient
 	modelsService *service.ModelsService
 	logService    *service.LogService
-	scheduleTime  string // 格式: "15:04" (24小時制)
+	runsService   *service.RunsService
+	hooks         []RunHook
 	stopChan      chan struct{}
+	wakeChan      chan struct{}
+	retryDelay    time.Duration // 排程任務遇到執行中的任務時，重新嘗試前的等待時間
+	// now 目前時間，測試時可替換
+	now func() time.Time
+	// work 任務的實際工作，預設為 fetchAndSaveData
+	work func(ctx context.Context, job *Job, runLog *service.LogService) (*service.UpsertResult, error)
+
+	mu       sync.Mutex
+	runCtx   context.Context // Start 的 context，手動觸發的背景任務也隨之取消
+	jobs     []*Job
+	schedule map[string]*scheduledRun // 任務名稱 → 下次執行時間
+	paused   bool
+	running  *JobProgress
+	lastRun  *RunResult
+}
+
+// scheduledRun 任務的下次執行時間，以及計算時使用的排程時間（排程時間變更時重新計算）
+type scheduledRun struct {
+	time string
+	at   time.Time
 }
 
-// NewDailyScheduler 建立新的每日排程器
+// NewDailyScheduler 建立新的每日排程器，每天在 scheduleTime 取得一頁可下載模型
 func NewDailyScheduler(apiClient *api.SketchfabClient, modelsService *service.ModelsService, logService *service.LogService, scheduleTime string) *DailyScheduler {
-	return &DailyScheduler{
+	return NewDailySchedulerWithJobs(apiClient, modelsService, logService, []*Job{
+		{Name: "downloadable", Time: scheduleTime, MaxPages: 1},
+	})
+}
+
+// NewDailySchedulerWithJobs 以指定的任務建立每日排程器，jobs 至少需要一個任務
+func NewDailySchedulerWithJobs(apiClient *api.SketchfabClient, modelsService *service.ModelsService, logService *service.LogService, jobs []*Job) *DailyScheduler {
+	s := &DailyScheduler{
 		apiClient:     apiClient,
 		modelsService: modelsService,
 		logService:    logService,
-		scheduleTime:  scheduleTime,
+		jobs:          jobs,
+		schedule:      map[string]*scheduledRun{},
 		stopChan:      make(chan struct{}),
+		wakeChan:      make(chan struct{}, 1),
+		retryDelay:    defaultRetryDelay,
+		now:           time.Now,
 	}
+	s.work = s.fetchAndSaveData
+	return s
+}
+
+// SetRunsService 設定執行紀錄服務，設定後每次任務完成都會寫入 sync_runs
+func (s *DailyScheduler) SetRunsService(runsService *service.RunsService) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runsService = runsService
+}
+
+// AddHook 加入任務結束後執行的 hook，依加入順序執行
+func (s *DailyScheduler) AddHook(hook RunHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, hook)
 }
 
 // Start 啟動每日排程器
 func (s *DailyScheduler) Start(ctx context.Context) error {
-	s.logService.Info(fmt.Sprintf("🕒 每日排程器已啟動，執行時間: %s", s.scheduleTime))
+	s.mu.Lock()
+	s.runCtx = ctx
+	s.mu.Unlock()
+
+	for _, job := range s.Jobs() {
+		s.logService.Info("🕒 每日排程器已啟動", "job", job.Name, "schedule_time", job.Time)
+	}
 
 	// 立即執行一次（可選）
 	s.logService.Info("執行初始資料同步...")
-	if err := s.fetchAndSaveData(); err != nil {
-		s.logService.Error(fmt.Sprintf("初始資料同步失敗: %v", err))
+	for _, job := range s.Jobs() {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := s.runJob(ctx, job, "scheduled"); err != nil {
+			s.logService.Error("初始資料同步失敗", "job", job.Name, "error", err)
+		}
 	}
 
 	for {
-		// 計算下次執行時間
-		nextRun := s.calculateNextRunTime()
-		waitDuration := time.Until(nextRun)
+		// 先執行所有已到期的任務（包含前一個任務執行期間到期的），再計算下次執行時間
+		s.runDueJobs(ctx)
+		job, nextRun := s.nextJob()
+		waitDuration := nextRun.Sub(s.now())
+		for _, next := range s.Status().NextRuns {
+			metrics.SchedulerNextRun.WithLabelValues(next.JobName).Set(float64(next.At.Unix()))
+		}
 
-		s.logService.Info(fmt.Sprintf("⏰ 下次執行時間: %s (等待 %v)", nextRun.Format("2006-01-02 15:04:05"), waitDuration))
+		s.logService.Info(fmt.Sprintf("⏰ 下次執行時間: %s", nextRun.Format("2006-01-02 15:04:05")),
+			"job", job.Name, "next_run", nextRun, "wait_ms", waitDuration)
 
+		timer := time.NewTimer(waitDuration)
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			s.logService.Info("接收到停止信號，正在關閉每日排程器...")
 			return ctx.Err()
 		case <-s.stopChan:
+			timer.Stop()
 			s.logService.Info("每日排程器已停止")
 			return nil
-		case <-time.After(waitDuration):
-			s.logService.Info("🚀 開始執行每日任務...")
-			if err := s.fetchAndSaveData(); err != nil {
-				s.logService.Error(fmt.Sprintf("❌ 每日任務執行失敗: %v", err))
-			} else {
-				s.logService.Info("✅ 每日任務執行完成")
-			}
+		case <-s.wakeChan:
+			// 排程狀態變更，重新計算下次執行時間
+			timer.Stop()
+		case <-timer.C:
 		}
 	}
 }
 
+// runDueJobs 依排定時間的順序執行所有已到期的任務，直到沒有到期的任務
+//
+// 已有任務執行中（例如手動觸發）時，到期的任務延後 retryDelay 再試，不會略過當天的執行。
+func (s *DailyScheduler) runDueJobs(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, ok := s.dueJob()
+		if !ok {
+			return
+		}
+		if s.IsPaused() {
+			s.logService.Warn("⏸️ 排程器已暫停，略過任務", "job", job.Name)
+			s.advance(job)
+			continue
+		}
+
+		s.logService.Info("🚀 開始執行每日任務...", "job", job.Name)
+		err := s.runJob(ctx, job, "scheduled")
+		switch {
+		case errors.Is(err, ErrJobRunning):
+			s.logService.Warn("⏳ 已有任務執行中，稍後重試", "job", job.Name, "retry_in", s.retryDelay.String())
+			s.postpone(job, s.retryDelay)
+			return
+		case err != nil:
+			s.logService.Error("❌ 每日任務執行失敗", "job", job.Name, "error", err)
+		default:
+			s.logService.Info("✅ 每日任務執行完成", "job", job.Name)
+		}
+		s.advance(job)
+	}
+}
+
 // Stop 停止每日排程器
 func (s *DailyScheduler) Stop() {
 	s.logService.Info("正在停止每日排程器...")
 	close(s.stopChan)
 }
 
+// Pause 暫停排程，已排定的任務到點時會被略過
+func (s *DailyScheduler) Pause() {
+	s.mu.Lock()
+	s.paused = true
+	s.mu.Unlock()
+	s.logService.Info("⏸️ 每日排程器已暫停")
+}
+
+// Resume 恢復排程
+func (s *DailyScheduler) Resume() {
+	s.mu.Lock()
+	s.paused = false
+	s.mu.Unlock()
+	s.logService.Info("▶️ 每日排程器已恢復")
+	s.wake()
+}
+
+// Jobs 取得目前的排程任務
+func (s *DailyScheduler) Jobs() []*Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Job(nil), s.jobs...)
+}
+
+// SetJobs 替換排程任務並重新計算下次執行時間，執行中的任務不受影響，jobs 至少需要一個任務
+func (s *DailyScheduler) SetJobs(jobs []*Job) {
+	s.mu.Lock()
+	names := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		names[job.Name] = true
+	}
+	for _, job := range s.jobs {
+		if !names[job.Name] {
+			metrics.SchedulerNextRun.DeleteLabelValues(job.Name)
+			delete(s.schedule, job.Name)
+		}
+	}
+	s.jobs = jobs
+	s.mu.Unlock()
+
+	s.logService.Info("🔁 排程任務已更新", "jobs", len(jobs))
+	s.wake()
+}
+
+// IsPaused 回傳排程是否暫停中
+func (s *DailyScheduler) IsPaused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// Status 取得排程器目前狀態
+func (s *DailyScheduler) Status() *Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := &Status{
+		Paused:   s.paused,
+		NextRuns: s.nextRuns(),
+	}
+	if s.running != nil {
+		running := *s.running
+		status.Running = &running
+	}
+	if s.lastRun != nil {
+		lastRun := *s.lastRun
+		status.LastRun = &lastRun
+	}
+	return status
+}
+
+// Trigger 立即在背景執行一次任務，params 為 nil 時使用預設任務的參數
+func (s *DailyScheduler) Trigger(params *models.GetModelsParams) error {
+	job := s.Jobs()[0]
+	if params != nil {
+		job = &Job{Name: "manual", Params: params, MaxPages: 1}
+	}
+
+	runID, err := s.beginRun(job.Name, "manual")
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err := s.execute(s.baseContext(), job, runID, "manual")
+		if err != nil {
+			s.logService.Error("❌ 手動任務執行失敗", "job", job.Name, "error", err)
+		} else {
+			s.logService.Info("✅ 手動任務執行完成", "job", job.Name)
+		}
+	}()
+	return nil
+}
+
+// baseContext 取得背景任務使用的 context：排程器啟動後為 Start 的 context，否則為 context.Background()
+func (s *DailyScheduler) baseContext() context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.runCtx != nil {
+		return s.runCtx
+	}
+	return context.Background()
+}
+
+// wake 通知排程迴圈重新計算下次執行時間
+func (s *DailyScheduler) wake() {
+	select {
+	case s.wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+// nextJob 取得最早要執行的任務
+func (s *DailyScheduler) nextJob() (*Job, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next *Job
+	var nextAt time.Time
+	for _, job := range s.jobs {
+		at := s.nextRunLocked(job)
+		if next == nil || at.Before(nextAt) {
+			next, nextAt = job, at
+		}
+	}
+	return next, nextAt
+}
+
+// dueJob 取得排定時間已到、最早的任務
+func (s *DailyScheduler) dueJob() (*Job, bool) {
+	job, at := s.nextJob()
+	if job == nil || at.After(s.now()) {
+		return nil, false
+	}
+	return job, true
+}
+
+// nextRunLocked 取得任務的下次執行時間，尚未排定或排程時間已變更時重新計算，呼叫端需持有 mu
+func (s *DailyScheduler) nextRunLocked(job *Job) time.Time {
+	run := s.schedule[job.Name]
+	if run == nil || run.time != job.Time {
+		run = &scheduledRun{time: job.Time, at: s.calculateNextRunTime(job.Time)}
+		s.schedule[job.Name] = run
+	}
+	return run.at
+}
+
+// advance 任務已處理，排定到下一個排程時間
+func (s *DailyScheduler) advance(job *Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedule[job.Name] = &scheduledRun{time: job.Time, at: s.calculateNextRunTime(job.Time)}
+}
+
+// postpone 將任務延後 delay 再執行
+func (s *DailyScheduler) postpone(job *Job, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedule[job.Name] = &scheduledRun{time: job.Time, at: s.now().Add(delay)}
+}
+
+// nextRuns 列出所有任務的下次執行時間，呼叫端需持有 mu
+func (s *DailyScheduler) nextRuns() []NextRun {
+	runs := make([]NextRun, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		runs = append(runs, NextRun{JobName: job.Name, At: s.nextRunLocked(job)})
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].At.Before(runs[j].At) })
+	return runs
+}
+
 // calculateNextRunTime 計算下次執行時間
-func (s *DailyScheduler) calculateNextRunTime() time.Time {
-	now := time.Now()
+func (s *DailyScheduler) calculateNextRunTime(scheduleTime string) time.Time {
+	now := s.now()
 
 	// 解析設定的時間
-	targetTime, err := time.Parse("15:04", s.scheduleTime)
+	targetTime, err := time.Parse("15:04", scheduleTime)
 	if err != nil {
-		s.logService.Error(fmt.Sprintf("時間格式錯誤，使用預設時間 09:00: %v", err))
+		s.logService.Error("時間格式錯誤，使用預設時間 09:00", "schedule_time", scheduleTime, "error", err)
 		targetTime, _ = time.Parse("15:04", "09:00")
 	}
 
@@ -93,41 +427,231 @@ func (s *DailyScheduler) calculateNextRunTime() time.Time {
 	return today
 }
 
-// fetchAndSaveData 取得並儲存資料
-func (s *DailyScheduler) fetchAndSaveData() error {
-	startTime := time.Now()
+// beginRun 標記任務開始執行並回傳 run ID，若已有任務執行中則回傳 ErrJobRunning
+func (s *DailyScheduler) beginRun(jobName, trigger string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running != nil {
+		return "", ErrJobRunning
+	}
+	s.running = &JobProgress{
+		RunID:     newRunID(),
+		JobName:   jobName,
+		Trigger:   trigger,
+		Stage:     "fetching",
+		StartedAt: time.Now(),
+	}
+	return s.running.RunID, nil
+}
+
+// newRunID 產生任務執行的識別碼
+func newRunID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// updateProgress 在鎖保護下更新進度
+func (s *DailyScheduler) updateProgress(update func(p *JobProgress)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running != nil {
+		update(s.running)
+	}
+}
+
+// finishRun 記錄任務結果並清除執行中狀態，回傳結果
+func (s *DailyScheduler) finishRun(runErr error) *RunResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running == nil {
+		return nil
+	}
+	result := &RunResult{
+		JobProgress: *s.running,
+		FinishedAt:  time.Now(),
+	}
+	result.Duration = result.FinishedAt.Sub(result.StartedAt).String()
+	if runErr != nil {
+		result.Stage = "failed"
+		result.Error = runErr.Error()
+	} else {
+		result.Stage = "done"
+		metrics.LastSuccessfulSync.WithLabelValues(result.JobName).Set(float64(result.FinishedAt.Unix()))
+	}
+	s.lastRun = result
+	s.running = nil
+	return result
+}
+
+// saveRun 將任務結果寫入執行紀錄，寫入失敗只記錄日誌
+func (s *DailyScheduler) saveRun(ctx context.Context, result *RunResult, runLog *service.LogService) {
+	s.mu.Lock()
+	runsService := s.runsService
+	s.mu.Unlock()
+	if runsService == nil || result == nil {
+		return
+	}
+
+	status := "done"
+	if result.Error != "" {
+		status = "failed"
+	}
+	err := runsService.SaveRun(ctx, &service.SyncRun{
+		RunID:          result.RunID,
+		JobName:        result.JobName,
+		Trigger:        result.Trigger,
+		Status:         status,
+		StartedAt:      result.StartedAt,
+		FinishedAt:     result.FinishedAt,
+		DurationMs:     result.FinishedAt.Sub(result.StartedAt).Milliseconds(),
+		Pages:          result.Page,
+		FetchedCount:   result.FetchedCount,
+		InsertedCount:  result.InsertedCount,
+		UpdatedCount:   result.UpdatedCount,
+		UnchangedCount: result.UnchangedCount,
+		Error:          result.Error,
+	})
+	if err != nil {
+		runLog.Warn("寫入執行紀錄失敗", "error", err)
+	}
+}
 
-	// 呼叫 API
-	response, err := s.apiClient.GetDownloadableModels()
+// runJob 同步執行一次任務，ctx 取消時中斷分頁取得、儲存與 hook
+func (s *DailyScheduler) runJob(ctx context.Context, job *Job, trigger string) error {
+	runID, err := s.beginRun(job.Name, trigger)
 	if err != nil {
-		return fmt.Errorf("API呼叫失敗: %v", err)
+		return err
+	}
+	return s.execute(ctx, job, runID, trigger)
+}
+
+// execute 執行已標記開始的任務並記錄結果，整次執行為一個 scheduler.run span
+func (s *DailyScheduler) execute(ctx context.Context, job *Job, runID, trigger string) error {
+	ctx, span := tracing.Start(ctx, "scheduler.run",
+		attribute.String("job", job.Name),
+		attribute.String("trigger", trigger),
+		attribute.String("run_id", runID),
+	)
+	runLog := s.logService.With("job", job.Name, "run_id", runID).WithTrace(ctx)
+	upsert, err := s.work(ctx, job, runLog)
+	tracing.End(span, err)
+	result := s.finishRun(err)
+	// 執行紀錄不受中斷影響，確保中斷的任務也留下 failed 紀錄
+	s.saveRun(context.WithoutCancel(ctx), result, runLog)
+	s.runHooks(ctx, result, upsert, runLog)
+	return err
+}
+
+// runHooks 依序執行任務結束後的 hook
+func (s *DailyScheduler) runHooks(ctx context.Context, result *RunResult, upsert *service.UpsertResult, runLog *service.LogService) {
+	s.mu.Lock()
+	hooks := s.hooks
+	s.mu.Unlock()
+	if result == nil {
+		return
+	}
+	for _, hook := range hooks {
+		if err := hook.AfterRun(ctx, result, upsert); err != nil {
+			runLog.Warn("⚠️ 任務後續處理失敗", "hook", hook.Name(), "error", err)
+		}
+	}
+}
+
+// fetchAndSaveData 逐頁取得並儲存資料，回傳所有分頁合計的 upsert 結果（失敗時為失敗前已儲存的分頁）；
+// runLog 為綁定 job、run_id 與 trace ID 的子日誌器
+func (s *DailyScheduler) fetchAndSaveData(ctx context.Context, job *Job, runLog *service.LogService) (*service.UpsertResult, error) {
+	startTime := time.Now()
+
+	params := job.Params
+	if params == nil {
+		params = &models.GetModelsParams{
+			Downloadable:     true,
+			ArchivesFlavours: false,
+		}
 	}
 
-	s.logService.Info(fmt.Sprintf("📥 成功取得 %d 個模型資料", len(response.Results)))
+	total := &service.UpsertResult{}
+	fetched := 0
 
-	// 儲存到資料庫
-	upsertResult, err := s.modelsService.ConvertAndSaveModelsResponse(response)
+	err := s.apiClient.GetModelsPages(ctx, params, job.MaxPages, func(page int, response *models.ModelsResponse) error {
+		pageLog := runLog.With("page", page)
+		pageLog.Info("📥 成功取得模型資料", "fetched", len(response.Results))
+		fetched += len(response.Results)
+		s.updateProgress(func(p *JobProgress) {
+			p.Stage = "saving"
+			p.Page = page
+			p.FetchedCount = fetched
+		})
+
+		// 儲存到資料庫
+		upsertResult, err := s.modelsService.ConvertAndSaveModelsResponse(ctx, response)
+		if err != nil {
+			return fmt.Errorf("儲存模型資料失敗: %v", err)
+		}
+		total.InsertedCount += upsertResult.InsertedCount
+		total.UpdatedCount += upsertResult.UpdatedCount
+		total.UnchangedCount += upsertResult.UnchangedCount
+		total.InsertedIDs = append(total.InsertedIDs, upsertResult.InsertedIDs...)
+		total.UpdatedIDs = append(total.UpdatedIDs, upsertResult.UpdatedIDs...)
+		total.SinkErrors = append(total.SinkErrors, upsertResult.SinkErrors...)
+		for _, uid := range upsertResult.InsertedIDs {
+			pageLog.Debug("🆕 新增模型", "uid", uid)
+		}
+		for _, uid := range upsertResult.UpdatedIDs {
+			pageLog.Debug("🔄 更新模型", "uid", uid)
+		}
+		for _, problem := range upsertResult.SinkErrors {
+			pageLog.Warn("⚠️ 同步下游失敗，可執行 reindex 重建", "error", problem)
+		}
+		if upsertResult.HistoryError != "" {
+			total.HistoryError = upsertResult.HistoryError
+			pageLog.Warn("⚠️ 寫入模型歷史失敗，這次的統計快照會缺漏", "error", upsertResult.HistoryError)
+		}
+
+		s.updateProgress(func(p *JobProgress) {
+			p.Stage = "fetching"
+			p.InsertedCount = total.InsertedCount
+			p.UpdatedCount = total.UpdatedCount
+			p.UnchangedCount = total.UnchangedCount
+		})
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("儲存模型資料失敗: %v", err)
+		return total, err
 	}
 
 	// 記錄統計資訊
-	duration := time.Since(startTime)
-	s.logService.Info(fmt.Sprintf("⏱️  任務完成 (耗時: %v)", duration))
-	s.logService.Info(fmt.Sprintf("📊 處理統計: 新增=%d, 更新=%d, 無變化=%d",
-		upsertResult.InsertedCount, upsertResult.UpdatedCount, upsertResult.UnchangedCount))
+	runLog.Info("📊 任務完成",
+		"duration_ms", time.Since(startTime),
+		"fetched", fetched,
+		"inserted", total.InsertedCount,
+		"updated", total.UpdatedCount,
+		"unchanged", total.UnchangedCount)
 
 	// 顯示資料庫總數
-	totalCount, err := s.modelsService.GetModelsCount()
+	totalCount, err := s.modelsService.GetModelsCount(ctx)
 	if err == nil {
-		s.logService.Info(fmt.Sprintf("💾 資料庫中的模型總數: %d", totalCount))
+		runLog.Info("💾 資料庫中的模型總數", "total_models", totalCount)
 	}
 
-	return nil
+	return total, nil
+}
+
+// RunJob 依名稱同步執行一次任務，找不到任務時回傳錯誤
+func (s *DailyScheduler) RunJob(ctx context.Context, name string) error {
+	for _, job := range s.Jobs() {
+		if job.Name == name {
+			return s.runJob(ctx, job, "manual")
+		}
+	}
+	return fmt.Errorf("找不到任務: %s", name)
 }
 
 // RunOnce 執行一次任務（用於手動觸發或測試）
-func (s *DailyScheduler) RunOnce() error {
+func (s *DailyScheduler) RunOnce(ctx context.Context) error {
 	s.logService.Info("🔧 執行單次任務...")
-	return s.fetchAndSaveData()
+	return s.runJob(ctx, s.Jobs()[0], "manual")
 }
@@ -0,0 +1,136 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// testScheduler 建立不連線 API 與 MongoDB 的排程器：時間從 start 起隨實際時間前進，任務只記錄執行次數
+func testScheduler(t *testing.T, start time.Time, jobs []*Job) (*DailyScheduler, func(name string) int) {
+	t.Helper()
+	logService := service.NewLogServiceWithSinks("test", service.DefaultShipperOptions())
+	t.Cleanup(func() { logService.Close() })
+
+	s := NewDailySchedulerWithJobs(nil, nil, logService, jobs)
+	began := time.Now()
+	s.now = func() time.Time { return start.Add(time.Since(began)) }
+
+	var mu sync.Mutex
+	runs := map[string]int{}
+	s.work = func(ctx context.Context, job *Job, runLog *service.LogService) (*service.UpsertResult, error) {
+		mu.Lock()
+		runs[job.Name]++
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		return &service.UpsertResult{}, nil
+	}
+	return s, func(name string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return runs[name]
+	}
+}
+
+// startScheduler 在背景執行 Start，測試結束時停止
+func startScheduler(t *testing.T, s *DailyScheduler) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Start(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// waitFor 等待 cond 成立，逾時則以 message 失敗
+func waitFor(t *testing.T, message string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(message)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestStartRunsAllJobsDueAtSameTime 同一時間的兩個任務都要執行，第二個不可因第一個執行完時已過時間而延到隔天
+func TestStartRunsAllJobsDueAtSameTime(t *testing.T) {
+	start := time.Date(2026, 1, 1, 8, 59, 59, 500*int(time.Millisecond), time.Local)
+	s, runs := testScheduler(t, start, []*Job{
+		{Name: "first", Time: "09:00"},
+		{Name: "second", Time: "09:00"},
+	})
+	startScheduler(t, s)
+
+	// 啟動時的初始同步執行一次，09:00 再各執行一次
+	waitFor(t, "09:00 的任務沒有全部執行", func() bool {
+		return runs("first") == 2 && runs("second") == 2
+	})
+
+	tomorrow := time.Date(2026, 1, 2, 9, 0, 0, 0, time.Local)
+	waitFor(t, "任務沒有排到隔天", func() bool {
+		next := s.Status().NextRuns
+		return len(next) == 2 && next[0].At.Equal(tomorrow) && next[1].At.Equal(tomorrow)
+	})
+}
+
+// TestStartRetriesJobBlockedByRunningJob 到期時已有手動任務執行中，排程任務要在手動任務結束後重試，而不是略過當天
+func TestStartRetriesJobBlockedByRunningJob(t *testing.T) {
+	start := time.Date(2026, 1, 1, 8, 59, 59, 700*int(time.Millisecond), time.Local)
+	s, runs := testScheduler(t, start, []*Job{{Name: "daily", Time: "09:00"}})
+	s.retryDelay = 20 * time.Millisecond
+
+	// 模擬執行中的手動任務，初始同步與 09:00 的執行都會遇到 ErrJobRunning
+	if _, err := s.beginRun("manual", "manual"); err != nil {
+		t.Fatal(err)
+	}
+	startScheduler(t, s)
+
+	nineAM := time.Date(2026, 1, 1, 9, 0, 0, 0, time.Local)
+	waitFor(t, "時間沒有前進到 09:00", func() bool { return s.now().After(nineAM.Add(100 * time.Millisecond)) })
+	if n := runs("daily"); n != 0 {
+		t.Fatalf("手動任務執行中不應執行排程任務，實際執行 %d 次", n)
+	}
+	s.finishRun(nil)
+
+	waitFor(t, "手動任務結束後排程任務沒有重試", func() bool { return runs("daily") == 1 })
+}
+
+// TestStopCancelsRunningJob 停止排程器的 context 時，執行中的任務要收到取消，不可繼續取得分頁
+func TestStopCancelsRunningJob(t *testing.T) {
+	s, _ := testScheduler(t, time.Now(), []*Job{{Name: "daily", Time: "09:00"}})
+	started := make(chan struct{})
+	s.work = func(ctx context.Context, job *Job, runLog *service.LogService) (*service.UpsertResult, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Start(ctx) }()
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Start 應回傳 context.Canceled，實際為 %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("取消後任務沒有停止")
+	}
+	if last := s.Status().LastRun; last == nil || last.Error == "" {
+		t.Fatalf("中斷的任務應記錄為失敗，實際為 %+v", last)
+	}
+}
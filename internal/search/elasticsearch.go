@@ -0,0 +1,534 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// mapping sketchfab-models 索引的欄位定義：名稱與描述為全文，標籤、分類、授權與作者為 keyword，數量為數值
+const mapping = `{
+  "settings": {
+    "number_of_shards": 1
+  },
+  "mappings": {
+    "dynamic": "strict",
+    "properties": {
+      "uid":             {"type": "keyword"},
+      "name":            {"type": "text", "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}},
+      "description":     {"type": "text"},
+      "uri":             {"type": "keyword", "index": false},
+      "viewer_url":      {"type": "keyword", "index": false},
+      "thumbnail_url":   {"type": "keyword", "index": false},
+      "user": {
+        "properties": {
+          "uid":          {"type": "keyword"},
+          "username":     {"type": "keyword"},
+          "display_name": {"type": "keyword"}
+        }
+      },
+      "license": {
+        "properties": {
+          "uid":   {"type": "keyword"},
+          "label": {"type": "keyword"}
+        }
+      },
+      "tags":            {"type": "keyword"},
+      "categories":      {"type": "keyword"},
+      "archive_formats": {"type": "keyword"},
+      "view_count":      {"type": "long"},
+      "like_count":      {"type": "long"},
+      "is_downloadable": {"type": "boolean"},
+      "created_at":      {"type": "date"},
+      "updated_at":      {"type": "date"},
+      "fetched_at":      {"type": "date"},
+      "changed_at":      {"type": "date"}
+    }
+  }
+}`
+
+// Config Elasticsearch 連線設定
+type Config struct {
+	URL      string
+	Index    string // 索引別名，實體索引為「別名-建立時間」
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+// Index 將模型寫入 Elasticsearch 的搜尋索引，實作 service.ModelSink
+//
+// 讀寫都透過別名進行，重建索引時建立新的實體索引，完成後再切換別名，搜尋不會中斷。
+type Index struct {
+	cfg        Config
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	ensured bool
+}
+
+// NewIndex 建立新的搜尋索引
+func NewIndex(cfg Config) *Index {
+	return &Index{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Name 實作 service.ModelSink
+func (i *Index) Name() string {
+	return "elasticsearch"
+}
+
+// Alias 索引別名
+func (i *Index) Alias() string {
+	return i.cfg.Index
+}
+
+// document 索引中的模型文件
+type document struct {
+	UID            string     `json:"uid"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	URI            string     `json:"uri"`
+	ViewerURL      string     `json:"viewer_url,omitempty"`
+	ThumbnailURL   string     `json:"thumbnail_url,omitempty"`
+	User           user       `json:"user"`
+	License        license    `json:"license"`
+	Tags           []string   `json:"tags"`
+	Categories     []string   `json:"categories"`
+	ArchiveFormats []string   `json:"archive_formats"`
+	ViewCount      int        `json:"view_count"`
+	LikeCount      int        `json:"like_count"`
+	IsDownloadable bool       `json:"is_downloadable"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+	FetchedAt      *time.Time `json:"fetched_at,omitempty"`
+	ChangedAt      *time.Time `json:"changed_at,omitempty"`
+}
+
+// user 文件中的作者
+type user struct {
+	UID         string `json:"uid"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+}
+
+// license 文件中的授權
+type license struct {
+	UID   string `json:"uid"`
+	Label string `json:"label"`
+}
+
+// newDocument 將模型轉為索引文件
+func newDocument(model *service.SketchfabModel) *document {
+	formats := []string{}
+	for _, archive := range model.ArchiveList() {
+		formats = append(formats, archive.Format)
+	}
+	return &document{
+		UID:          model.ID,
+		Name:         model.Name,
+		Description:  model.Description,
+		URI:          model.URI,
+		ViewerURL:    model.ViewerURL(),
+		ThumbnailURL: model.ThumbnailURL(1024),
+		User: user{
+			UID:         model.UserString("uid"),
+			Username:    model.UserString("username"),
+			DisplayName: model.UserString("displayName"),
+		},
+		License: license{
+			UID:   model.LicenseString("uid"),
+			Label: model.LicenseString("label"),
+		},
+		Tags:           model.TagNames(),
+		Categories:     model.CategoryNames(),
+		ArchiveFormats: formats,
+		ViewCount:      model.ViewCount,
+		LikeCount:      model.LikeCount,
+		IsDownloadable: model.IsDownloadable,
+		CreatedAt:      optionalTime(model.CreatedAt),
+		UpdatedAt:      optionalTime(model.UpdatedAt),
+		FetchedAt:      optionalTime(model.FetchedAt),
+		ChangedAt:      optionalTime(model.ChangedAt),
+	}
+}
+
+// optionalTime 零值時間不寫入文件
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// Ping 檢查 Elasticsearch 是否可連線
+func (i *Index) Ping(ctx context.Context) error {
+	return i.do(ctx, http.MethodGet, "/", nil, nil)
+}
+
+// EnsureIndex 別名不存在時建立實體索引並指向別名，回傳別名目前指向的索引名稱
+func (i *Index) EnsureIndex(ctx context.Context) ([]string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	indices, err := i.aliasIndices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(indices) == 0 {
+		name := i.newIndexName()
+		if err := i.createIndex(ctx, name, true); err != nil {
+			return nil, err
+		}
+		indices = []string{name}
+	}
+	i.ensured = true
+	return indices, nil
+}
+
+// ensure 第一次寫入前確認索引存在
+func (i *Index) ensure(ctx context.Context) error {
+	i.mu.Lock()
+	ensured := i.ensured
+	i.mu.Unlock()
+	if ensured {
+		return nil
+	}
+	_, err := i.EnsureIndex(ctx)
+	return err
+}
+
+// IndexModels 實作 service.ModelSink，以 bulk API 寫入模型
+func (i *Index) IndexModels(ctx context.Context, models []*service.SketchfabModel) error {
+	if len(models) == 0 {
+		return nil
+	}
+	if err := i.ensure(ctx); err != nil {
+		return err
+	}
+	return i.bulkIndex(ctx, i.cfg.Index, models)
+}
+
+// DeleteModels 實作 service.ModelSink，以 bulk API 移除模型，不存在的文件會被略過
+func (i *Index) DeleteModels(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := i.ensure(ctx); err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, id := range ids {
+		if err := encoder.Encode(bulkAction{"delete": {Index: i.cfg.Index, ID: id}}); err != nil {
+			return fmt.Errorf("編碼刪除請求失敗: %v", err)
+		}
+	}
+	return i.bulk(ctx, &body)
+}
+
+// bulkAction bulk API 的動作列
+type bulkAction map[string]struct {
+	Index string `json:"_index"`
+	ID    string `json:"_id"`
+}
+
+// bulkResponse bulk API 的回應，只解析錯誤
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string `json:"_id"`
+		Status int    `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// bulkIndex 將模型寫入指定的索引
+func (i *Index) bulkIndex(ctx context.Context, index string, models []*service.SketchfabModel) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	encoder.SetEscapeHTML(false)
+	for _, model := range models {
+		if err := encoder.Encode(bulkAction{"index": {Index: index, ID: model.ID}}); err != nil {
+			return fmt.Errorf("編碼索引請求失敗: %v", err)
+		}
+		if err := encoder.Encode(newDocument(model)); err != nil {
+			return fmt.Errorf("編碼模型 %s 失敗: %v", model.ID, err)
+		}
+	}
+	return i.bulk(ctx, &body)
+}
+
+// bulk 送出 bulk 請求，任一項目失敗時回傳錯誤（刪除不存在的文件不視為失敗）
+func (i *Index) bulk(ctx context.Context, body io.Reader) error {
+	var response bulkResponse
+	if err := i.doNDJSON(ctx, "/_bulk", body, &response); err != nil {
+		return err
+	}
+	if !response.Errors {
+		return nil
+	}
+
+	failed := 0
+	var first string
+	for _, item := range response.Items {
+		for action, result := range item {
+			if result.Error == nil || (action == "delete" && result.Status == http.StatusNotFound) {
+				continue
+			}
+			if failed == 0 {
+				first = fmt.Sprintf("%s %s: %s: %s", action, result.ID, result.Error.Type, result.Error.Reason)
+			}
+			failed++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d 筆寫入失敗，第一筆: %s", failed, first)
+}
+
+// ReindexResult 重建索引的結果
+type ReindexResult struct {
+	Index    string   // 新的實體索引
+	Indexed  int      // 從 MongoDB 寫入的模型數
+	CaughtUp int      // 重建期間新增或變更、切換別名後補寫的模型數
+	Removed  int      // 重建期間刪除、切換別名後補刪的模型數
+	Dropped  []string // 已刪除的舊索引
+}
+
+// Reindex 從 MongoDB 重建索引：寫入新的實體索引後切換別名，再補上重建期間的變更並刪除舊索引
+func (i *Index) Reindex(ctx context.Context, modelsService *service.ModelsService, batchSize int) (*ReindexResult, error) {
+	startedAt := time.Now()
+	result := &ReindexResult{Index: i.newIndexName()}
+	if err := i.createIndex(ctx, result.Index, false); err != nil {
+		return nil, err
+	}
+
+	// 寫入全部模型
+	batch := make([]*service.SketchfabModel, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := i.bulkIndex(ctx, result.Index, batch)
+		batch = batch[:0]
+		return err
+	}
+	count, err := modelsService.StreamModels(ctx, &service.ModelFilter{WithRawData: true}, func(model *service.SketchfabModel) error {
+		batch = append(batch, model)
+		if len(batch) >= batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		i.deleteIndex(context.Background(), result.Index)
+		return nil, fmt.Errorf("重建索引失敗（已寫入 %d 筆）: %v", count, err)
+	}
+	result.Indexed = count
+
+	// 切換別名
+	old, err := i.swapAlias(ctx, result.Index)
+	if err != nil {
+		i.deleteIndex(context.Background(), result.Index)
+		return nil, err
+	}
+
+	// 重建期間的寫入仍進入舊索引，切換後補上
+	_, err = modelsService.StreamModels(ctx, &service.ModelFilter{WithRawData: true, ChangedSince: &startedAt}, func(model *service.SketchfabModel) error {
+		batch = append(batch, model)
+		result.CaughtUp++
+		if len(batch) >= batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("補寫重建期間的變更失敗: %v", err)
+	}
+	var removed []string
+	_, err = modelsService.StreamTombstones(ctx, &startedAt, nil, func(tombstone *service.Tombstone) error {
+		removed = append(removed, tombstone.ID)
+		return nil
+	})
+	if err == nil {
+		err = i.DeleteModels(ctx, removed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("補刪重建期間刪除的模型失敗: %v", err)
+	}
+	result.Removed = len(removed)
+
+	for _, index := range old {
+		if err := i.deleteIndex(ctx, index); err != nil {
+			return nil, err
+		}
+		result.Dropped = append(result.Dropped, index)
+	}
+	return result, nil
+}
+
+// newIndexName 以建立時間命名實體索引
+func (i *Index) newIndexName() string {
+	return i.cfg.Index + "-" + time.Now().UTC().Format("20060102150405")
+}
+
+// createIndex 以 mapping 建立實體索引，withAlias 為 true 時同時指向別名
+func (i *Index) createIndex(ctx context.Context, name string, withAlias bool) error {
+	body := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(mapping), &body); err != nil {
+		return fmt.Errorf("解析索引定義失敗: %v", err)
+	}
+	if withAlias {
+		body["aliases"] = map[string]interface{}{i.cfg.Index: map[string]interface{}{}}
+	}
+	if err := i.do(ctx, http.MethodPut, "/"+name, body, nil); err != nil {
+		return fmt.Errorf("建立索引 %s 失敗: %v", name, err)
+	}
+	return nil
+}
+
+// deleteIndex 刪除實體索引
+func (i *Index) deleteIndex(ctx context.Context, name string) error {
+	if err := i.do(ctx, http.MethodDelete, "/"+name, nil, nil); err != nil && !isNotFound(err) {
+		return fmt.Errorf("刪除索引 %s 失敗: %v", name, err)
+	}
+	return nil
+}
+
+// aliasIndices 取得別名指向的實體索引；與別名同名的實體索引也會列出，不存在時回傳空清單
+func (i *Index) aliasIndices(ctx context.Context) ([]string, error) {
+	var response map[string]json.RawMessage
+	err := i.do(ctx, http.MethodGet, "/"+i.cfg.Index+"/_alias", nil, &response)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查詢索引別名失敗: %v", err)
+	}
+	indices := make([]string, 0, len(response))
+	for index := range response {
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
+
+// swapAlias 將別名原子地切換到新索引，回傳需要刪除的舊索引
+//
+// 與別名同名的實體索引（例如手動建立的索引）會在切換時一併移除。
+func (i *Index) swapAlias(ctx context.Context, index string) ([]string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	current, err := i.aliasIndices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var actions []map[string]interface{}
+	var old []string
+	for _, name := range current {
+		if name == i.cfg.Index {
+			actions = append(actions, map[string]interface{}{"remove_index": map[string]string{"index": name}})
+			continue
+		}
+		actions = append(actions, map[string]interface{}{"remove": map[string]string{"index": name, "alias": i.cfg.Index}})
+		old = append(old, name)
+	}
+	actions = append(actions, map[string]interface{}{"add": map[string]string{"index": index, "alias": i.cfg.Index}})
+
+	if err := i.do(ctx, http.MethodPost, "/_aliases", map[string]interface{}{"actions": actions}, nil); err != nil {
+		return nil, fmt.Errorf("切換索引別名失敗: %v", err)
+	}
+	i.ensured = true
+	return old, nil
+}
+
+// statusError Elasticsearch 回傳的錯誤狀態
+type statusError struct {
+	Status int
+	Body   string
+}
+
+// Error 實作 error
+func (e *statusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.Status, e.Body)
+}
+
+// isNotFound 判斷是否為 404
+func isNotFound(err error) bool {
+	var statusErr *statusError
+	return errors.As(err, &statusErr) && statusErr.Status == http.StatusNotFound
+}
+
+// do 送出 JSON 請求，in 為 nil 時不帶 body，out 不為 nil 時解析回應
+func (i *Index) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	return i.send(ctx, method, path, "application/json", body, out)
+}
+
+// doNDJSON 送出 NDJSON 請求（bulk API）
+func (i *Index) doNDJSON(ctx context.Context, path string, body io.Reader, out interface{}) error {
+	return i.send(ctx, http.MethodPost, path, "application/x-ndjson", body, out)
+}
+
+// send 送出請求，非 2xx 回應時回傳 *statusError
+func (i *Index) send(ctx context.Context, method, path, contentType string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(i.cfg.URL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if i.cfg.Username != "" || i.cfg.Password != "" {
+		req.SetBasicAuth(i.cfg.Username, i.cfg.Password)
+	}
+
+	resp, err := i.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &statusError{Status: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	if out == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("解析回應失敗: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// CatalogueHandler 模型資料庫唯讀 REST API
+type CatalogueHandler struct {
+	modelsService *service.ModelsService
+}
+
+// NewCatalogueHandler 建立新的模型資料庫 API
+func NewCatalogueHandler(modelsService *service.ModelsService) *CatalogueHandler {
+	return &CatalogueHandler{
+		modelsService: modelsService,
+	}
+}
+
+// Register 將模型資料庫 API 路由註冊到伺服器
+func (h *CatalogueHandler) Register(s *Server) {
+	s.HandleFunc("GET /models", h.handleList)
+	s.HandleFunc("GET /models/{uid}", h.handleGet)
+	s.HandleFunc("GET /models/{uid}/history", h.handleHistory)
+	s.HandleFunc("GET /stats", h.handleStats)
+}
+
+// handleList 依條件列出模型
+func (h *CatalogueHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	filter, err := ParseModelFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.modelsService.QueryModels(filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// handleGet 取得單一模型
+func (h *CatalogueHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	model, err := h.modelsService.GetModelByID(r.PathValue("uid"))
+	if err != nil {
+		if errors.Is(err, service.ErrModelNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model)
+}
+
+// handleHistory 取得模型的統計數據歷史
+func (h *CatalogueHandler) handleHistory(w http.ResponseWriter, r *http.Request) {
+	uid := r.PathValue("uid")
+
+	if _, err := h.modelsService.GetModelByID(uid); err != nil {
+		if errors.Is(err, service.ErrModelNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	history, err := h.modelsService.GetModelHistory(uid)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"uid":     uid,
+		"history": history,
+	})
+}
+
+// handleStats 取得模型資料庫統計
+func (h *CatalogueHandler) handleStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.modelsService.GetStats()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// ParseModelFilter 將查詢參數解析為模型查詢條件
+func ParseModelFilter(query url.Values) (*service.ModelFilter, error) {
+	filter := &service.ModelFilter{
+		Query:    query.Get("q"),
+		Tag:      query.Get("tag"),
+		Category: query.Get("category"),
+		License:  query.Get("license"),
+		User:     query.Get("user"),
+		Sort:     query.Get("sort"),
+		Cursor:   query.Get("cursor"),
+	}
+
+	var err error
+	if value := query.Get("downloadable"); value != "" {
+		downloadable, parseErr := strconv.ParseBool(value)
+		if parseErr != nil {
+			return nil, fmt.Errorf("downloadable 參數格式錯誤: %s", value)
+		}
+		filter.Downloadable = &downloadable
+	}
+	if filter.CreatedAfter, err = parseTimeParam(query, "created_after"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedBefore, err = parseTimeParam(query, "created_before"); err != nil {
+		return nil, err
+	}
+	if filter.MinViews, err = parseIntParam(query, "min_views"); err != nil {
+		return nil, err
+	}
+	if filter.MaxViews, err = parseIntParam(query, "max_views"); err != nil {
+		return nil, err
+	}
+	if filter.MinLikes, err = parseIntParam(query, "min_likes"); err != nil {
+		return nil, err
+	}
+	if filter.MaxLikes, err = parseIntParam(query, "max_likes"); err != nil {
+		return nil, err
+	}
+
+	limit, err := parseIntParam(query, "limit")
+	if err != nil {
+		return nil, err
+	}
+	if limit != nil {
+		filter.Limit = *limit
+	}
+
+	return filter, nil
+}
+
+// parseIntParam 解析整數查詢參數
+func parseIntParam(query url.Values, key string) (*int, error) {
+	value := query.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("%s 參數格式錯誤: %s", key, value)
+	}
+	return &n, nil
+}
+
+// parseTimeParam 解析時間查詢參數，接受 RFC3339 或 YYYY-MM-DD
+func parseTimeParam(query url.Values, key string) (*time.Time, error) {
+	value := query.Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%s 參數格式錯誤: %s", key, value)
+}
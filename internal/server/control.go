@@ -0,0 +1,125 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"fetch-sketchfab-data/internal/models"
+	"fetch-sketchfab-data/internal/scheduler"
+	"fetch-sketchfab-data/internal/service"
+)
+
+// ControlHandler 排程器控制 API
+type ControlHandler struct {
+	scheduler     *scheduler.DailyScheduler
+	modelsService *service.ModelsService
+	token         string
+}
+
+// NewControlHandler 建立新的排程器控制 API
+func NewControlHandler(dailyScheduler *scheduler.DailyScheduler, token string) *ControlHandler {
+	return &ControlHandler{
+		scheduler: dailyScheduler,
+		token:     token,
+	}
+}
+
+// NewControlHandlerWithModels 建立可移除模型的控制 API，移除的模型會留下刪除紀錄供增量匯出
+func NewControlHandlerWithModels(dailyScheduler *scheduler.DailyScheduler, modelsService *service.ModelsService, token string) *ControlHandler {
+	h := NewControlHandler(dailyScheduler, token)
+	h.modelsService = modelsService
+	return h
+}
+
+// Register 將控制 API 路由註冊到伺服器
+func (h *ControlHandler) Register(s *Server) {
+	s.Handle("POST /scheduler/run", h.requireToken(http.HandlerFunc(h.handleRun)))
+	s.Handle("POST /scheduler/pause", h.requireToken(http.HandlerFunc(h.handlePause)))
+	s.Handle("POST /scheduler/resume", h.requireToken(http.HandlerFunc(h.handleResume)))
+	s.Handle("GET /scheduler/status", h.requireToken(http.HandlerFunc(h.handleStatus)))
+	if h.modelsService != nil {
+		s.Handle("DELETE /models/{uid}", h.requireToken(http.HandlerFunc(h.handleRemoveModel)))
+	}
+}
+
+// requireToken 驗證 Authorization: Bearer <token> 標頭
+func (h *ControlHandler) requireToken(next http.Handler) http.Handler {
+	return bearerAuth(h.token, next)
+}
+
+// bearerAuth 驗證 Authorization: Bearer <token> 標頭，不符時回傳 401
+func bearerAuth(expected string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			writeError(w, http.StatusUnauthorized, "未授權")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleRun 立即觸發一次任務，可在 body 傳入 GetModelsParams 覆寫查詢參數
+func (h *ControlHandler) handleRun(w http.ResponseWriter, r *http.Request) {
+	var params *models.GetModelsParams
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "無法讀取請求內容")
+		return
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		params = &models.GetModelsParams{}
+		if err := json.Unmarshal(body, params); err != nil {
+			writeError(w, http.StatusBadRequest, "無法解析 GetModelsParams: "+err.Error())
+			return
+		}
+	}
+
+	if err := h.scheduler.Trigger(params); err != nil {
+		if errors.Is(err, scheduler.ErrJobRunning) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, h.scheduler.Status())
+}
+
+// handlePause 暫停排程
+func (h *ControlHandler) handlePause(w http.ResponseWriter, r *http.Request) {
+	h.scheduler.Pause()
+	writeJSON(w, http.StatusOK, h.scheduler.Status())
+}
+
+// handleResume 恢復排程
+func (h *ControlHandler) handleResume(w http.ResponseWriter, r *http.Request) {
+	h.scheduler.Resume()
+	writeJSON(w, http.StatusOK, h.scheduler.Status())
+}
+
+// handleStatus 回傳排程器狀態、下次執行時間與執行中任務的進度
+func (h *ControlHandler) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.scheduler.Status())
+}
+
+// handleRemoveModel 移除模型並寫入刪除紀錄，可用 reason 查詢參數記錄原因
+func (h *ControlHandler) handleRemoveModel(w http.ResponseWriter, r *http.Request) {
+	uid := r.PathValue("uid")
+	removed, err := h.modelsService.RemoveModels(r.Context(), []string{uid}, r.URL.Query().Get("reason"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if removed == 0 {
+		writeError(w, http.StatusNotFound, "找不到模型: "+uid)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,340 @@
+package server
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// defaultFeedLimit 訂閱源預設的項目數
+const defaultFeedLimit = 50
+
+// FeedHandler 最近新增模型的 Atom 與 RSS 訂閱源
+type FeedHandler struct {
+	modelsService *service.ModelsService
+}
+
+// NewFeedHandler 建立新的訂閱源 handler
+func NewFeedHandler(modelsService *service.ModelsService) *FeedHandler {
+	return &FeedHandler{modelsService: modelsService}
+}
+
+// Register 將訂閱源路由註冊到伺服器
+func (h *FeedHandler) Register(s *Server) {
+	s.HandleFunc("GET /feeds/models.atom", h.handleAtom)
+	s.HandleFunc("GET /feeds/models.rss", h.handleRSS)
+}
+
+// feedEntry 訂閱源中的一個模型
+type feedEntry struct {
+	ID           string
+	Title        string
+	Link         string
+	ThumbnailURL string
+	Description  string
+	Author       string
+	AuthorURL    string
+	License      string
+	Categories   []string
+	Published    time.Time // Sketchfab 上的建立時間
+	Inserted     time.Time // 第一次寫入資料庫的時間
+}
+
+// feed 訂閱源內容
+type feed struct {
+	Title   string
+	SelfURL string
+	Updated time.Time
+	Entries []*feedEntry
+}
+
+// loadFeed 依查詢參數（與 /models 相同）取得最近新增的模型，查詢參數錯誤時已輸出錯誤並回傳 nil
+func (h *FeedHandler) loadFeed(w http.ResponseWriter, r *http.Request) *feed {
+	filter, err := ParseModelFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return nil
+	}
+	filter.Sort = "-inserted_at"
+	filter.Cursor = ""
+	filter.WithRawData = true
+	if filter.Limit <= 0 {
+		filter.Limit = defaultFeedLimit
+	}
+
+	page, err := h.modelsService.QueryModels(filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return nil
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil
+	}
+
+	f := &feed{Title: feedTitle(filter), SelfURL: requestURL(r)}
+	for _, model := range page.Models {
+		entry := newFeedEntry(model)
+		if entry.Inserted.After(f.Updated) {
+			f.Updated = entry.Inserted
+		}
+		f.Entries = append(f.Entries, entry)
+	}
+	if f.Updated.IsZero() {
+		f.Updated = time.Now()
+	}
+	return f
+}
+
+// newFeedEntry 由儲存的模型建立訂閱源項目
+func newFeedEntry(model *service.SketchfabModel) *feedEntry {
+	entry := &feedEntry{
+		ID:           "urn:sketchfab:model:" + model.ID,
+		Title:        model.Name,
+		Link:         model.ViewerURL(),
+		ThumbnailURL: model.ThumbnailURL(640),
+		Description:  model.Description,
+		Author:       model.UserString("displayName"),
+		AuthorURL:    model.UserString("profileUrl"),
+		License:      model.LicenseString("label"),
+		Categories:   append(model.TagNames(), model.CategoryNames()...),
+		Published:    model.CreatedAt,
+		Inserted:     model.InsertedAt,
+	}
+	if entry.Author == "" {
+		entry.Author = model.UserString("username")
+	}
+	if entry.Inserted.IsZero() {
+		// 新增 inserted_at 之前寫入的模型
+		entry.Inserted = model.FetchedAt
+	}
+	return entry
+}
+
+// contentHTML 項目的 HTML 內容：縮圖、描述、作者與授權
+func (e *feedEntry) contentHTML() string {
+	var b strings.Builder
+	if e.ThumbnailURL != "" {
+		fmt.Fprintf(&b, `<p><a href="%s"><img src="%s" alt="%s"></a></p>`,
+			html.EscapeString(e.Link), html.EscapeString(e.ThumbnailURL), html.EscapeString(e.Title))
+	}
+	if e.Description != "" {
+		fmt.Fprintf(&b, "<p>%s</p>", strings.ReplaceAll(html.EscapeString(e.Description), "\n", "<br>"))
+	}
+	fmt.Fprintf(&b, "<p>作者: %s · 授權: %s</p>", html.EscapeString(e.Author), html.EscapeString(e.License))
+	return b.String()
+}
+
+// feedTitle 依篩選條件產生訂閱源標題
+func feedTitle(filter *service.ModelFilter) string {
+	var conditions []string
+	for _, c := range []struct{ name, value string }{
+		{"tag", filter.Tag}, {"category", filter.Category}, {"license", filter.License}, {"user", filter.User}, {"q", filter.Query},
+	} {
+		if c.value != "" {
+			conditions = append(conditions, c.name+": "+c.value)
+		}
+	}
+	if len(conditions) == 0 {
+		return "Sketchfab 新模型"
+	}
+	return "Sketchfab 新模型（" + strings.Join(conditions, ", ") + "）"
+}
+
+// requestURL 重建請求的完整網址，反向代理可用 X-Forwarded-Proto 指定協定
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+// writeXML 輸出 XML 回應
+func writeXML(w http.ResponseWriter, contentType string, data interface{}) {
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	enc.Encode(data)
+}
+
+// Atom 1.0（RFC 4287）
+
+type atomFeed struct {
+	XMLName xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	XMLNS   string       `xml:"xmlns:media,attr"`
+	ID      string       `xml:"id"`
+	Title   string       `xml:"title"`
+	Updated string       `xml:"updated"`
+	Links   []atomLink   `xml:"link"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Author     atomAuthor     `xml:"author"`
+	Rights     string         `xml:"rights,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Thumbnail  *mediaThumb    `xml:"media:thumbnail"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type mediaThumb struct {
+	URL string `xml:"url,attr"`
+}
+
+// handleAtom 輸出 Atom 訂閱源
+func (h *FeedHandler) handleAtom(w http.ResponseWriter, r *http.Request) {
+	f := h.loadFeed(w, r)
+	if f == nil {
+		return
+	}
+
+	out := &atomFeed{
+		XMLNS:   "http://search.yahoo.com/mrss/",
+		ID:      f.SelfURL,
+		Title:   f.Title,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Links:   []atomLink{{Rel: "self", Href: f.SelfURL, Type: "application/atom+xml"}},
+	}
+	for _, e := range f.Entries {
+		entry := &atomEntry{
+			ID:      e.ID,
+			Title:   e.Title,
+			Updated: e.Inserted.UTC().Format(time.RFC3339),
+			Author:  atomAuthor{Name: e.Author, URI: e.AuthorURL},
+			Rights:  e.License,
+			Content: atomContent{Type: "html", Body: e.contentHTML()},
+		}
+		if !e.Published.IsZero() {
+			entry.Published = e.Published.UTC().Format(time.RFC3339)
+		}
+		if e.Link != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "alternate", Href: e.Link, Type: "text/html"})
+		}
+		if e.ThumbnailURL != "" {
+			entry.Thumbnail = &mediaThumb{URL: e.ThumbnailURL}
+		}
+		for _, category := range e.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		out.Entries = append(out.Entries, entry)
+	}
+	writeXML(w, "application/atom+xml", out)
+}
+
+// RSS 2.0
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Media   string     `xml:"xmlns:media,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	LastBuildDate string     `xml:"lastBuildDate"`
+	Self          atomLink   `xml:"atom:link"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string       `xml:"title"`
+	Link        string       `xml:"link,omitempty"`
+	GUID        rssGUID      `xml:"guid"`
+	PubDate     string       `xml:"pubDate"`
+	Creator     string       `xml:"dc:creator,omitempty"`
+	Categories  []string     `xml:"category"`
+	License     string       `xml:"media:license,omitempty"`
+	Thumbnail   *mediaThumb  `xml:"media:thumbnail"`
+	Description rssCDATAText `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssCDATAText struct {
+	Value string `xml:",cdata"`
+}
+
+// handleRSS 輸出 RSS 訂閱源
+func (h *FeedHandler) handleRSS(w http.ResponseWriter, r *http.Request) {
+	f := h.loadFeed(w, r)
+	if f == nil {
+		return
+	}
+
+	out := &rssFeed{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		Media:   "http://search.yahoo.com/mrss/",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.SelfURL,
+			Description:   f.Title,
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+			Self:          atomLink{Rel: "self", Href: f.SelfURL, Type: "application/rss+xml"},
+		},
+	}
+	for _, e := range f.Entries {
+		item := &rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			GUID:        rssGUID{IsPermaLink: "false", Value: e.ID},
+			PubDate:     e.Inserted.UTC().Format(time.RFC1123Z),
+			Creator:     e.Author,
+			Categories:  e.Categories,
+			License:     e.License,
+			Description: rssCDATAText{Value: e.contentHTML()},
+		}
+		if e.ThumbnailURL != "" {
+			item.Thumbnail = &mediaThumb{URL: e.ThumbnailURL}
+		}
+		out.Channel.Items = append(out.Channel.Items, item)
+	}
+	writeXML(w, "application/rss+xml", out)
+}
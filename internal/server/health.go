@@ -0,0 +1,270 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"fetch-sketchfab-data/internal/api"
+	"fetch-sketchfab-data/internal/database"
+	"fetch-sketchfab-data/internal/search"
+	"fetch-sketchfab-data/internal/service"
+)
+
+// 健康檢查狀態
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthFail     = "fail"
+)
+
+// HealthCheck 單一相依服務的檢查
+type HealthCheck struct {
+	Name string
+	// Degradable 為 true 時，檢查失敗只會讓整體狀態變為 degraded 而非 fail
+	Degradable bool
+	// CacheFor 檢查結果的快取時間，避免每次探測都呼叫外部服務
+	CacheFor time.Duration
+	Run      func(ctx context.Context) (map[string]interface{}, error)
+}
+
+// CheckResult 單一檢查的結果
+type CheckResult struct {
+	Status    string                 `json:"status"`
+	LatencyMs int64                  `json:"latency_ms"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
+
+// HealthReport 健康檢查回應
+type HealthReport struct {
+	Status string                  `json:"status"`
+	Uptime string                  `json:"uptime"`
+	Checks map[string]*CheckResult `json:"checks,omitempty"`
+}
+
+// HealthHandler 存活與就緒檢查端點
+type HealthHandler struct {
+	checks    []HealthCheck
+	startedAt time.Time
+	timeout   time.Duration
+
+	mu    sync.Mutex
+	cache map[string]*CheckResult
+}
+
+// NewHealthHandler 建立新的健康檢查端點
+func NewHealthHandler(checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{
+		checks:    checks,
+		startedAt: time.Now(),
+		timeout:   5 * time.Second,
+		cache:     make(map[string]*CheckResult),
+	}
+}
+
+// Register 將健康檢查路由註冊到伺服器
+func (h *HealthHandler) Register(s *Server) {
+	s.HandleFunc("GET /healthz", h.handleLiveness)
+	s.HandleFunc("GET /readyz", h.handleReadiness)
+}
+
+// handleLiveness 存活檢查：程序可回應即視為存活
+func (h *HealthHandler) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &HealthReport{
+		Status: HealthOK,
+		Uptime: time.Since(h.startedAt).Round(time.Second).String(),
+	})
+}
+
+// handleReadiness 就緒檢查：並行執行所有相依服務檢查
+func (h *HealthHandler) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	report := h.Check(r.Context())
+	status := http.StatusOK
+	if report.Status == HealthFail {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+// Check 並行執行所有檢查並彙整結果，也供 doctor 子命令使用
+func (h *HealthHandler) Check(ctx context.Context) *HealthReport {
+	report := &HealthReport{
+		Status: HealthOK,
+		Uptime: time.Since(h.startedAt).Round(time.Second).String(),
+		Checks: make(map[string]*CheckResult, len(h.checks)),
+	}
+
+	results := make([]*CheckResult, len(h.checks))
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			results[i] = h.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for i, check := range h.checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+		switch {
+		case result.Status == HealthFail:
+			report.Status = HealthFail
+		case result.Status == HealthDegraded && report.Status == HealthOK:
+			report.Status = HealthDegraded
+		}
+	}
+	return report
+}
+
+// run 執行單一檢查，在快取時間內直接回傳上次結果
+func (h *HealthHandler) run(ctx context.Context, check HealthCheck) *CheckResult {
+	if check.CacheFor > 0 {
+		h.mu.Lock()
+		cached, ok := h.cache[check.Name]
+		h.mu.Unlock()
+		if ok && time.Since(cached.CheckedAt) < check.CacheFor {
+			return cached
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check.Run(ctx)
+	result := &CheckResult{
+		Status:    HealthOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Details:   details,
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		result.Error = err.Error()
+		result.Status = HealthFail
+		if check.Degradable {
+			result.Status = HealthDegraded
+		}
+	}
+
+	if check.CacheFor > 0 {
+		h.mu.Lock()
+		h.cache[check.Name] = result
+		h.mu.Unlock()
+	}
+	return result
+}
+
+// MongoHealthCheck MongoDB 連線檢查
+func MongoHealthCheck(client *database.MongoDBClient) HealthCheck {
+	return HealthCheck{
+		Name: "mongodb",
+		Run: func(ctx context.Context) (map[string]interface{}, error) {
+			info := client.GetConnectionInfo()
+			details := map[string]interface{}{
+				"database": info["database"],
+				"timeout":  info["timeout"],
+			}
+			return details, client.Ping(ctx)
+		},
+	}
+}
+
+// LogstashHealthCheck Logstash 連線檢查，失敗時僅視為 degraded
+func LogstashHealthCheck(logService *service.LogService) HealthCheck {
+	return HealthCheck{
+		Name:       "logstash",
+		Degradable: true,
+		CacheFor:   30 * time.Second,
+		Run: func(ctx context.Context) (map[string]interface{}, error) {
+			return nil, logService.Ping(ctx)
+		},
+	}
+}
+
+// SketchfabHealthCheck Sketchfab API 連線檢查
+func SketchfabHealthCheck(client *api.SketchfabClient) HealthCheck {
+	return HealthCheck{
+		Name:     "sketchfab_api",
+		CacheFor: time.Minute,
+		Run: func(ctx context.Context) (map[string]interface{}, error) {
+			return map[string]interface{}{"base_url": client.BaseURL}, client.Ping(ctx)
+		},
+	}
+}
+
+// ElasticsearchHealthCheck 搜尋索引連線檢查，失敗時僅視為 degraded（可執行 reindex 補齊）
+func ElasticsearchHealthCheck(index *search.Index) HealthCheck {
+	return HealthCheck{
+		Name:       "elasticsearch",
+		Degradable: true,
+		CacheFor:   30 * time.Second,
+		Run: func(ctx context.Context) (map[string]interface{}, error) {
+			return map[string]interface{}{"index": index.Alias()}, index.Ping(ctx)
+		},
+	}
+}
+
+// EventOutboxHealthCheck 模型事件 outbox 檢查，有事件超過最大嘗試次數仍未送達時視為 degraded
+func EventOutboxHealthCheck(outbox *service.OutboxService) HealthCheck {
+	return HealthCheck{
+		Name:       "event_outbox",
+		Degradable: true,
+		CacheFor:   30 * time.Second,
+		Run: func(ctx context.Context) (map[string]interface{}, error) {
+			counts, err := outbox.CountByStatus(ctx)
+			if err != nil {
+				return nil, err
+			}
+			details := map[string]interface{}{}
+			for status, count := range counts {
+				details[status] = count
+			}
+			if failed := counts[service.OutboxFailed]; failed > 0 {
+				return details, fmt.Errorf("%d 筆事件送出失敗", failed)
+			}
+			return details, nil
+		},
+	}
+}
+
+// SyncFreshnessHealthCheck 檢查最近一次成功同步距今的時間是否超過 maxAge
+//
+// 以 sync_runs 中最近一次狀態為 done 的執行紀錄為準，失敗的任務不會讓資料看起來是新的。
+// 服務啟動後 maxAge 內尚未有同步紀錄時視為正常，避免首次同步期間被判定為失敗。
+func SyncFreshnessHealthCheck(runsService *service.RunsService, maxAge time.Duration) HealthCheck {
+	startedAt := time.Now()
+	return HealthCheck{
+		Name: "last_sync",
+		Run: func(ctx context.Context) (map[string]interface{}, error) {
+			run, err := runsService.GetLastSuccessfulRun(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			details := map[string]interface{}{"max_age": maxAge.String()}
+			if run == nil {
+				details["last_sync_at"] = nil
+				if time.Since(startedAt) > maxAge {
+					return details, fmt.Errorf("啟動超過 %v 仍沒有任何同步紀錄", maxAge)
+				}
+				return details, nil
+			}
+
+			age := time.Since(run.FinishedAt)
+			details["last_sync_at"] = run.FinishedAt
+			details["run_id"] = run.RunID
+			details["job_name"] = run.JobName
+			details["age"] = age.Round(time.Second).String()
+			if age > maxAge {
+				return details, fmt.Errorf("最近一次同步已超過 %v", maxAge)
+			}
+			return details, nil
+		},
+	}
+}
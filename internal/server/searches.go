@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// SearchesHandler 儲存搜尋管理 API，使用與控制 API 相同的 token
+type SearchesHandler struct {
+	searches      *service.SavedSearchesService
+	modelsService *service.ModelsService
+	token         string
+}
+
+// NewSearchesHandler 建立新的儲存搜尋管理 API
+func NewSearchesHandler(searches *service.SavedSearchesService, modelsService *service.ModelsService, token string) *SearchesHandler {
+	return &SearchesHandler{searches: searches, modelsService: modelsService, token: token}
+}
+
+// Register 將儲存搜尋管理 API 路由註冊到伺服器
+func (h *SearchesHandler) Register(s *Server) {
+	routes := map[string]http.HandlerFunc{
+		"GET /searches":             h.handleList,
+		"POST /searches":            h.handleCreate,
+		"GET /searches/{id}":        h.handleGet,
+		"PUT /searches/{id}":        h.handleUpdate,
+		"DELETE /searches/{id}":     h.handleDelete,
+		"GET /searches/{id}/models": h.handleModels,
+	}
+	for pattern, handler := range routes {
+		s.Handle(pattern, bearerAuth(h.token, handler))
+	}
+}
+
+// handleList 列出所有儲存搜尋
+func (h *SearchesHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	searches, err := h.searches.ListSearches(r.Context(), false)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, searches)
+}
+
+// handleCreate 建立儲存搜尋
+func (h *SearchesHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	search := &service.SavedSearch{Active: true}
+	if !decodeSavedSearch(w, r, search) {
+		return
+	}
+	if err := h.searches.CreateSearch(r.Context(), search); err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, search)
+}
+
+// handleGet 取得單一儲存搜尋
+func (h *SearchesHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	search, err := h.searches.GetSearch(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, search)
+}
+
+// handleUpdate 以請求內容取代儲存搜尋的設定
+func (h *SearchesHandler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	search := &service.SavedSearch{Active: true}
+	if !decodeSavedSearch(w, r, search) {
+		return
+	}
+	search.ID = r.PathValue("id")
+	if err := h.searches.UpdateSearch(r.Context(), search); err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	h.handleGet(w, r)
+}
+
+// handleDelete 刪除儲存搜尋
+func (h *SearchesHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.searches.DeleteSearch(r.Context(), r.PathValue("id")); err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleModels 以儲存搜尋的條件查詢目前資料庫中的模型，可用 sort、limit、cursor 查詢參數分頁
+func (h *SearchesHandler) handleModels(w http.ResponseWriter, r *http.Request) {
+	search, err := h.searches.GetSearch(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeSavedSearchError(w, err)
+		return
+	}
+	paging, err := ParseModelFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filter := search.Criteria.ModelFilter()
+	filter.Sort, filter.Cursor, filter.Limit = paging.Sort, paging.Cursor, paging.Limit
+	page, err := h.modelsService.QueryModels(filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// decodeSavedSearch 解析請求內容，失敗時輸出 400 並回傳 false
+func decodeSavedSearch(w http.ResponseWriter, r *http.Request, search *service.SavedSearch) bool {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "無法讀取請求內容")
+		return false
+	}
+	if err := json.Unmarshal(body, search); err != nil {
+		writeError(w, http.StatusBadRequest, "無法解析儲存搜尋: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// writeSavedSearchError 依錯誤類型輸出 404、400 或 500
+func writeSavedSearchError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrSavedSearchNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidSavedSearch):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
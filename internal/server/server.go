@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// Server 內嵌的 HTTP 伺服器
+type Server struct {
+	httpServer *http.Server
+	mux        *http.ServeMux
+	logService *service.LogService
+}
+
+// NewServer 建立新的 HTTP 伺服器
+func NewServer(addr string, logService *service.LogService) *Server {
+	mux := http.NewServeMux()
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		mux:        mux,
+		logService: logService,
+	}
+}
+
+// Handle 註冊路由
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// HandleFunc 註冊路由處理函式
+func (s *Server) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// Start 監聽位址並在背景啟動伺服器，無法監聽（例如連接埠已被佔用）時直接回傳錯誤
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("HTTP 伺服器無法監聽 %s: %v", s.httpServer.Addr, err)
+	}
+	s.logService.Info("🌐 HTTP 伺服器已啟動", "addr", ln.Addr().String())
+	go func() {
+		if err := s.httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logService.Error("HTTP 伺服器錯誤", "error", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown 優雅關閉伺服器
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("關閉 HTTP 伺服器失敗: %v", err)
+	}
+	return nil
+}
+
+// writeJSON 輸出 JSON 回應
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// writeError 輸出 JSON 錯誤回應
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
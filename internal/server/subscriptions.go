@@ -0,0 +1,197 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+// maxDeliveryListLimit 投遞查詢單次回傳的上限
+const maxDeliveryListLimit = 500
+
+// SubscriptionsHandler webhook 訂閱管理 API，使用與控制 API 相同的 token
+type SubscriptionsHandler struct {
+	subscriptions *service.SubscriptionsService
+	token         string
+}
+
+// NewSubscriptionsHandler 建立新的訂閱管理 API
+func NewSubscriptionsHandler(subscriptions *service.SubscriptionsService, token string) *SubscriptionsHandler {
+	return &SubscriptionsHandler{subscriptions: subscriptions, token: token}
+}
+
+// Register 將訂閱管理 API 路由註冊到伺服器
+func (h *SubscriptionsHandler) Register(s *Server) {
+	routes := map[string]http.HandlerFunc{
+		"GET /subscriptions":                                       h.handleList,
+		"POST /subscriptions":                                      h.handleCreate,
+		"GET /subscriptions/{id}":                                  h.handleGet,
+		"PUT /subscriptions/{id}":                                  h.handleUpdate,
+		"DELETE /subscriptions/{id}":                               h.handleDelete,
+		"GET /subscriptions/{id}/deliveries":                       h.handleListDeliveries,
+		"GET /subscriptions/{id}/deliveries/{delivery}/attempts":   h.handleListAttempts,
+		"POST /subscriptions/{id}/deliveries/{delivery}/redeliver": h.handleRedeliver,
+	}
+	for pattern, handler := range routes {
+		s.Handle(pattern, bearerAuth(h.token, handler))
+	}
+}
+
+// handleList 列出所有訂閱（不含簽章金鑰）
+func (h *SubscriptionsHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := h.subscriptions.ListSubscriptions(r.Context(), false)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+	writeJSON(w, http.StatusOK, subscriptions)
+}
+
+// handleCreate 建立訂閱，未提供 secret 時自動產生；回應中包含 secret，之後不會再顯示
+func (h *SubscriptionsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	subscription := &service.Subscription{Active: true}
+	if !decodeSubscription(w, r, subscription) {
+		return
+	}
+	if err := h.subscriptions.CreateSubscription(r.Context(), subscription); err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, subscription)
+}
+
+// handleGet 取得單一訂閱（不含簽章金鑰）
+func (h *SubscriptionsHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	subscription, err := h.subscriptions.GetSubscription(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	subscription.Secret = ""
+	writeJSON(w, http.StatusOK, subscription)
+}
+
+// handleUpdate 以請求內容取代訂閱設定，未提供 secret 時保留原本的金鑰
+func (h *SubscriptionsHandler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	subscription := &service.Subscription{Active: true}
+	if !decodeSubscription(w, r, subscription) {
+		return
+	}
+	subscription.ID = r.PathValue("id")
+	if err := h.subscriptions.UpdateSubscription(r.Context(), subscription); err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	h.handleGet(w, r)
+}
+
+// handleDelete 刪除訂閱與尚未送出的投遞
+func (h *SubscriptionsHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.subscriptions.DeleteSubscription(r.Context(), r.PathValue("id")); err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListDeliveries 列出訂閱的投遞，可用 status（pending / delivered / dead）與 limit 查詢參數篩選
+func (h *SubscriptionsHandler) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &service.DeliveryFilter{
+		SubscriptionID: r.PathValue("id"),
+		Status:         query.Get("status"),
+		Limit:          100,
+	}
+	switch filter.Status {
+	case "", service.DeliveryPending, service.DeliveryDelivered, service.DeliveryDead:
+	default:
+		writeError(w, http.StatusBadRequest, "無效的 status: "+filter.Status)
+		return
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > maxDeliveryListLimit {
+			writeError(w, http.StatusBadRequest, "limit 必須介於 1 到 "+strconv.Itoa(maxDeliveryListLimit))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	deliveries, err := h.subscriptions.ListDeliveries(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// handleListAttempts 列出投遞紀錄中一個投遞的所有嘗試
+func (h *SubscriptionsHandler) handleListAttempts(w http.ResponseWriter, r *http.Request) {
+	deliveryID, ok := deliveryPathValue(w, r)
+	if !ok {
+		return
+	}
+	attempts, err := h.subscriptions.ListAttempts(r.Context(), deliveryID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, attempts)
+}
+
+// handleRedeliver 將 dead letter 重新排入佇列
+func (h *SubscriptionsHandler) handleRedeliver(w http.ResponseWriter, r *http.Request) {
+	deliveryID, ok := deliveryPathValue(w, r)
+	if !ok {
+		return
+	}
+	if err := h.subscriptions.Redeliver(r.Context(), deliveryID); err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// deliveryPathValue 取得路徑中的投遞 ID，投遞不屬於路徑中的訂閱時輸出 404 並回傳 false
+func deliveryPathValue(w http.ResponseWriter, r *http.Request) (string, bool) {
+	deliveryID := r.PathValue("delivery")
+	if !strings.HasPrefix(deliveryID, r.PathValue("id")+":") {
+		writeError(w, http.StatusNotFound, "找不到投遞: "+deliveryID)
+		return "", false
+	}
+	return deliveryID, true
+}
+
+// decodeSubscription 解析請求內容，失敗時輸出 400 並回傳 false
+func decodeSubscription(w http.ResponseWriter, r *http.Request, subscription *service.Subscription) bool {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "無法讀取請求內容")
+		return false
+	}
+	if err := json.Unmarshal(body, subscription); err != nil {
+		writeError(w, http.StatusBadRequest, "無法解析訂閱: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// writeSubscriptionError 依錯誤類型輸出 404、400 或 500
+func writeSubscriptionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrSubscriptionNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidSubscription):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
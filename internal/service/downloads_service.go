@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fetch-sketchfab-data/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 檔案封存下載狀態
+const (
+	DownloadInProgress = "downloading" // 下載中，或中斷後留下可續傳的部分檔案
+	DownloadCompleted  = "completed"
+	DownloadFailed     = "failed"
+	DownloadSkipped    = "skipped" // 沒有此格式或超過大小上限
+)
+
+// ArchiveDownload 一個模型單一格式的下載狀態
+type ArchiveDownload struct {
+	Status      string     `bson:"status" json:"status"`
+	Path        string     `bson:"path,omitempty" json:"path,omitempty"` // 相對於下載目錄的檔案路徑
+	Size        int64      `bson:"size" json:"size"`                     // 預期大小（Archive.Size）
+	Bytes       int64      `bson:"bytes" json:"bytes"`                   // 已寫入的大小，中斷時為部分檔案的大小
+	Attempts    int        `bson:"attempts" json:"attempts"`
+	Error       string     `bson:"error,omitempty" json:"error,omitempty"`
+	UpdatedAt   time.Time  `bson:"updated_at" json:"updated_at"`
+	CompletedAt *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+}
+
+// ModelDownload 一個模型各格式的下載狀態
+type ModelDownload struct {
+	ID        string                      `bson:"_id" json:"id"`
+	Name      string                      `bson:"name" json:"name"`
+	Archives  map[string]*ArchiveDownload `bson:"archives" json:"archives"` // 格式名稱 → 下載狀態
+	UpdatedAt time.Time                   `bson:"updated_at" json:"updated_at"`
+}
+
+// DownloadsService 記錄模型檔案封存的下載狀態
+type DownloadsService struct {
+	collection *mongo.Collection
+}
+
+// NewDownloadsService 建立新的下載狀態服務
+func NewDownloadsService(client *database.MongoDBClient) *DownloadsService {
+	return &DownloadsService{collection: client.GetCollection("downloads")}
+}
+
+// EnsureIndexes 建立依更新時間列出下載紀錄的索引
+func (s *DownloadsService) EnsureIndexes(ctx context.Context) ([]string, error) {
+	names, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "updated_at", Value: -1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("建立下載紀錄索引失敗: %v", err)
+	}
+	return names, nil
+}
+
+// GetDownload 取得模型的下載狀態，沒有紀錄時回傳 nil
+func (s *DownloadsService) GetDownload(ctx context.Context, modelID string) (*ModelDownload, error) {
+	var download ModelDownload
+	opCtx, end := startOp(ctx, s.collection, "find_one")
+	err := s.collection.FindOne(opCtx, bson.M{"_id": modelID}).Decode(&download)
+	end(ignoreNoDocuments(err))
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查詢下載紀錄失敗: %v", err)
+	}
+	return &download, nil
+}
+
+// SaveArchive 寫入模型單一格式的下載狀態，不影響其他格式
+func (s *DownloadsService) SaveArchive(ctx context.Context, modelID, name, format string, archive *ArchiveDownload) error {
+	now := time.Now()
+	archive.UpdatedAt = now
+	update := bson.M{"$set": bson.M{
+		"name":               name,
+		"updated_at":         now,
+		"archives." + format: archive,
+	}}
+
+	opCtx, end := startOp(ctx, s.collection, "update_one")
+	_, err := s.collection.UpdateOne(opCtx, bson.M{"_id": modelID}, update, options.Update().SetUpsert(true))
+	end(err)
+	if err != nil {
+		return fmt.Errorf("寫入下載紀錄失敗: %v", err)
+	}
+	return nil
+}
+
+// CountByStatus 依格式與狀態統計下載紀錄
+func (s *DownloadsService) CountByStatus(ctx context.Context) (map[string]map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$project", Value: bson.M{"archives": bson.M{"$objectToArray": "$archives"}}}},
+		{{Key: "$unwind", Value: "$archives"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"format": "$archives.k", "status": "$archives.v.status"},
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+	opCtx, end := startOp(ctx, s.collection, "aggregate")
+	cur, err := s.collection.Aggregate(opCtx, pipeline)
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("統計下載紀錄失敗: %v", err)
+	}
+	var rows []struct {
+		ID struct {
+			Format string `bson:"format"`
+			Status string `bson:"status"`
+		} `bson:"_id"`
+		Count int64 `bson:"count"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("統計下載紀錄失敗: %v", err)
+	}
+	counts := map[string]map[string]int64{}
+	for _, row := range rows {
+		if counts[row.ID.Format] == nil {
+			counts[row.ID.Format] = map[string]int64{}
+		}
+		counts[row.ID.Format][row.ID.Status] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"maps"
+	"time"
+)
+
+// 模型變更事件類型
+const (
+	EventModelCreated = "model.created"
+	EventModelUpdated = "model.updated"
+	EventModelRemoved = "model.removed"
+)
+
+// ModelEvent 模型變更事件，寫入 outbox 後由 dispatcher 送到 publisher
+type ModelEvent struct {
+	ID            string                 `bson:"_id" json:"id"`
+	Type          string                 `bson:"type" json:"type"`
+	ModelID       string                 `bson:"model_id" json:"model_id"`
+	OccurredAt    time.Time              `bson:"occurred_at" json:"occurred_at"`
+	ChangedFields []string               `bson:"changed_fields,omitempty" json:"changed_fields,omitempty"` // model.updated 變更的欄位
+	Previous      map[string]interface{} `bson:"previous,omitempty" json:"previous,omitempty"`             // model.updated 變更欄位的舊值
+	Model         *SketchfabModel        `bson:"model,omitempty" json:"model,omitempty"`                   // 變更後的模型，model.removed 時為移除前的模型
+	Reason        string                 `bson:"reason,omitempty" json:"reason,omitempty"`                 // model.removed 的移除原因
+}
+
+// newEventID 產生事件識別碼，消費端可用於去除重複
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// newModelEvent 建立模型事件
+func newModelEvent(eventType, modelID string, occurredAt time.Time) *ModelEvent {
+	return &ModelEvent{
+		ID:         newEventID(),
+		Type:       eventType,
+		ModelID:    modelID,
+		OccurredAt: occurredAt,
+	}
+}
+
+// changedFields 比較模型的關鍵欄位，回傳變更的欄位名稱與舊值
+func changedFields(existing, new *SketchfabModel) ([]string, map[string]interface{}) {
+	var fields []string
+	previous := map[string]interface{}{}
+	add := func(field string, changed bool, old interface{}) {
+		if changed {
+			fields = append(fields, field)
+			previous[field] = old
+		}
+	}
+
+	add("name", existing.Name != new.Name, existing.Name)
+	add("description", existing.Description != new.Description, existing.Description)
+	add("view_count", existing.ViewCount != new.ViewCount, existing.ViewCount)
+	add("like_count", existing.LikeCount != new.LikeCount, existing.LikeCount)
+	add("is_downloadable", existing.IsDownloadable != new.IsDownloadable, existing.IsDownloadable)
+	add("updated_at", !existing.UpdatedAt.Equal(new.UpdatedAt), existing.UpdatedAt)
+	// 標籤與分類不論順序，比較 slug 與名稱的集合
+	add("tags", !sameSet(existing.TagNames(), new.TagNames()), existing.Tags)
+	add("categories", !sameSet(existing.CategoryNames(), new.CategoryNames()), existing.Categories)
+	add("license", existing.LicenseString("uid") != new.LicenseString("uid"), existing.License)
+	add("user", existing.UserString("uid") != new.UserString("uid"), existing.User)
+	oldSizes := archiveSizes(existing)
+	add("archives", !maps.Equal(oldSizes, archiveSizes(new)), oldSizes)
+	return fields, previous
+}
+
+// sameSet 兩組字串去除重複後是否相同
+func sameSet(a, b []string) bool {
+	set := make(map[string]bool, len(a))
+	for _, v := range a {
+		set[v] = true
+	}
+	other := make(map[string]bool, len(b))
+	for _, v := range b {
+		if !set[v] {
+			return false
+		}
+		other[v] = true
+	}
+	return len(set) == len(other)
+}
+
+// archiveSizes 取得各格式封存的檔案大小
+func archiveSizes(m *SketchfabModel) map[string]int {
+	sizes := map[string]int{}
+	for _, archive := range m.ArchiveList() {
+		sizes[archive.Format] = archive.Size
+	}
+	return sizes
+}
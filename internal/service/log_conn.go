@@ -0,0 +1,139 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// errReconnectBackoff 尚在退避期間，暫不重新連線
+var errReconnectBackoff = errors.New("等待重新連線")
+
+// 網路 sink 預設的重新連線退避時間
+const (
+	defaultReconnectBase = time.Second
+	defaultReconnectMax  = time.Minute
+)
+
+// logConnection 管理日誌 sink 的網路連線（TCP 或 UDP）
+//
+// 連線中斷後以指數退避重新連線，避免 Logstash 離線時每次寫入都重新撥號。
+// 所有方法皆可並行呼叫。
+type logConnection struct {
+	network      string
+	addr         string
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+
+	mu          sync.Mutex
+	conn        net.Conn
+	failures    int       // 連續失敗次數
+	nextAttempt time.Time // 退避期間結束的時間
+}
+
+// newLogConnection 建立連線管理器，實際連線在第一次寫入時建立
+func newLogConnection(network, addr string, baseDelay, maxDelay time.Duration) *logConnection {
+	if baseDelay <= 0 {
+		baseDelay = defaultReconnectBase
+	}
+	if maxDelay < baseDelay {
+		maxDelay = max(defaultReconnectMax, baseDelay)
+	}
+	return &logConnection{
+		network:      network,
+		addr:         addr,
+		dialTimeout:  5 * time.Second,
+		writeTimeout: 10 * time.Second,
+		baseDelay:    baseDelay,
+		maxDelay:     maxDelay,
+	}
+}
+
+// Write 實作 io.Writer，必要時先重新連線；寫入失敗會關閉連線並進入退避
+func (c *logConnection) Write(data []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.ensureConnected(); err != nil {
+		return 0, err
+	}
+
+	c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	n, err := c.conn.Write(data)
+	if err != nil {
+		c.conn.Close()
+		c.conn = nil
+		c.backoff()
+		return n, fmt.Errorf("發送日誌到 %s 失敗: %v", c.addr, err)
+	}
+	return n, nil
+}
+
+// ensureConnected 確保有可用的連線，呼叫端需持有 mu
+func (c *logConnection) ensureConnected() error {
+	if c.conn != nil {
+		return nil
+	}
+	if time.Now().Before(c.nextAttempt) {
+		return errReconnectBackoff
+	}
+
+	conn, err := net.DialTimeout(c.network, c.addr, c.dialTimeout)
+	if err != nil {
+		c.backoff()
+		return fmt.Errorf("連接到 %s 失敗: %v", c.addr, err)
+	}
+
+	if c.failures > 0 {
+		fmt.Fprintf(os.Stderr, "[INFO] 已重新連線到 %s://%s（先前失敗 %d 次）\n", c.network, c.addr, c.failures)
+	}
+	c.conn = conn
+	c.failures = 0
+	c.nextAttempt = time.Time{}
+	return nil
+}
+
+// backoff 記錄一次失敗並計算下次可重新連線的時間，呼叫端需持有 mu
+func (c *logConnection) backoff() {
+	c.failures++
+
+	delay := c.baseDelay
+	for i := 1; i < c.failures && delay < c.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > c.maxDelay {
+		delay = c.maxDelay
+	}
+	// 加入最多 20% 的隨機抖動，避免多個實例同時重連
+	delay += time.Duration(rand.Int63n(int64(delay)/5 + 1))
+
+	c.nextAttempt = time.Now().Add(delay)
+	if c.failures == 1 {
+		fmt.Fprintf(os.Stderr, "[WARN] %s://%s 連線中斷，將以指數退避於背景重新連線\n", c.network, c.addr)
+	}
+}
+
+// available 是否可嘗試寫入（已連線或退避期間已結束）
+func (c *logConnection) available() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn != nil || !time.Now().Before(c.nextAttempt)
+}
+
+// close 關閉連線
+func (c *logConnection) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// logServer 測試用的 Logstash：接受 TCP 連線並逐行讀取日誌
+type logServer struct {
+	t      *testing.T
+	addr   string
+	marker string        // 收到含有此字串的日誌時關閉 seen
+	seen   chan struct{} // 收到 marker 後關閉
+	once   sync.Once
+
+	mu    sync.Mutex
+	ln    net.Listener
+	conns []net.Conn
+}
+
+// startLogServer 在 127.0.0.1 的隨機埠開始接受連線
+func startLogServer(t *testing.T, marker string) *logServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("建立測試伺服器失敗: %v", err)
+	}
+	s := &logServer{t: t, addr: ln.Addr().String(), marker: marker, seen: make(chan struct{})}
+	s.serve(ln)
+	t.Cleanup(s.stop)
+	return s
+}
+
+// serve 接受連線直到 ln 被關閉
+func (s *logServer) serve(ln net.Listener) {
+	s.mu.Lock()
+	s.ln = ln
+	s.mu.Unlock()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			if s.ln != ln {
+				// 接受連線的同時 listener 已被關閉
+				s.mu.Unlock()
+				conn.Close()
+				return
+			}
+			s.conns = append(s.conns, conn)
+			s.mu.Unlock()
+			go s.read(conn)
+		}
+	}()
+}
+
+// read 逐行讀取日誌，遇到 marker 時通知測試
+func (s *logServer) read(conn net.Conn) {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		if strings.Contains(scanner.Text(), s.marker) {
+			s.once.Do(func() { close(s.seen) })
+		}
+	}
+}
+
+// connected 是否有已建立的連線
+func (s *logServer) connected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns) > 0
+}
+
+// stop 關閉 listener 與所有已建立的連線，模擬 Logstash 離線
+func (s *logServer) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ln != nil {
+		s.ln.Close()
+		s.ln = nil
+	}
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+// restart 在原本的位址重新開始接受連線
+func (s *logServer) restart() {
+	s.t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		ln, err := net.Listen("tcp", s.addr)
+		if err == nil {
+			s.serve(ln)
+			return
+		}
+		if time.Now().After(deadline) {
+			s.t.Fatalf("重新監聽 %s 失敗: %v", s.addr, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestLogConnectionReconnectsUnderConcurrentLogging 多個 goroutine 同時寫日誌時 Logstash 離線再恢復，
+// 寫日誌的呼叫不可阻塞或 panic，恢復後 shipper 必須重新連線並送出新的日誌
+func TestLogConnectionReconnectsUnderConcurrentLogging(t *testing.T) {
+	const marker = "after-restart"
+	server := startLogServer(t, marker)
+
+	host, port, err := net.SplitHostPort(server.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink, err := NewTCPSink(host, port, TCPSinkOptions{
+		SpoolPath:     filepath.Join(t.TempDir(), "spool.jsonl"),
+		SpoolMaxBytes: 1 << 20,
+		ReconnectBase: 10 * time.Millisecond,
+		ReconnectMax:  50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("建立 TCP sink 失敗: %v", err)
+	}
+	logService := NewLogServiceWithSinks("test", ShipperOptions{
+		QueueSize:     256,
+		BatchSize:     32,
+		FlushInterval: 10 * time.Millisecond,
+		DropPolicy:    DropNewest,
+		CloseTimeout:  2 * time.Second,
+	}, sink)
+
+	const workers = 16
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			child := logService.With("worker", w)
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				logService.Info("並行寫入", "i", i)
+				child.With("i", i).Error("子日誌器寫入", "detail", map[string]interface{}{"attempt": i})
+				time.Sleep(200 * time.Microsecond)
+			}
+		}(w)
+	}
+
+	// 寫入途中關閉 Logstash，確認 shipper 發現斷線後在相同位址恢復
+	waitFor(t, "shipper 沒有連線到 Logstash", server.connected)
+	time.Sleep(50 * time.Millisecond)
+	server.stop()
+	waitFor(t, "shipper 沒有發現 Logstash 已離線", func() bool {
+		sink.conn.mu.Lock()
+		defer sink.conn.mu.Unlock()
+		return sink.conn.failures > 0
+	})
+	time.Sleep(100 * time.Millisecond)
+	server.restart()
+	time.Sleep(100 * time.Millisecond)
+	close(stop)
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Logstash 離線期間寫日誌的呼叫被阻塞")
+	}
+
+	// 持續寫入直到伺服器收到恢復後的日誌
+	deadline := time.After(10 * time.Second)
+	for i := 0; ; i++ {
+		logService.Info(fmt.Sprintf("%s %d", marker, i))
+		select {
+		case <-server.seen:
+		case <-deadline:
+			t.Fatal("Logstash 恢復後 shipper 沒有重新連線")
+		case <-time.After(20 * time.Millisecond):
+			continue
+		}
+		break
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		logService.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("關閉日誌服務被阻塞")
+	}
+}
+
+// waitFor 等待 cond 成立，逾時則以 message 失敗
+func waitFor(t *testing.T, message string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(message)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
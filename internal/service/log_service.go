@@ -1,166 +1,328 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net"
+	"log/slog"
+	"strings"
 	"time"
-)
 
-// LogEntry 日誌條目結構
-type LogEntry struct {
-	Timestamp string                 `json:"timestamp"`
-	Level     string                 `json:"level"`
-	Message   string                 `json:"message"`
-	Service   string                 `json:"service"`
-	Type      string                 `json:"type"`
-	Data      map[string]interface{} `json:"data,omitempty"`
-}
+	"fetch-sketchfab-data/internal/secrets"
+	"fetch-sketchfab-data/internal/tracing"
+)
 
-// LogService 日誌服務
+// LogService 日誌服務，實作 slog.Handler
+//
+// 每筆日誌會轉成 JSON 欄位後交給一或多個 LogSink（預設為 Logstash TCP），屬性（attrs）會成為頂層欄位，
+// 群組（group）則成為巢狀物件。以 With 建立的子日誌器共用同一組 sink 與日誌等級。
+// 所有方法皆可並行呼叫：綁定的欄位建立後不再修改，連線則由背景發送器統一管理。
 type LogService struct {
-	conn    net.Conn
-	host    string
-	port    string
-	service string
+	transport *logTransport
+	attrs     map[string]interface{} // 已綁定的欄位
+	groups    []string               // 目前所在的群組路徑
 }
 
-// NewLogService 建立新的日誌服務
+// NewLogService 以預設設定建立發送到 Logstash TCP 的日誌服務
 func NewLogService(host, port, service string) *LogService {
+	sink, _ := NewTCPSink(host, port, DefaultTCPSinkOptions())
+	return NewLogServiceWithSinks(service, DefaultShipperOptions(), sink)
+}
+
+// NewLogServiceWithSinks 建立新的日誌服務，日誌會經由背景佇列批次交給每個 sink
+func NewLogServiceWithSinks(service string, opts ShipperOptions, sinks ...LogSink) *LogService {
 	return &LogService{
-		host:    host,
-		port:    port,
-		service: service,
+		transport: newLogTransport(service, opts, sinks),
+		attrs:     map[string]interface{}{},
 	}
 }
 
-// Connect 連接到 Logstash
-func (ls *LogService) Connect() error {
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%s", ls.host, ls.port), 5*time.Second)
-	if err != nil {
-		return fmt.Errorf("連接到 Logstash 失敗: %v", err)
+// ParseLevel 解析日誌等級字串（DEBUG、INFO、WARN、ERROR）
+func ParseLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.ToUpper(level))); err != nil {
+		return l, fmt.Errorf("無效的日誌等級: %s", level)
 	}
-	ls.conn = conn
-	return nil
+	return l, nil
 }
 
-// Close 關閉連接
-func (ls *LogService) Close() error {
-	if ls.conn != nil {
-		return ls.conn.Close()
+// SetLevel 設定最低輸出等級，對所有子日誌器生效
+func (ls *LogService) SetLevel(level slog.Level) {
+	ls.transport.level.Set(level)
+}
+
+// Level 取得目前的最低輸出等級
+func (ls *LogService) Level() slog.Level {
+	return ls.transport.level.Level()
+}
+
+// Logger 以此服務作為 handler 建立 slog.Logger
+func (ls *LogService) Logger() *slog.Logger {
+	return slog.New(ls)
+}
+
+// With 建立綁定欄位的子日誌器，args 格式同 slog（key, value 交錯或 slog.Attr）
+func (ls *LogService) With(args ...any) *LogService {
+	r := slog.NewRecord(time.Time{}, 0, "", 0)
+	r.Add(args...)
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return ls.WithAttrs(attrs).(*LogService)
+}
+
+// WithTrace 回傳綁定 ctx 中 trace_id 與 span_id 的子日誌器，ctx 沒有有效 span 時回傳原日誌器
+func (ls *LogService) WithTrace(ctx context.Context) *LogService {
+	traceID, spanID := tracing.IDs(ctx)
+	if traceID == "" {
+		return ls
 	}
-	return nil
+	return ls.With("trace_id", traceID, "span_id", spanID)
 }
 
-// Log 發送日誌到 Logstash
-func (ls *LogService) Log(level, message string) error {
-	if ls.conn == nil {
-		// 如果沒有連接，嘗試重新連接
-		if err := ls.Connect(); err != nil {
-			// 如果連接失敗，只輸出到標準輸出
-			fmt.Printf("[%s] %s: %s\n", level, ls.service, message)
-			return nil
-		}
+// Enabled 實作 slog.Handler
+func (ls *LogService) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= ls.transport.level.Level()
+}
+
+// WithAttrs 實作 slog.Handler
+func (ls *LogService) WithAttrs(attrs []slog.Attr) slog.Handler {
+	child := ls.clone()
+	target := child.groupMap(child.attrs)
+	for _, a := range attrs {
+		addAttr(target, a)
+	}
+	return child
+}
+
+// WithGroup 實作 slog.Handler
+func (ls *LogService) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return ls
 	}
+	child := ls.clone()
+	child.groups = append(child.groups, name)
+	return child
+}
 
-	logEntry := LogEntry{
-		Timestamp: time.Now().Format(time.RFC3339),
-		Level:     level,
-		Message:   message,
-		Service:   ls.service,
-		Type:      "sketchfab",
+// Handle 實作 slog.Handler，將日誌發送到 Logstash
+func (ls *LogService) Handle(ctx context.Context, r slog.Record) error {
+	fields := cloneMap(ls.attrs)
+	// 透過 InfoContext 等帶入的 span 優先於 WithTrace 綁定的 ID
+	if traceID, spanID := tracing.IDs(ctx); traceID != "" {
+		fields["trace_id"] = traceID
+		fields["span_id"] = spanID
 	}
+	target := ls.groupMap(fields)
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(target, a)
+		return true
+	})
 
-	data, err := json.Marshal(logEntry)
-	if err != nil {
-		return fmt.Errorf("序列化日誌失敗: %v", err)
+	timestamp := r.Time
+	if timestamp.IsZero() {
+		timestamp = time.Now()
 	}
+	fields["timestamp"] = timestamp.Format(time.RFC3339)
+	fields["level"] = r.Level.String()
+	fields["message"] = r.Message
+	fields["service"] = ls.transport.service
+	fields["type"] = "sketchfab"
 
-	// 發送到 Logstash
-	_, err = ls.conn.Write(append(data, '\n'))
-	if err != nil {
-		// 如果發送失敗，關閉連接並輸出到標準輸出
-		ls.conn.Close()
-		ls.conn = nil
-		fmt.Printf("[%s] %s: %s\n", level, ls.service, message)
-		return nil
+	// 遮蔽已登錄的機密值（API key、密碼、token），避免經由錯誤訊息等欄位外洩
+	if secrets.Active() {
+		r.Message = secrets.Scrub(r.Message)
+		fields = scrubFields(fields)
 	}
 
+	r.Time = timestamp
+	return ls.transport.send(r, fields)
+}
+
+// Ping 測試可檢查連線的 sink（例如 Logstash TCP）是否可連線，不影響目前的連接
+func (ls *LogService) Ping(ctx context.Context) error {
+	for _, sink := range ls.transport.sinks {
+		if p, ok := sink.(logSinkPinger); ok {
+			if err := p.Ping(ctx); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
+// Close 停止接收日誌，送出佇列中剩餘的日誌後關閉所有 sink
+func (ls *LogService) Close() error {
+	return ls.transport.close()
+}
+
+// DroppedCount 取得因佇列已滿而丟棄的日誌筆數
+func (ls *LogService) DroppedCount() uint64 {
+	return ls.transport.dropped.Load()
+}
+
+// Log 以指定等級發送日誌
+func (ls *LogService) Log(level slog.Level, message string, args ...any) {
+	ctx := context.Background()
+	if !ls.Enabled(ctx, level) {
+		return
+	}
+	r := slog.NewRecord(time.Now(), level, message, 0)
+	r.Add(args...)
+	ls.Handle(ctx, r)
+}
+
 // Info 發送 INFO 級別日誌
-func (ls *LogService) Info(message string) error {
-	return ls.Log("INFO", message)
+func (ls *LogService) Info(message string, args ...any) {
+	ls.Log(slog.LevelInfo, message, args...)
 }
 
 // Error 發送 ERROR 級別日誌
-func (ls *LogService) Error(message string) error {
-	return ls.Log("ERROR", message)
+func (ls *LogService) Error(message string, args ...any) {
+	ls.Log(slog.LevelError, message, args...)
 }
 
 // Warn 發送 WARN 級別日誌
-func (ls *LogService) Warn(message string) error {
-	return ls.Log("WARN", message)
+func (ls *LogService) Warn(message string, args ...any) {
+	ls.Log(slog.LevelWarn, message, args...)
 }
 
 // Debug 發送 DEBUG 級別日誌
-func (ls *LogService) Debug(message string) error {
-	return ls.Log("DEBUG", message)
-}
-
-// LogWithData 發送包含資料的日誌
-func (ls *LogService) LogWithData(level, message string, data map[string]interface{}) error {
-	if ls.conn == nil {
-		// 如果沒有連接，嘗試重新連接
-		if err := ls.Connect(); err != nil {
-			// 如果連接失敗，只輸出到標準輸出
-			fmt.Printf("[%s] %s: %s\n", level, ls.service, message)
-			return nil
-		}
+func (ls *LogService) Debug(message string, args ...any) {
+	ls.Log(slog.LevelDebug, message, args...)
+}
+
+// LogWithData 發送包含資料的日誌，data 的每個鍵值都會成為頂層欄位
+func (ls *LogService) LogWithData(level slog.Level, message string, data map[string]interface{}) {
+	args := make([]any, 0, len(data))
+	for key, value := range data {
+		args = append(args, slog.Any(key, value))
 	}
+	ls.Log(level, message, args...)
+}
+
+// LogAPIData 記錄 API 資料
+func (ls *LogService) LogAPIData(message string, apiData interface{}) {
+	ls.Info(message, "data_type", "api_response", "api_data", apiData)
+}
+
+// LogModelData 記錄模型資料
+func (ls *LogService) LogModelData(message string, modelData interface{}) {
+	ls.Info(message, "data_type", "model_info", "model_data", modelData)
+}
 
-	logEntry := LogEntry{
-		Timestamp: time.Now().Format(time.RFC3339),
-		Level:     level,
-		Message:   message,
-		Service:   ls.service,
-		Type:      "sketchfab",
-		Data:      data,
+// clone 複製日誌器，子日誌器共用連線
+func (ls *LogService) clone() *LogService {
+	return &LogService{
+		transport: ls.transport,
+		attrs:     cloneMap(ls.attrs),
+		groups:    append([]string(nil), ls.groups...),
 	}
+}
 
-	dataBytes, err := json.Marshal(logEntry)
-	if err != nil {
-		return fmt.Errorf("序列化日誌失敗: %v", err)
+// groupMap 取得目前群組路徑對應的巢狀 map，不存在時建立
+func (ls *LogService) groupMap(root map[string]interface{}) map[string]interface{} {
+	target := root
+	for _, group := range ls.groups {
+		next, ok := target[group].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			target[group] = next
+		}
+		target = next
 	}
+	return target
+}
 
-	// 發送到 Logstash
-	_, err = ls.conn.Write(append(dataBytes, '\n'))
-	if err != nil {
-		// 如果發送失敗，關閉連接並輸出到標準輸出
-		ls.conn.Close()
-		ls.conn = nil
-		fmt.Printf("[%s] %s: %s\n", level, ls.service, message)
-		return nil
+// addAttr 將 slog 屬性轉換為 JSON 欄位
+func addAttr(target map[string]interface{}, a slog.Attr) {
+	value := a.Value.Resolve()
+	if a.Key == "" && value.Kind() != slog.KindGroup {
+		return
 	}
 
-	return nil
+	switch value.Kind() {
+	case slog.KindGroup:
+		group := target
+		if a.Key != "" {
+			existing, ok := target[a.Key].(map[string]interface{})
+			if !ok {
+				existing = map[string]interface{}{}
+				target[a.Key] = existing
+			}
+			group = existing
+		}
+		for _, ga := range value.Group() {
+			addAttr(group, ga)
+		}
+	case slog.KindTime:
+		target[a.Key] = value.Time().Format(time.RFC3339Nano)
+	case slog.KindDuration:
+		// 時間長度以毫秒數值輸出，方便在 Kibana 聚合
+		target[a.Key] = float64(value.Duration()) / float64(time.Millisecond)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			target[a.Key] = err.Error()
+		} else {
+			target[a.Key] = value.Any()
+		}
+	default:
+		target[a.Key] = value.Any()
+	}
 }
 
-// LogAPIData 記錄 API 資料
-func (ls *LogService) LogAPIData(message string, apiData interface{}) error {
-	data := map[string]interface{}{
-		"api_data":  apiData,
-		"data_type": "api_response",
+// scrubFields 回傳遮蔽所有字串值中機密值的副本，不修改 fields
+//
+// 欄位中的巢狀 map 可能是呼叫端傳入的值，直接修改會改動呼叫端的資料，也可能與其他 goroutine 競爭。
+func scrubFields(fields map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		out[key] = scrubValue(value)
 	}
-	return ls.LogWithData("INFO", message, data)
+	return out
 }
 
-// LogModelData 記錄模型資料
-func (ls *LogService) LogModelData(message string, modelData interface{}) error {
-	data := map[string]interface{}{
-		"model_data": modelData,
-		"data_type":  "model_info",
+// scrubValue 遮蔽單一欄位值，巢狀 map 與 slice 會遞迴處理，其他型別先轉為 JSON 檢查
+func scrubValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return secrets.Scrub(v)
+	case map[string]interface{}:
+		return scrubFields(v)
+	case []interface{}:
+		// 建立新的 slice，綁定在子日誌器上的值可能被其他 goroutine 同時讀取
+		out := make([]interface{}, len(v))
+		for i := range v {
+			out[i] = scrubValue(v[i])
+		}
+		return out
+	case nil, bool, int, int64, uint64, float64:
+		return v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return v
+		}
+		if scrubbed := secrets.Scrub(string(data)); scrubbed != string(data) {
+			var out interface{}
+			if json.Unmarshal([]byte(scrubbed), &out) == nil {
+				return out
+			}
+		}
+		return v
+	}
+}
+
+// cloneMap 深層複製欄位 map
+func cloneMap(src map[string]interface{}) map[string]interface{} {
+	dst := make(map[string]interface{}, len(src))
+	for key, value := range src {
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = cloneMap(nested)
+		}
+		dst[key] = value
 	}
-	return ls.LogWithData("INFO", message, data)
+	return dst
 }
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"fetch-sketchfab-data/internal/metrics"
+)
+
+// 佇列已滿時的處理方式
+const (
+	DropNewest = "drop_newest" // 丟棄新進的日誌
+	DropOldest = "drop_oldest" // 丟棄佇列中最舊的日誌
+	Block      = "block"       // 等待佇列有空間
+)
+
+// ShipperOptions 背景日誌發送設定
+type ShipperOptions struct {
+	QueueSize     int           // 佇列可容納的日誌筆數
+	BatchSize     int           // 單次寫入的最大筆數
+	FlushInterval time.Duration // 未滿一批時的最長等待時間
+	DropPolicy    string        // DropNewest、DropOldest 或 Block
+	CloseTimeout  time.Duration // Close 時等待佇列送完的最長時間
+}
+
+// DefaultShipperOptions 回傳預設的背景日誌發送設定
+func DefaultShipperOptions() ShipperOptions {
+	return ShipperOptions{
+		QueueSize:     1024,
+		BatchSize:     100,
+		FlushInterval: time.Second,
+		DropPolicy:    DropNewest,
+		CloseTimeout:  5 * time.Second,
+	}
+}
+
+// logTransport 子日誌器共用的背景發送器
+//
+// 呼叫端只負責序列化並放入有界佇列，實際的寫入都在單一背景 goroutine 中依序交給各個 sink，
+// 因此 Logstash 緩慢或無法連線時不會阻塞同步流程。
+type logTransport struct {
+	service string
+	level   *slog.LevelVar
+	opts    ShipperOptions
+	sinks   []LogSink
+
+	queue    chan *LogEntry
+	stopping chan struct{}
+	done     chan struct{}
+
+	mu        sync.RWMutex // 保護 closed 與關閉 queue 的時機
+	closed    bool
+	closeOnce sync.Once
+
+	dropped  atomic.Uint64
+	reported uint64          // 已回報的丟棄筆數，僅由背景 goroutine 存取
+	failing  map[string]bool // 寫入失敗中的 sink，僅由背景 goroutine 存取
+}
+
+// newLogTransport 建立並啟動背景發送器
+func newLogTransport(service string, opts ShipperOptions, sinks []LogSink) *logTransport {
+	defaults := DefaultShipperOptions()
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaults.QueueSize
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaults.BatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaults.FlushInterval
+	}
+	if opts.CloseTimeout <= 0 {
+		opts.CloseTimeout = defaults.CloseTimeout
+	}
+	switch opts.DropPolicy {
+	case DropNewest, DropOldest, Block:
+	default:
+		opts.DropPolicy = defaults.DropPolicy
+	}
+
+	t := &logTransport{
+		service:  service,
+		level:    new(slog.LevelVar),
+		opts:     opts,
+		sinks:    sinks,
+		queue:    make(chan *LogEntry, opts.QueueSize),
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+		failing:  map[string]bool{},
+	}
+	for _, sink := range sinks {
+		if d, ok := sink.(logSinkDropper); ok {
+			d.setDropHandler(t.drop)
+		}
+	}
+	go t.run()
+	return t
+}
+
+// send 序列化日誌並放入佇列
+func (t *logTransport) send(r slog.Record, fields map[string]interface{}) error {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("序列化日誌失敗: %v", err)
+	}
+	entry := &LogEntry{Time: r.Time, Level: r.Level, Message: r.Message, Fields: fields, JSON: data}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	// 關閉後的日誌直接輸出到標準錯誤
+	if t.closed {
+		printFallback(entry)
+		return nil
+	}
+
+	switch t.opts.DropPolicy {
+	case Block:
+		select {
+		case t.queue <- entry:
+		case <-t.stopping:
+			printFallback(entry)
+		}
+	case DropOldest:
+		select {
+		case t.queue <- entry:
+		default:
+			select {
+			case <-t.queue:
+				t.drop()
+			default:
+			}
+			select {
+			case t.queue <- entry:
+			default:
+				t.drop()
+			}
+		}
+	default:
+		select {
+		case t.queue <- entry:
+		default:
+			t.drop()
+		}
+	}
+	return nil
+}
+
+// drop 記錄一筆被丟棄的日誌
+func (t *logTransport) drop() {
+	t.dropped.Add(1)
+	metrics.LogsDropped.Inc()
+}
+
+// close 停止接收日誌，等待佇列送完（最多 CloseTimeout）後關閉所有 sink
+func (t *logTransport) close() error {
+	t.closeOnce.Do(func() {
+		close(t.stopping)
+
+		t.mu.Lock()
+		t.closed = true
+		close(t.queue)
+		t.mu.Unlock()
+
+		select {
+		case <-t.done:
+		case <-time.After(t.opts.CloseTimeout):
+			fmt.Fprintf(os.Stderr, "[WARN] %s: 關閉日誌服務逾時，仍有 %d 筆日誌未送出\n", t.service, len(t.queue))
+		}
+
+		if dropped := t.dropped.Load(); dropped > 0 {
+			fmt.Fprintf(os.Stderr, "[WARN] %s: 日誌佇列已滿，共丟棄 %d 筆日誌\n", t.service, dropped)
+		}
+	})
+	return nil
+}
+
+// run 背景 goroutine：批次取出佇列中的日誌並交給各個 sink
+func (t *logTransport) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*LogEntry, 0, t.opts.BatchSize)
+	for {
+		select {
+		case entry, ok := <-t.queue:
+			if !ok {
+				t.flush(batch)
+				for _, sink := range t.sinks {
+					if err := sink.Close(); err != nil {
+						fmt.Fprintf(os.Stderr, "[WARN] %s: 關閉日誌 sink %s 失敗: %v\n", t.service, sink.Name(), err)
+					}
+				}
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= t.opts.BatchSize {
+				t.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if report := t.dropReport(); report != nil {
+				batch = append(batch, report)
+			}
+			t.flush(batch)
+			batch = batch[:0]
+			// 即使沒有新日誌，也讓 sink 有機會重送先前暫存的資料
+			for _, sink := range t.sinks {
+				if f, ok := sink.(logSinkFlusher); ok {
+					if err := f.Flush(); err != nil {
+						t.report(sink, err)
+					}
+				}
+			}
+		}
+	}
+}
+
+// flush 將一批日誌依序交給每個 sink
+func (t *logTransport) flush(batch []*LogEntry) {
+	if len(batch) == 0 {
+		return
+	}
+	for _, sink := range t.sinks {
+		t.report(sink, sink.Write(batch))
+	}
+}
+
+// report 在 sink 開始失敗與恢復時各輸出一次提示，避免每批都輸出錯誤
+func (t *logTransport) report(sink LogSink, err error) {
+	name := sink.Name()
+	switch {
+	case err != nil && !t.failing[name]:
+		t.failing[name] = true
+		fmt.Fprintf(os.Stderr, "[WARN] %s: 日誌 sink %s 寫入失敗: %v\n", t.service, name, err)
+	case err == nil && t.failing[name]:
+		delete(t.failing, name)
+		fmt.Fprintf(os.Stderr, "[INFO] %s: 日誌 sink %s 已恢復\n", t.service, name)
+	}
+}
+
+// dropReport 若有新丟棄的日誌，產生一筆 WARN 日誌回報丟棄數量
+func (t *logTransport) dropReport() *LogEntry {
+	total := t.dropped.Load()
+	if total == t.reported {
+		return nil
+	}
+	count := total - t.reported
+	t.reported = total
+
+	now := time.Now()
+	message := "⚠️ 日誌佇列已滿，已丟棄部分日誌"
+	fields := map[string]interface{}{
+		"timestamp":     now.Format(time.RFC3339),
+		"level":         slog.LevelWarn.String(),
+		"message":       message,
+		"service":       t.service,
+		"type":          "sketchfab",
+		"dropped":       count,
+		"dropped_total": total,
+	}
+	data, _ := json.Marshal(fields)
+	return &LogEntry{Time: now, Level: slog.LevelWarn, Message: message, Fields: fields, JSON: data}
+}
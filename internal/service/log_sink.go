@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogEntry 一筆已格式化的日誌
+type LogEntry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Fields  map[string]interface{} // 完整欄位，含 timestamp、level、message、service、type
+	JSON    []byte                 // Fields 序列化後的 JSON（不含換行）
+}
+
+// LogSink 日誌輸出目的地
+//
+// Write 由背景發送 goroutine 依序呼叫，每次傳入一批日誌；
+// 若 sink 另外實作 Flush() error，會在每個 FlushInterval 被呼叫，可用來重送暫存的資料。
+type LogSink interface {
+	Name() string
+	Write(entries []*LogEntry) error
+	Close() error
+}
+
+// logSinkFlusher 需要定期處理暫存資料的 sink
+type logSinkFlusher interface {
+	Flush() error
+}
+
+// logSinkPinger 可檢查遠端是否可連線的 sink
+type logSinkPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// logSinkDropper 會自行丟棄日誌的 sink，丟棄數量計入 LogService.DroppedCount
+type logSinkDropper interface {
+	setDropHandler(drop func())
+}
+
+// standardFields 每筆日誌固定帶有的欄位，以文字格式輸出時不重複列出
+var standardFields = map[string]bool{
+	"timestamp": true,
+	"level":     true,
+	"message":   true,
+	"service":   true,
+	"type":      true,
+}
+
+// extraFields 取得排序後的非固定欄位名稱
+func extraFields(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if !standardFields[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatText 以 key=value 格式輸出日誌內容
+func formatText(entry *LogEntry) string {
+	var b strings.Builder
+	b.WriteString(entry.Message)
+	for _, key := range extraFields(entry.Fields) {
+		value := entry.Fields[key]
+		if nested, ok := value.(map[string]interface{}); ok {
+			data, _ := json.Marshal(nested)
+			value = string(data)
+		}
+		fmt.Fprintf(&b, " %s=%v", key, value)
+	}
+	return b.String()
+}
+
+// printFallback 無法交給 sink 時以 key=value 格式輸出到標準錯誤
+func printFallback(entry *LogEntry) {
+	fmt.Fprintf(os.Stderr, "[%s] %v: %s\n", entry.Level, entry.Fields["service"], formatText(entry))
+}
+
+// ConsoleSink 適合人閱讀的主控台輸出，輸出到終端機時以顏色區分等級
+type ConsoleSink struct {
+	mu    sync.Mutex
+	w     *bufio.Writer
+	color bool
+}
+
+// NewConsoleSink 建立主控台 sink，w 為終端機時自動啟用顏色
+func NewConsoleSink(w io.Writer) *ConsoleSink {
+	color := false
+	if f, ok := w.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			color = true
+		}
+	}
+	return &ConsoleSink{w: bufio.NewWriter(w), color: color}
+}
+
+// Name 實作 LogSink
+func (s *ConsoleSink) Name() string { return "console" }
+
+// Write 實作 LogSink
+func (s *ConsoleSink) Write(entries []*LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range entries {
+		level := fmt.Sprintf("%-5s", entry.Level.String())
+		if s.color {
+			level = levelColor(entry.Level) + level + "\033[0m"
+		}
+		fmt.Fprintf(s.w, "%s %s %s\n", entry.Time.Format("2006-01-02 15:04:05"), level, formatText(entry))
+	}
+	return s.w.Flush()
+}
+
+// Close 實作 LogSink
+func (s *ConsoleSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Flush()
+}
+
+// levelColor 取得日誌等級對應的 ANSI 顏色
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "\033[31m"
+	case level >= slog.LevelWarn:
+		return "\033[33m"
+	case level >= slog.LevelInfo:
+		return "\033[32m"
+	default:
+		return "\033[90m"
+	}
+}
+
+// JSONSink 以每行一筆 JSON 輸出，適合交給容器日誌收集器
+type JSONSink struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+// NewJSONSink 建立 JSON sink
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{w: bufio.NewWriter(w)}
+}
+
+// Name 實作 LogSink
+func (s *JSONSink) Name() string { return "json" }
+
+// Write 實作 LogSink
+func (s *JSONSink) Write(entries []*LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range entries {
+		s.w.Write(entry.JSON)
+		s.w.WriteByte('\n')
+	}
+	return s.w.Flush()
+}
+
+// Close 實作 LogSink
+func (s *JSONSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Flush()
+}
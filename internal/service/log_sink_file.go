@@ -0,0 +1,113 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileSink 以每行一筆 JSON 寫入本機檔案，超過大小上限時輪替
+//
+// 輪替時 app.log 會改名為 app.log.1，原本的 app.log.1 改為 app.log.2，依此類推，
+// 超過 maxBackups 的舊檔會被刪除。
+type FileSink struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink 建立檔案 sink，maxBytes 為 0 表示不輪替
+func NewFileSink(path string, maxBytes int64, maxBackups int) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("建立日誌目錄失敗: %v", err)
+	}
+	s := &FileSink{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Name 實作 LogSink
+func (s *FileSink) Name() string { return "file" }
+
+// Write 實作 LogSink
+func (s *FileSink) Write(entries []*LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := bufio.NewWriter(s.file)
+	for _, entry := range entries {
+		lineSize := int64(len(entry.JSON) + 1)
+		if s.maxBytes > 0 && s.size > 0 && s.size+lineSize > s.maxBytes {
+			if err := w.Flush(); err != nil {
+				return fmt.Errorf("寫入日誌檔失敗: %v", err)
+			}
+			if err := s.rotate(); err != nil {
+				return err
+			}
+			w = bufio.NewWriter(s.file)
+		}
+		w.Write(entry.JSON)
+		w.WriteByte('\n')
+		s.size += lineSize
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("寫入日誌檔失敗: %v", err)
+	}
+	return nil
+}
+
+// Close 實作 LogSink
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// open 開啟（或建立）目前的日誌檔，呼叫端需持有 mu
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("開啟日誌檔失敗: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("讀取日誌檔資訊失敗: %v", err)
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate 輪替日誌檔，呼叫端需持有 mu
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("關閉日誌檔失敗: %v", err)
+	}
+
+	if s.maxBackups > 0 {
+		os.Remove(s.backupPath(s.maxBackups))
+		for i := s.maxBackups - 1; i >= 1; i-- {
+			os.Rename(s.backupPath(i), s.backupPath(i+1))
+		}
+		if err := os.Rename(s.path, s.backupPath(1)); err != nil {
+			return fmt.Errorf("輪替日誌檔失敗: %v", err)
+		}
+	} else if err := os.Remove(s.path); err != nil {
+		return fmt.Errorf("輪替日誌檔失敗: %v", err)
+	}
+	return s.open()
+}
+
+// backupPath 取得第 n 個備份檔的路徑
+func (s *FileSink) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", s.path, n)
+}
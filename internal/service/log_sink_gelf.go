@@ -0,0 +1,130 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+)
+
+// GELF UDP 分塊設定
+const (
+	gelfMaxPacketSize = 8192
+	gelfChunkHeader   = 12
+	gelfMaxChunks     = 128
+)
+
+// gelfFieldName GELF 額外欄位名稱允許的字元
+var gelfFieldName = regexp.MustCompile(`[^\w.\-]`)
+
+// GELFSink 以 GELF 1.1 格式經 UDP 發送日誌（Graylog、Logstash gelf input）
+//
+// 超過單一封包大小的訊息會依 GELF 規範分塊，超過 128 塊的訊息會被丟棄。
+type GELFSink struct {
+	conn     *logConnection
+	hostname string
+}
+
+// NewGELFSink 建立 GELF sink，addr 格式為 host:port
+func NewGELFSink(addr string) *GELFSink {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return &GELFSink{conn: newLogConnection("udp", addr, 0, 0), hostname: hostname}
+}
+
+// Name 實作 LogSink
+func (s *GELFSink) Name() string { return "gelf" }
+
+// Write 實作 LogSink
+func (s *GELFSink) Write(entries []*LogEntry) error {
+	for _, entry := range entries {
+		data, err := json.Marshal(s.message(entry))
+		if err != nil {
+			return fmt.Errorf("序列化 GELF 訊息失敗: %v", err)
+		}
+		if err := s.send(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close 實作 LogSink
+func (s *GELFSink) Close() error {
+	return s.conn.close()
+}
+
+// message 將日誌轉換為 GELF 訊息，額外欄位加上底線前綴，巢狀物件轉為 JSON 字串
+func (s *GELFSink) message(entry *LogEntry) map[string]interface{} {
+	msg := map[string]interface{}{
+		"version":       "1.1",
+		"host":          s.hostname,
+		"short_message": entry.Message,
+		"timestamp":     float64(entry.Time.UnixMilli()) / 1000,
+		"level":         syslogSeverity(entry.Level),
+		"_service":      entry.Fields["service"],
+		"_type":         entry.Fields["type"],
+	}
+	for _, key := range extraFields(entry.Fields) {
+		name := "_" + gelfFieldName.ReplaceAllString(key, "_")
+		if name == "_id" {
+			name = "_id_"
+		}
+		switch value := entry.Fields[key].(type) {
+		case string, bool, int, int64, uint64, float64:
+			msg[name] = value
+		default:
+			data, _ := json.Marshal(value)
+			msg[name] = string(data)
+		}
+	}
+	return msg
+}
+
+// send 發送一則 GELF 訊息，必要時分塊
+func (s *GELFSink) send(data []byte) error {
+	if len(data) <= gelfMaxPacketSize {
+		_, err := s.conn.Write(data)
+		return err
+	}
+
+	chunkSize := gelfMaxPacketSize - gelfChunkHeader
+	count := (len(data) + chunkSize - 1) / chunkSize
+	if count > gelfMaxChunks {
+		return fmt.Errorf("GELF 訊息過大（%d bytes），已丟棄", len(data))
+	}
+
+	// 分塊標頭：magic(2) + message id(8) + 序號(1) + 總塊數(1)
+	var id [8]byte
+	rand.Read(id[:])
+	packet := make([]byte, 0, gelfMaxPacketSize)
+	for i := 0; i < count; i++ {
+		end := min((i+1)*chunkSize, len(data))
+		packet = append(packet[:0], 0x1e, 0x0f)
+		packet = append(packet, id[:]...)
+		packet = append(packet, byte(i), byte(count))
+		packet = append(packet, data[i*chunkSize:end]...)
+		if _, err := s.conn.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syslogSeverity 將 slog 等級轉換為 syslog 嚴重程度（GELF 與 RFC5424 共用）
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3 // error
+	case level >= slog.LevelWarn:
+		return 4 // warning
+	case level >= slog.LevelInfo:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
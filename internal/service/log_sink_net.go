@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// TCPSinkOptions TCP json_lines sink 設定
+type TCPSinkOptions struct {
+	SpoolPath     string        // 無法送達時的本機暫存檔，空字串表示不暫存
+	SpoolMaxBytes int64         // 暫存檔容量上限
+	ReconnectBase time.Duration // 連線中斷後第一次重新連線前的等待時間
+	ReconnectMax  time.Duration // 重新連線等待時間上限
+}
+
+// DefaultTCPSinkOptions 回傳預設的 TCP sink 設定
+func DefaultTCPSinkOptions() TCPSinkOptions {
+	return TCPSinkOptions{
+		SpoolMaxBytes: 50 << 20,
+		ReconnectBase: defaultReconnectBase,
+		ReconnectMax:  defaultReconnectMax,
+	}
+}
+
+// TCPSink 以 newline JSON（Logstash json_lines codec）經 TCP 發送日誌
+//
+// 無法送達時會輸出到標準錯誤並寫入本機暫存檔，連線恢復後依原順序重送。
+type TCPSink struct {
+	conn    *logConnection
+	spool   *logSpool // nil 表示不暫存
+	dropped func()    // 暫存檔已滿時的計數，由 LogService 設定
+}
+
+// NewTCPSink 建立 TCP sink，暫存檔無法開啟時回傳錯誤
+func NewTCPSink(host, port string, opts TCPSinkOptions) (*TCPSink, error) {
+	if opts.SpoolMaxBytes <= 0 {
+		opts.SpoolMaxBytes = DefaultTCPSinkOptions().SpoolMaxBytes
+	}
+
+	s := &TCPSink{
+		conn: newLogConnection("tcp", net.JoinHostPort(host, port), opts.ReconnectBase, opts.ReconnectMax),
+	}
+	if opts.SpoolPath != "" {
+		spool, err := newLogSpool(opts.SpoolPath, opts.SpoolMaxBytes)
+		if err != nil {
+			return nil, err
+		}
+		s.spool = spool
+	}
+	return s, nil
+}
+
+// Name 實作 LogSink
+func (s *TCPSink) Name() string { return "tcp" }
+
+// Write 實作 LogSink，會先依序重送暫存檔中的日誌；
+// 失敗或仍在重新連線的退避期間時，改為輸出到標準錯誤並寫入暫存檔
+func (s *TCPSink) Write(entries []*LogEntry) error {
+	if err := s.Flush(); err != nil {
+		s.spill(entries)
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		buf.Write(entry.JSON)
+		buf.WriteByte('\n')
+	}
+	if _, err := s.conn.Write(buf.Bytes()); err != nil {
+		s.spill(entries)
+		return err
+	}
+	return nil
+}
+
+// Flush 若連線可用，依序重送暫存檔中的日誌
+func (s *TCPSink) Flush() error {
+	if s.spool == nil || !s.spool.pending() {
+		return nil
+	}
+	if !s.conn.available() {
+		return errReconnectBackoff
+	}
+	// 暫存的日誌較舊，必須先送出才能維持順序
+	return s.spool.replay(s.conn)
+}
+
+// spill 處理無法送達的日誌：輸出到標準錯誤，並寫入暫存檔等待重送
+func (s *TCPSink) spill(entries []*LogEntry) {
+	for _, entry := range entries {
+		printFallback(entry)
+	}
+	if s.spool == nil || len(entries) == 0 {
+		return
+	}
+
+	written, err := s.spool.append(entries)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] %v\n", err)
+	}
+	// 暫存檔已滿，其餘日誌計入丟棄
+	if s.dropped != nil {
+		for i := written; i < len(entries); i++ {
+			s.dropped()
+		}
+	}
+}
+
+// setDropHandler 實作 logSinkDropper
+func (s *TCPSink) setDropHandler(drop func()) {
+	s.dropped = drop
+}
+
+// Ping 測試是否可連線（不影響目前的連接）
+func (s *TCPSink) Ping(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.conn.addr)
+	if err != nil {
+		return fmt.Errorf("連接到 %s 失敗: %v", s.conn.addr, err)
+	}
+	return conn.Close()
+}
+
+// Close 實作 LogSink
+func (s *TCPSink) Close() error {
+	if s.spool != nil {
+		s.spool.close()
+	}
+	return s.conn.close()
+}
+
+// UDPSink 以 newline JSON 經 UDP 發送日誌，每筆日誌一個封包
+//
+// UDP 不保證送達，失敗的日誌不會暫存。
+type UDPSink struct {
+	conn *logConnection
+}
+
+// NewUDPSink 建立 UDP sink
+func NewUDPSink(host, port string) *UDPSink {
+	return &UDPSink{conn: newLogConnection("udp", net.JoinHostPort(host, port), 0, 0)}
+}
+
+// Name 實作 LogSink
+func (s *UDPSink) Name() string { return "udp" }
+
+// Write 實作 LogSink
+func (s *UDPSink) Write(entries []*LogEntry) error {
+	for _, entry := range entries {
+		packet := append(append([]byte(nil), entry.JSON...), '\n')
+		if _, err := s.conn.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close 實作 LogSink
+func (s *UDPSink) Close() error {
+	return s.conn.close()
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// syslogFacilityLocal0 RFC5424 facility：local0
+const syslogFacilityLocal0 = 16
+
+// syslogSDID 結構化資料的 SD-ID（32473 為 RFC5612 保留給文件範例的企業編號）
+const syslogSDID = "fields@32473"
+
+// SyslogSink 以 RFC5424 格式發送日誌
+//
+// 透過 UDP 時每筆日誌一個封包；透過 TCP 時依 RFC6587 以位元組長度前綴分隔。
+// 額外欄位放在結構化資料 [fields@32473 ...] 中。
+type SyslogSink struct {
+	conn     *logConnection
+	network  string
+	hostname string
+	appName  string
+	procID   string
+}
+
+// NewSyslogSink 建立 syslog sink，network 為 udp 或 tcp
+func NewSyslogSink(network, addr, appName string) (*SyslogSink, error) {
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("不支援的 syslog 傳輸協定: %s", network)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+	return &SyslogSink{
+		conn:     newLogConnection(network, addr, 0, 0),
+		network:  network,
+		hostname: syslogHeaderValue(hostname, 255),
+		appName:  syslogHeaderValue(appName, 48),
+		procID:   strconv.Itoa(os.Getpid()),
+	}, nil
+}
+
+// Name 實作 LogSink
+func (s *SyslogSink) Name() string { return "syslog" }
+
+// Write 實作 LogSink
+func (s *SyslogSink) Write(entries []*LogEntry) error {
+	if s.network == "tcp" {
+		var b strings.Builder
+		for _, entry := range entries {
+			msg := s.format(entry)
+			fmt.Fprintf(&b, "%d %s", len(msg), msg)
+		}
+		_, err := s.conn.Write([]byte(b.String()))
+		return err
+	}
+
+	for _, entry := range entries {
+		if _, err := s.conn.Write([]byte(s.format(entry))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close 實作 LogSink
+func (s *SyslogSink) Close() error {
+	return s.conn.close()
+}
+
+// format 產生 RFC5424 訊息：<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG
+func (s *SyslogSink) format(entry *LogEntry) string {
+	pri := syslogFacilityLocal0*8 + syslogSeverity(entry.Level)
+
+	msgID := "-"
+	if t, ok := entry.Fields["type"].(string); ok && t != "" {
+		msgID = syslogHeaderValue(t, 32)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s %s ",
+		pri, entry.Time.UTC().Format(time.RFC3339Nano), s.hostname, s.appName, s.procID, msgID)
+
+	keys := extraFields(entry.Fields)
+	if len(keys) == 0 {
+		b.WriteString("-")
+	} else {
+		b.WriteString("[" + syslogSDID)
+		for _, key := range keys {
+			value := entry.Fields[key]
+			var text string
+			switch v := value.(type) {
+			case string:
+				text = v
+			case map[string]interface{}, []interface{}:
+				data, _ := json.Marshal(v)
+				text = string(data)
+			default:
+				text = fmt.Sprint(v)
+			}
+			fmt.Fprintf(&b, ` %s="%s"`, syslogParamName(key), syslogEscape(text))
+		}
+		b.WriteString("]")
+	}
+
+	// MSG 以 UTF-8 BOM 開頭，表示內容為 UTF-8
+	b.WriteString(" \ufeff")
+	b.WriteString(entry.Message)
+	return b.String()
+}
+
+// syslogHeaderValue 將標頭欄位限制為可列印 ASCII 並截斷長度，空值以 "-" 表示
+func syslogHeaderValue(value string, maxLen int) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r > 32 && r < 127 {
+			b.WriteRune(r)
+		}
+	}
+	out := b.String()
+	if out == "" {
+		return "-"
+	}
+	if len(out) > maxLen {
+		out = out[:maxLen]
+	}
+	return out
+}
+
+// syslogParamName 將欄位名稱轉為合法的 PARAM-NAME（不可含 = ] " 與空白，最長 32 字元）
+func syslogParamName(key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		if r > 32 && r < 127 && r != '=' && r != ']' && r != '"' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	name := b.String()
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// syslogEscape 跳脫 PARAM-VALUE 中的 "、\ 與 ]
+func syslogEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
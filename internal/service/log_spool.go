@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"fetch-sketchfab-data/internal/metrics"
+)
+
+// replayChunkSize 重送暫存日誌時單次寫入的最大位元組數
+const replayChunkSize = 64 * 1024
+
+// logSpool 無法送達 Logstash 時的本機暫存檔
+//
+// 檔案內容與送往 Logstash 的格式相同（每行一筆 JSON），依寫入順序重送，
+// 程式重啟後仍會保留，直到成功送出為止。僅由背景發送 goroutine 存取。
+type logSpool struct {
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+}
+
+// newLogSpool 開啟（或建立）暫存檔，保留上次未送出的內容
+func newLogSpool(path string, maxBytes int64) (*logSpool, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("建立日誌暫存目錄失敗: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("開啟日誌暫存檔失敗: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("讀取日誌暫存檔資訊失敗: %v", err)
+	}
+
+	s := &logSpool{path: path, maxBytes: maxBytes, file: file, size: info.Size()}
+	metrics.LogSpoolBytes.Set(float64(s.size))
+	return s, nil
+}
+
+// pending 是否有尚未送出的暫存日誌
+func (s *logSpool) pending() bool {
+	return s.size > 0
+}
+
+// append 依序寫入日誌，回傳成功寫入的筆數；超過容量上限的日誌不會寫入
+func (s *logSpool) append(entries []*LogEntry) (int, error) {
+	var buf bytes.Buffer
+	written := 0
+	for _, entry := range entries {
+		if s.size+int64(buf.Len()+len(entry.JSON)+1) > s.maxBytes {
+			break
+		}
+		buf.Write(entry.JSON)
+		buf.WriteByte('\n')
+		written++
+	}
+	if written == 0 {
+		return 0, nil
+	}
+
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		return 0, fmt.Errorf("寫入日誌暫存檔失敗: %v", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return written, fmt.Errorf("同步日誌暫存檔失敗: %v", err)
+	}
+	s.size += int64(buf.Len())
+	metrics.LogSpoolBytes.Set(float64(s.size))
+	return written, nil
+}
+
+// replay 依序將暫存日誌寫入 w，全部送出後清空暫存檔；
+// 中途失敗時只保留尚未送出的部分
+func (s *logSpool) replay(w io.Writer) error {
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("讀取日誌暫存檔失敗: %v", err)
+	}
+
+	reader := bufio.NewReader(s.file)
+	var (
+		chunk bytes.Buffer
+		sent  int64
+	)
+	send := func() error {
+		if chunk.Len() == 0 {
+			return nil
+		}
+		if _, err := w.Write(chunk.Bytes()); err != nil {
+			return err
+		}
+		sent += int64(chunk.Len())
+		chunk.Reset()
+		return nil
+	}
+
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return fmt.Errorf("讀取日誌暫存檔失敗: %v", readErr)
+		}
+		// 忽略結尾不完整的行（例如寫入途中程式中止）
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			chunk.Write(line)
+		}
+		if chunk.Len() >= replayChunkSize || readErr == io.EOF {
+			if err := send(); err != nil {
+				if compactErr := s.compact(sent); compactErr != nil {
+					return compactErr
+				}
+				return fmt.Errorf("重送暫存日誌失敗: %v", err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	if err := s.file.Truncate(0); err != nil {
+		return fmt.Errorf("清空日誌暫存檔失敗: %v", err)
+	}
+	s.size = 0
+	metrics.LogSpoolBytes.Set(0)
+	return nil
+}
+
+// compact 移除前 offset 個已送出的位元組，以暫存檔改名的方式避免中途中止遺失資料
+func (s *logSpool) compact(offset int64) error {
+	if offset == 0 {
+		return nil
+	}
+	if _, err := s.file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("讀取日誌暫存檔失敗: %v", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("建立日誌暫存檔失敗: %v", err)
+	}
+	size, err := io.Copy(tmp, s.file)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("寫入日誌暫存檔失敗: %v", err)
+	}
+
+	s.file.Close()
+	renameErr := os.Rename(tmpPath, s.path)
+	if renameErr != nil {
+		os.Remove(tmpPath)
+		size = s.size
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("開啟日誌暫存檔失敗: %v", err)
+	}
+	s.file = file
+	if renameErr != nil {
+		return fmt.Errorf("更新日誌暫存檔失敗: %v", renameErr)
+	}
+	s.size = size
+	metrics.LogSpoolBytes.Set(float64(s.size))
+	return nil
+}
+
+// close 關閉暫存檔
+func (s *logSpool) close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"fetch-sketchfab-data/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// decodeRaw 將 RawData 中的欄位解碼為指定的結構
+func (m *SketchfabModel) decodeRaw(key string, out interface{}) bool {
+	value, ok := m.RawData[key]
+	if !ok || value == nil {
+		return false
+	}
+
+	data, err := bson.Marshal(bson.M{"v": value})
+	if err != nil {
+		return false
+	}
+	wrapper := struct {
+		V bson.RawValue `bson:"v"`
+	}{}
+	if err := bson.Unmarshal(data, &wrapper); err != nil {
+		return false
+	}
+	return wrapper.V.Unmarshal(out) == nil
+}
+
+// Archives 取得模型的檔案封存資訊
+func (m *SketchfabModel) Archives() models.Archives {
+	var archives models.Archives
+	m.decodeRaw("archives", &archives)
+	return archives
+}
+
+// ArchiveList 以格式名稱列出模型的檔案封存（依 glb、gltf、gltf-ar、usdz、source 順序）
+func (m *SketchfabModel) ArchiveList() []NamedArchive {
+	archives := m.Archives()
+	var list []NamedArchive
+	for _, item := range []struct {
+		format  string
+		archive *models.Archive
+	}{
+		{"glb", archives.GLB},
+		{"gltf", archives.GLTF},
+		{"gltf-ar", archives.GLTFAR},
+		{"usdz", archives.USDZ},
+		{"source", archives.Source},
+	} {
+		if item.archive != nil {
+			list = append(list, NamedArchive{Format: item.format, Archive: *item.archive})
+		}
+	}
+	return list
+}
+
+// NamedArchive 帶有格式名稱的檔案封存資訊
+type NamedArchive struct {
+	Format string `json:"format"`
+	models.Archive
+}
+
+// Thumbnails 取得模型的縮圖集合
+func (m *SketchfabModel) Thumbnails() models.Thumbnails {
+	var thumbnails models.Thumbnails
+	m.decodeRaw("thumbnails", &thumbnails)
+	return thumbnails
+}
+
+// ThumbnailURL 取得寬度不超過 maxWidth 的最大縮圖網址，沒有符合的則回傳最小的縮圖
+func (m *SketchfabModel) ThumbnailURL(maxWidth int) string {
+	var best, smallest *models.ThumbnailImage
+	images := m.Thumbnails().Images
+	for i := range images {
+		image := &images[i]
+		if smallest == nil || image.Width < smallest.Width {
+			smallest = image
+		}
+		if image.Width <= maxWidth && (best == nil || image.Width > best.Width) {
+			best = image
+		}
+	}
+	if best != nil {
+		return best.URL
+	}
+	if smallest != nil {
+		return smallest.URL
+	}
+	return ""
+}
+
+// ViewerURL 取得模型的檢視頁網址
+func (m *SketchfabModel) ViewerURL() string {
+	if url, ok := m.RawData["viewerUrl"].(string); ok {
+		return url
+	}
+	return ""
+}
+
+// UserString 取得使用者資訊中的字串欄位
+func (m *SketchfabModel) UserString(key string) string {
+	if value, ok := m.User[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// LicenseString 取得授權資訊中的字串欄位
+func (m *SketchfabModel) LicenseString(key string) string {
+	if value, ok := m.License[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// TagNames 取得模型的標籤 slug（沒有 slug 時使用名稱）
+func (m *SketchfabModel) TagNames() []string {
+	names := make([]string, 0, len(m.Tags))
+	for _, tag := range m.Tags {
+		name := tag["slug"]
+		if name == "" {
+			name = tag["name"]
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// CategoryNames 取得模型的分類名稱
+func (m *SketchfabModel) CategoryNames() []string {
+	names := make([]string, 0, len(m.Categories))
+	for _, category := range m.Categories {
+		names = append(names, category["name"])
+	}
+	return names
+}
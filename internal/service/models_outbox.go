@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// outboxCommitTimeout 事件停留在 pending_commit 超過此時間，表示寫入模型的程序已中止或失敗
+const outboxCommitTimeout = 2 * upsertTimeout
+
+// SweepOutbox 處理停留在 pending_commit 的事件：依模型目前的狀態判斷對應的寫入是否已完成，
+// 完成的改為可送出，沒有完成的刪除，回傳兩者的筆數
+func (s *ModelsService) SweepOutbox(ctx context.Context) (committed, discarded int, err error) {
+	if s.outbox == nil {
+		return 0, 0, nil
+	}
+	entries, err := s.outbox.ListUncommitted(ctx, time.Now().Add(-outboxCommitTimeout))
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, entry := range entries {
+		applied, err := s.eventApplied(ctx, &entry.ModelEvent)
+		if err != nil {
+			return committed, discarded, err
+		}
+		if applied {
+			if err := s.outbox.Commit(ctx, []*ModelEvent{&entry.ModelEvent}); err != nil {
+				return committed, discarded, err
+			}
+			committed++
+			continue
+		}
+		if err := s.outbox.Discard(ctx, entry.ID); err != nil {
+			return committed, discarded, err
+		}
+		discarded++
+	}
+	return committed, discarded, nil
+}
+
+// eventApplied 事件描述的變更是否已寫入 models 集合
+//
+// 新增與更新：模型的 changed_at 不早於事件發生時間（兩者在 UpsertModels 中取自同一個時間）。
+// 移除：模型已不存在，或是在事件之後才重新加入。
+func (s *ModelsService) eventApplied(ctx context.Context, event *ModelEvent) (bool, error) {
+	var model SketchfabModel
+	opCtx, end := startOp(ctx, s.collection, "find_one")
+	err := s.collection.FindOne(opCtx, bson.M{"_id": event.ModelID}).Decode(&model)
+	end(ignoreNoDocuments(err))
+	if err != nil && err != mongo.ErrNoDocuments {
+		return false, fmt.Errorf("確認事件對應的模型失敗: %v", err)
+	}
+	exists := err == nil
+
+	if event.Type == EventModelRemoved {
+		return !exists || model.InsertedAt.After(event.OccurredAt), nil
+	}
+	return exists && !model.ChangedAt.Before(event.OccurredAt), nil
+}
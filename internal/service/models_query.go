@@ -0,0 +1,775 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrModelNotFound 表示找不到指定的模型
+var ErrModelNotFound = errors.New("找不到模型")
+
+// ValidModelID 模型 ID 是否可安全地作為目錄與檔名：不可為空或 .，不可包含路徑分隔字元或 ..
+//
+// uid 來自 API 或控制 API，寫入檔案前都須先檢查，避免寫到輸出目錄之外。
+func ValidModelID(id string) bool {
+	return id != "" && id != "." && !strings.ContainsAny(id, `/\`) && !strings.Contains(id, "..")
+}
+
+// ErrInvalidQuery 表示查詢條件（排序欄位、分頁游標等）格式錯誤
+var ErrInvalidQuery = errors.New("無效的查詢條件")
+
+const (
+	// DefaultQueryLimit 預設每頁筆數
+	DefaultQueryLimit = 24
+	// MaxQueryLimit 每頁筆數上限
+	MaxQueryLimit = 100
+)
+
+// sortFields 可排序的欄位與對應的資料庫欄位
+var sortFields = map[string]string{
+	"created_at":  "created_at",
+	"updated_at":  "updated_at",
+	"fetched_at":  "fetched_at",
+	"changed_at":  "changed_at",
+	"inserted_at": "inserted_at",
+	"view_count":  "view_count",
+	"like_count":  "like_count",
+	"name":        "name",
+}
+
+// ModelFilter 模型查詢條件
+type ModelFilter struct {
+	IDs           []string // 限定模型 uid
+	Query         string   // 關鍵字，以空白分隔，每個字都須出現在名稱、描述或標籤中（不分大小寫）
+	Tag           string
+	Category      string
+	License       string // 授權 uid 或名稱
+	User          string // 使用者 uid 或帳號
+	Downloadable  *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	ChangedSince  *time.Time // 新增或內容變更時間下限（含），沒有 changed_at 的舊資料以 fetched_at 判斷
+	ChangedBefore *time.Time // 新增或內容變更時間上限（不含）
+	MinViews      *int
+	MaxViews      *int
+	MinLikes      *int
+	MaxLikes      *int
+	Sort          string // 排序欄位，前綴 "-" 表示遞減，例如 "-like_count"
+	Cursor        string
+	Limit         int
+	WithRawData   bool // 是否包含原始 API 資料（縮圖、檔案封存等）
+}
+
+// ModelPage 分頁查詢結果
+type ModelPage struct {
+	Models     []*SketchfabModel `json:"models"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// ModelSnapshot 模型統計數據的歷史快照
+type ModelSnapshot struct {
+	ModelID   string    `bson:"model_id" json:"model_id"`
+	Name      string    `bson:"name" json:"name"`
+	ViewCount int       `bson:"view_count" json:"view_count"`
+	LikeCount int       `bson:"like_count" json:"like_count"`
+	FetchedAt time.Time `bson:"fetched_at" json:"fetched_at"`
+}
+
+// CountBucket 分組統計
+type CountBucket struct {
+	Key   string `bson:"_id" json:"key"`
+	Count int64  `bson:"count" json:"count"`
+}
+
+// UserStats 作者的模型統計
+type UserStats struct {
+	UID         string `bson:"_id" json:"uid"`
+	Username    string `bson:"username" json:"username"`
+	DisplayName string `bson:"display_name" json:"display_name"`
+	ProfileURL  string `bson:"profile_url" json:"profile_url"`
+	ModelCount  int64  `bson:"model_count" json:"model_count"`
+	TotalViews  int64  `bson:"total_views" json:"total_views"`
+	TotalLikes  int64  `bson:"total_likes" json:"total_likes"`
+}
+
+// UserFilter 作者查詢條件
+type UserFilter struct {
+	MinTotalLikes *int
+	MinModels     *int
+	Limit         int
+}
+
+// CatalogueStats 模型資料庫統計
+type CatalogueStats struct {
+	TotalModels        int64         `json:"total_models"`
+	DownloadableModels int64         `json:"downloadable_models"`
+	TotalViews         int64         `json:"total_views"`
+	TotalLikes         int64         `json:"total_likes"`
+	LastFetchedAt      *time.Time    `json:"last_fetched_at,omitempty"`
+	Licenses           []CountBucket `json:"licenses"`
+	TopTags            []CountBucket `json:"top_tags"`
+	TopCategories      []CountBucket `json:"top_categories"`
+	TopUsers           []CountBucket `json:"top_users"`
+}
+
+// cursorPayload 分頁游標內容
+type cursorPayload struct {
+	Value interface{} `bson:"v"`
+	ID    string      `bson:"id"`
+}
+
+// parseSort 解析排序參數，回傳資料庫欄位與方向
+func parseSort(sort string) (string, int, error) {
+	if sort == "" {
+		return "fetched_at", -1, nil
+	}
+
+	direction := 1
+	if strings.HasPrefix(sort, "-") {
+		direction = -1
+		sort = sort[1:]
+	}
+
+	field, ok := sortFields[sort]
+	if !ok {
+		return "", 0, fmt.Errorf("%w: 不支援的排序欄位 %s", ErrInvalidQuery, sort)
+	}
+	return field, direction, nil
+}
+
+// buildFilter 將查詢條件轉換為 MongoDB 篩選條件（各條件以 AND 組合）
+func (f *ModelFilter) buildFilter() bson.A {
+	clauses := bson.A{}
+
+	if len(f.IDs) > 0 {
+		clauses = append(clauses, bson.M{"_id": bson.M{"$in": f.IDs}})
+	}
+	for _, word := range strings.Fields(f.Query) {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(word), "$options": "i"}
+		clauses = append(clauses, bson.M{"$or": bson.A{
+			bson.M{"name": pattern},
+			bson.M{"description": pattern},
+			bson.M{"tags.name": pattern},
+			bson.M{"tags.slug": pattern},
+		}})
+	}
+	if f.Tag != "" {
+		clauses = append(clauses, bson.M{"$or": bson.A{
+			bson.M{"tags.slug": f.Tag},
+			bson.M{"tags.name": f.Tag},
+		}})
+	}
+	if f.Category != "" {
+		clauses = append(clauses, bson.M{"categories.name": f.Category})
+	}
+	if f.License != "" {
+		clauses = append(clauses, bson.M{"$or": bson.A{
+			bson.M{"license.uid": f.License},
+			bson.M{"license.label": f.License},
+		}})
+	}
+	if f.User != "" {
+		clauses = append(clauses, bson.M{"$or": bson.A{
+			bson.M{"user.uid": f.User},
+			bson.M{"user.username": f.User},
+		}})
+	}
+	if f.Downloadable != nil {
+		clauses = append(clauses, bson.M{"is_downloadable": *f.Downloadable})
+	}
+
+	if createdAt := timeRange(f.CreatedAfter, f.CreatedBefore); createdAt != nil {
+		clauses = append(clauses, bson.M{"created_at": createdAt})
+	}
+
+	if changed := timeRange(f.ChangedSince, f.ChangedBefore); changed != nil {
+		clauses = append(clauses, bson.M{"$or": bson.A{
+			bson.M{"changed_at": changed},
+			bson.M{"changed_at": bson.M{"$exists": false}, "fetched_at": changed},
+		}})
+	}
+
+	if viewCount := rangeFilter(f.MinViews, f.MaxViews); viewCount != nil {
+		clauses = append(clauses, bson.M{"view_count": viewCount})
+	}
+	if likeCount := rangeFilter(f.MinLikes, f.MaxLikes); likeCount != nil {
+		clauses = append(clauses, bson.M{"like_count": likeCount})
+	}
+
+	return clauses
+}
+
+// andFilter 將多個條件組合為單一篩選條件
+func andFilter(clauses bson.A) bson.M {
+	if len(clauses) == 0 {
+		return bson.M{}
+	}
+	return bson.M{"$and": clauses}
+}
+
+// rangeFilter 建立數值範圍條件
+func rangeFilter(min, max *int) bson.M {
+	if min == nil && max == nil {
+		return nil
+	}
+	r := bson.M{}
+	if min != nil {
+		r["$gte"] = *min
+	}
+	if max != nil {
+		r["$lte"] = *max
+	}
+	return r
+}
+
+// timeRange 建立 [since, before) 的時間範圍條件
+func timeRange(since, before *time.Time) bson.M {
+	if since == nil && before == nil {
+		return nil
+	}
+	r := bson.M{}
+	if since != nil {
+		r["$gte"] = *since
+	}
+	if before != nil {
+		r["$lt"] = *before
+	}
+	return r
+}
+
+// sortValue 取得模型在排序欄位上的值
+func sortValue(model *SketchfabModel, field string) interface{} {
+	switch field {
+	case "created_at":
+		return model.CreatedAt
+	case "updated_at":
+		return model.UpdatedAt
+	case "changed_at":
+		return model.ChangedAt
+	case "inserted_at":
+		return model.InsertedAt
+	case "view_count":
+		return model.ViewCount
+	case "like_count":
+		return model.LikeCount
+	case "name":
+		return model.Name
+	default:
+		return model.FetchedAt
+	}
+}
+
+// encodeCursor 將最後一筆資料的排序值編碼為游標
+func encodeCursor(value interface{}, id string) (string, error) {
+	data, err := bson.Marshal(cursorPayload{Value: value, ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor 解碼游標
+func decodeCursor(cursor string) (*cursorPayload, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 分頁游標格式錯誤", ErrInvalidQuery)
+	}
+	var payload cursorPayload
+	if err := bson.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("%w: 分頁游標格式錯誤", ErrInvalidQuery)
+	}
+	return &payload, nil
+}
+
+// QueryModels 依條件查詢模型，以游標分頁
+func (s *ModelsService) QueryModels(filter *ModelFilter) (*ModelPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if filter == nil {
+		filter = &ModelFilter{}
+	}
+
+	sortField, direction, err := parseSort(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+	if limit > MaxQueryLimit {
+		limit = MaxQueryLimit
+	}
+
+	clauses := filter.buildFilter()
+
+	// 以 (排序欄位, _id) 做 keyset 分頁
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		op := "$gt"
+		if direction < 0 {
+			op = "$lt"
+		}
+		clauses = append(clauses, bson.M{"$or": bson.A{
+			bson.M{sortField: bson.M{op: cursor.Value}},
+			bson.M{sortField: cursor.Value, "_id": bson.M{op: cursor.ID}},
+		}})
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: sortField, Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(int64(limit + 1))
+	if !filter.WithRawData {
+		opts.SetProjection(bson.M{"raw_data": 0})
+	}
+
+	opCtx, end := startOp(ctx, s.collection, "find")
+	cur, err := s.collection.Find(opCtx, andFilter(clauses), opts)
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("查詢模型失敗: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	results := []*SketchfabModel{}
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("解析模型失敗: %v", err)
+	}
+
+	page := &ModelPage{Models: results}
+	if len(results) > limit {
+		page.Models = results[:limit]
+		last := page.Models[limit-1]
+		next, err := encodeCursor(sortValue(last, sortField), last.ID)
+		if err != nil {
+			return nil, fmt.Errorf("建立分頁游標失敗: %v", err)
+		}
+		page.NextCursor = next
+	}
+
+	return page, nil
+}
+
+// StreamModels 依條件與排序以資料庫游標逐筆讀取模型並交給 fn，不會一次載入所有結果，回傳讀取筆數
+//
+// filter.Limit 為匯出筆數上限（0 表示不限制），不受 MaxQueryLimit 限制；filter.Cursor 會被忽略。
+func (s *ModelsService) StreamModels(ctx context.Context, filter *ModelFilter, fn func(model *SketchfabModel) error) (int, error) {
+	if filter == nil {
+		filter = &ModelFilter{}
+	}
+	sortField, direction, err := parseSort(filter.Sort)
+	if err != nil {
+		return 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: sortField, Value: direction}, {Key: "_id", Value: direction}}).
+		SetBatchSize(500)
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+	if !filter.WithRawData {
+		opts.SetProjection(bson.M{"raw_data": 0})
+	}
+
+	opCtx, end := startOp(ctx, s.collection, "find_stream")
+	cur, err := s.collection.Find(opCtx, andFilter(filter.buildFilter()), opts)
+	end(err)
+	if err != nil {
+		return 0, fmt.Errorf("查詢模型失敗: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	count := 0
+	for cur.Next(ctx) {
+		var model SketchfabModel
+		if err := cur.Decode(&model); err != nil {
+			return count, fmt.Errorf("解析模型失敗: %v", err)
+		}
+		if err := fn(&model); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := cur.Err(); err != nil {
+		return count, fmt.Errorf("讀取模型失敗: %v", err)
+	}
+	return count, nil
+}
+
+// GetModelHistory 取得模型的統計數據歷史快照（依時間遞增）
+func (s *ModelsService) GetModelHistory(id string) ([]*ModelSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "fetched_at", Value: 1}})
+	opCtx, end := startOp(ctx, s.historyCollection, "find_history")
+	cur, err := s.historyCollection.Find(opCtx, bson.M{"model_id": id}, opts)
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("查詢模型歷史失敗: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	snapshots := []*ModelSnapshot{}
+	if err := cur.All(ctx, &snapshots); err != nil {
+		return nil, fmt.Errorf("解析模型歷史失敗: %v", err)
+	}
+	return snapshots, nil
+}
+
+// GetStats 取得模型資料庫統計
+func (s *ModelsService) GetStats() (*CatalogueStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	stats := &CatalogueStats{}
+
+	// 總數、瀏覽數、喜歡數
+	opCtx, end := startOp(ctx, s.collection, "aggregate")
+	cur, err := s.collection.Aggregate(opCtx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":          nil,
+			"total":        bson.M{"$sum": 1},
+			"downloadable": bson.M{"$sum": bson.M{"$cond": bson.A{"$is_downloadable", 1, 0}}},
+			"views":        bson.M{"$sum": "$view_count"},
+			"likes":        bson.M{"$sum": "$like_count"},
+			"last_fetched": bson.M{"$max": "$fetched_at"},
+		}}},
+	})
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("統計模型失敗: %v", err)
+	}
+	var totals []struct {
+		Total        int64     `bson:"total"`
+		Downloadable int64     `bson:"downloadable"`
+		Views        int64     `bson:"views"`
+		Likes        int64     `bson:"likes"`
+		LastFetched  time.Time `bson:"last_fetched"`
+	}
+	if err := cur.All(ctx, &totals); err != nil {
+		return nil, fmt.Errorf("統計模型失敗: %v", err)
+	}
+	if len(totals) > 0 {
+		stats.TotalModels = totals[0].Total
+		stats.DownloadableModels = totals[0].Downloadable
+		stats.TotalViews = totals[0].Views
+		stats.TotalLikes = totals[0].Likes
+		stats.LastFetchedAt = &totals[0].LastFetched
+	}
+
+	if stats.Licenses, err = s.countBy(ctx, "", "$license.label", 0); err != nil {
+		return nil, err
+	}
+	if stats.TopTags, err = s.countBy(ctx, "$tags", "$tags.name", 20); err != nil {
+		return nil, err
+	}
+	if stats.TopCategories, err = s.countBy(ctx, "$categories", "$categories.name", 20); err != nil {
+		return nil, err
+	}
+	if stats.TopUsers, err = s.countBy(ctx, "", "$user.username", 20); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// countBy 依欄位分組計數，unwind 不為空時先展開陣列，limit 為 0 表示不限制
+func (s *ModelsService) countBy(ctx context.Context, unwind, key string, limit int) ([]CountBucket, error) {
+	pipeline := mongo.Pipeline{}
+	if unwind != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$unwind", Value: unwind}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{"_id": key, "count": bson.M{"$sum": 1}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	)
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	opCtx, end := startOp(ctx, s.collection, "aggregate")
+	cur, err := s.collection.Aggregate(opCtx, pipeline)
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("分組統計失敗: %v", err)
+	}
+	buckets := []CountBucket{}
+	if err := cur.All(ctx, &buckets); err != nil {
+		return nil, fmt.Errorf("分組統計失敗: %v", err)
+	}
+	return buckets, nil
+}
+
+// recordHistory 為新增或更新的模型寫入歷史快照
+func (s *ModelsService) recordHistory(ctx context.Context, changed []*SketchfabModel) error {
+	if len(changed) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, 0, len(changed))
+	for _, model := range changed {
+		docs = append(docs, ModelSnapshot{
+			ModelID:   model.ID,
+			Name:      model.Name,
+			ViewCount: model.ViewCount,
+			LikeCount: model.LikeCount,
+			FetchedAt: model.FetchedAt,
+		})
+	}
+
+	opCtx, end := startOp(ctx, s.historyCollection, "insert_history")
+	_, err := s.historyCollection.InsertMany(opCtx, docs)
+	end(err)
+	if err != nil {
+		return fmt.Errorf("寫入模型歷史失敗: %v", err)
+	}
+	return nil
+}
+
+// GetModelsByIDs 依多個 ID 批次取得模型
+func (s *ModelsService) GetModelsByIDs(ids []string) (map[string]*SketchfabModel, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opCtx, end := startOp(ctx, s.collection, "find")
+	cur, err := s.collection.Find(opCtx, bson.M{"_id": bson.M{"$in": ids}})
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("批次查詢模型失敗: %v", err)
+	}
+	var results []*SketchfabModel
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("解析模型失敗: %v", err)
+	}
+
+	byID := make(map[string]*SketchfabModel, len(results))
+	for _, model := range results {
+		byID[model.ID] = model
+	}
+	return byID, nil
+}
+
+// GetModelsByUsers 依多個作者 uid 批次取得模型，每位作者最多 limit 筆（依喜歡數遞減），limit 為 0 時不限制
+//
+// 有限制時每位作者各查詢一次並由 MongoDB 套用 limit，避免熱門作者的所有模型都被載入記憶體。
+func (s *ModelsService) GetModelsByUsers(userIDs []string, limit int) (map[string][]*SketchfabModel, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	byUser := make(map[string][]*SketchfabModel, len(userIDs))
+	if limit <= 0 {
+		results, err := s.findByUsers(ctx, bson.M{"$in": userIDs}, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, model := range results {
+			uid := model.UserString("uid")
+			byUser[uid] = append(byUser[uid], model)
+		}
+		return byUser, nil
+	}
+
+	for _, uid := range userIDs {
+		results, err := s.findByUsers(ctx, uid, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(results) > 0 {
+			byUser[uid] = results
+		}
+	}
+	return byUser, nil
+}
+
+// findByUsers 依作者條件取得模型（依喜歡數遞減），limit 為 0 時不限制
+func (s *ModelsService) findByUsers(ctx context.Context, user interface{}, limit int) ([]*SketchfabModel, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "like_count", Value: -1}, {Key: "_id", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	opCtx, end := startOp(ctx, s.collection, "find")
+	cur, err := s.collection.Find(opCtx, bson.M{"user.uid": user}, opts)
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("批次查詢作者模型失敗: %v", err)
+	}
+	var results []*SketchfabModel
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("解析模型失敗: %v", err)
+	}
+	return results, nil
+}
+
+// GetModelHistories 依多個模型 ID 批次取得歷史快照
+func (s *ModelsService) GetModelHistories(ids []string) (map[string][]*ModelSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "fetched_at", Value: 1}})
+	opCtx, end := startOp(ctx, s.historyCollection, "find_history")
+	cur, err := s.historyCollection.Find(opCtx, bson.M{"model_id": bson.M{"$in": ids}}, opts)
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("批次查詢模型歷史失敗: %v", err)
+	}
+	var snapshots []*ModelSnapshot
+	if err := cur.All(ctx, &snapshots); err != nil {
+		return nil, fmt.Errorf("解析模型歷史失敗: %v", err)
+	}
+
+	byModel := make(map[string][]*ModelSnapshot, len(ids))
+	for _, snapshot := range snapshots {
+		byModel[snapshot.ModelID] = append(byModel[snapshot.ModelID], snapshot)
+	}
+	return byModel, nil
+}
+
+// GetSnapshotsBefore 依多個模型 ID 批次取得 before 之前的最後一筆歷史快照，作為計算變化量的基準；沒有更早快照的模型不會出現在結果中
+func (s *ModelsService) GetSnapshotsBefore(ctx context.Context, ids []string, before time.Time) (map[string]*ModelSnapshot, error) {
+	if len(ids) == 0 {
+		return map[string]*ModelSnapshot{}, nil
+	}
+	opCtx, end := startOp(ctx, s.historyCollection, "aggregate")
+	cur, err := s.historyCollection.Aggregate(opCtx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"model_id": bson.M{"$in": ids}, "fetched_at": bson.M{"$lt": before}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "model_id", Value: 1}, {Key: "fetched_at", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        "$model_id",
+			"model_id":   bson.M{"$last": "$model_id"},
+			"name":       bson.M{"$last": "$name"},
+			"view_count": bson.M{"$last": "$view_count"},
+			"like_count": bson.M{"$last": "$like_count"},
+			"fetched_at": bson.M{"$last": "$fetched_at"},
+		}}},
+	})
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("批次查詢模型歷史失敗: %v", err)
+	}
+	var snapshots []*ModelSnapshot
+	if err := cur.All(ctx, &snapshots); err != nil {
+		return nil, fmt.Errorf("解析模型歷史失敗: %v", err)
+	}
+
+	byModel := make(map[string]*ModelSnapshot, len(snapshots))
+	for _, snapshot := range snapshots {
+		byModel[snapshot.ModelID] = snapshot
+	}
+	return byModel, nil
+}
+
+// userStatsGroup 依作者分組的聚合階段
+var userStatsGroup = bson.D{{Key: "$group", Value: bson.M{
+	"_id":          "$user.uid",
+	"username":     bson.M{"$first": "$user.username"},
+	"display_name": bson.M{"$first": "$user.displayName"},
+	"profile_url":  bson.M{"$first": "$user.profileUrl"},
+	"model_count":  bson.M{"$sum": 1},
+	"total_views":  bson.M{"$sum": "$view_count"},
+	"total_likes":  bson.M{"$sum": "$like_count"},
+}}}
+
+// GetUserStats 依多個作者 uid 批次取得作者統計
+func (s *ModelsService) GetUserStats(userIDs []string) (map[string]*UserStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opCtx, end := startOp(ctx, s.collection, "aggregate")
+	cur, err := s.collection.Aggregate(opCtx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user.uid": bson.M{"$in": userIDs}}}},
+		userStatsGroup,
+	})
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("統計作者失敗: %v", err)
+	}
+	var results []*UserStats
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("統計作者失敗: %v", err)
+	}
+
+	byID := make(map[string]*UserStats, len(results))
+	for _, stats := range results {
+		byID[stats.UID] = stats
+	}
+	return byID, nil
+}
+
+// QueryUsers 依條件查詢作者統計（依總喜歡數遞減）
+func (s *ModelsService) QueryUsers(filter *UserFilter) ([]*UserStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if filter == nil {
+		filter = &UserFilter{}
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+	if limit > MaxQueryLimit {
+		limit = MaxQueryLimit
+	}
+
+	having := bson.M{}
+	if filter.MinTotalLikes != nil {
+		having["total_likes"] = bson.M{"$gte": *filter.MinTotalLikes}
+	}
+	if filter.MinModels != nil {
+		having["model_count"] = bson.M{"$gte": *filter.MinModels}
+	}
+
+	opCtx, end := startOp(ctx, s.collection, "aggregate")
+	cur, err := s.collection.Aggregate(opCtx, mongo.Pipeline{
+		userStatsGroup,
+		{{Key: "$match", Value: having}},
+		{{Key: "$sort", Value: bson.D{{Key: "total_likes", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	})
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("查詢作者失敗: %v", err)
+	}
+	results := []*UserStats{}
+	if err := cur.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("查詢作者失敗: %v", err)
+	}
+	return results, nil
+}
+
+// CountTags 依標籤計數，limit 為 0 表示不限制
+func (s *ModelsService) CountTags(limit int) ([]CountBucket, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return s.countBy(ctx, "$tags", "$tags.name", limit)
+}
+
+// CountCategories 依分類計數，limit 為 0 表示不限制
+func (s *ModelsService) CountCategories(limit int) ([]CountBucket, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return s.countBy(ctx, "$categories", "$categories.name", limit)
+}
+
+// CountLicenses 依授權計數
+func (s *ModelsService) CountLicenses() ([]CountBucket, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return s.countBy(ctx, "", "$license.label", 0)
+}
@@ -6,17 +6,24 @@ import (
 	"time"
 
 	"fetch-sketchfab-data/internal/database"
+	"fetch-sketchfab-data/internal/metrics"
 	"fetch-sketchfab-data/internal/models"
+	"fetch-sketchfab-data/internal/tracing"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ModelsService 處理模型相關的資料庫操作
 type ModelsService struct {
-	client     *database.MongoDBClient
-	collection *mongo.Collection
+	client              *database.MongoDBClient
+	collection          *mongo.Collection
+	historyCollection   *mongo.Collection
+	tombstoneCollection *mongo.Collection
+	sinks               []ModelSink
+	outbox              *OutboxService
 }
 
 // SketchfabModel 代表Sketchfab模型的資料結構
@@ -32,6 +39,8 @@ type SketchfabModel struct {
 	CreatedAt      time.Time              `bson:"created_at" json:"created_at"`
 	UpdatedAt      time.Time              `bson:"updated_at" json:"updated_at"`
 	FetchedAt      time.Time              `bson:"fetched_at" json:"fetched_at"`
+	ChangedAt      time.Time              `bson:"changed_at" json:"changed_at"`                       // 最近一次新增或內容變更的時間，供增量匯出使用
+	InsertedAt     time.Time              `bson:"inserted_at,omitempty" json:"inserted_at,omitempty"` // 第一次寫入資料庫的時間，之後的更新不會改變
 	ViewCount      int                    `bson:"view_count" json:"view_count"`
 	LikeCount      int                    `bson:"like_count" json:"like_count"`
 	IsDownloadable bool                   `bson:"is_downloadable" json:"is_downloadable"`
@@ -41,33 +50,64 @@ type SketchfabModel struct {
 // NewModelsService 建立新的模型服務
 func NewModelsService(client *database.MongoDBClient) *ModelsService {
 	collection := client.GetCollection("models")
+	historyCollection := client.GetCollection("model_history")
 
-	// 建立索引以提升查詢效能
+	s := &ModelsService{
+		client:              client,
+		collection:          collection,
+		historyCollection:   historyCollection,
+		tombstoneCollection: client.GetCollection("model_tombstones"),
+	}
+
+	// 在背景建立索引以提升查詢效能，失敗時可執行 migrate 子命令重試
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
+		s.EnsureIndexes(ctx)
+	}()
 
-		// 建立複合索引
-		indexes := []mongo.IndexModel{
-			{
-				Keys: bson.D{
-					{Key: "name", Value: 1},
-				},
-			},
-			{
-				Keys: bson.D{
-					{Key: "uid", Value: 1},
-				},
-			},
-		}
+	return s
+}
 
-		collection.Indexes().CreateMany(ctx, indexes)
-	}()
+// EnsureIndexes 建立模型、歷史快照與刪除紀錄的索引（已存在時不會重複建立），回傳索引名稱
+func (s *ModelsService) EnsureIndexes(ctx context.Context) ([]string, error) {
+	names, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "name", Value: 1}}},
+		{Keys: bson.D{{Key: "uid", Value: 1}}},
+		{Keys: bson.D{{Key: "changed_at", Value: 1}}},
+		{Keys: bson.D{{Key: "inserted_at", Value: 1}}},
+		// GetModelsByUsers 依作者取得喜歡數最多的模型
+		{Keys: bson.D{{Key: "user.uid", Value: 1}, {Key: "like_count", Value: -1}, {Key: "_id", Value: 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("建立模型索引失敗: %v", err)
+	}
 
-	return &ModelsService{
-		client:     client,
-		collection: collection,
+	// 歷史快照依模型與時間查詢
+	name, err := s.historyCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "model_id", Value: 1},
+			{Key: "fetched_at", Value: 1},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("建立歷史快照索引失敗: %v", err)
 	}
+	names = append(names, name)
+
+	// 刪除紀錄依刪除時間查詢
+	name, err = s.tombstoneCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "deleted_at", Value: 1}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("建立刪除紀錄索引失敗: %v", err)
+	}
+	return append(names, name), nil
+}
+
+// SetOutbox 設定事件 outbox，設定後 UpsertModels 與 RemoveModels 會寫入 model.created、model.updated、model.removed 事件
+func (s *ModelsService) SetOutbox(outbox *OutboxService) {
+	s.outbox = outbox
 }
 
 // SaveModel 儲存或更新模型
@@ -76,6 +116,7 @@ func (s *ModelsService) SaveModel(model *SketchfabModel) error {
 	defer cancel()
 
 	model.FetchedAt = time.Now()
+	model.ChangedAt = model.FetchedAt
 
 	// 使用upsert來更新或插入
 	filter := bson.M{"_id": model.ID}
@@ -83,7 +124,9 @@ func (s *ModelsService) SaveModel(model *SketchfabModel) error {
 
 	opts := options.Update().SetUpsert(true)
 
-	_, err := s.collection.UpdateOne(ctx, filter, update, opts)
+	opCtx, end := startOp(ctx, s.collection, "update_one")
+	_, err := s.collection.UpdateOne(opCtx, filter, update, opts)
+	end(err)
 	if err != nil {
 		return fmt.Errorf("儲存模型失敗: %v", err)
 	}
@@ -105,6 +148,7 @@ func (s *ModelsService) SaveModels(models []*SketchfabModel) error {
 
 	for _, model := range models {
 		model.FetchedAt = time.Now()
+		model.ChangedAt = model.FetchedAt
 
 		operation := mongo.NewUpdateOneModel()
 		operation.SetFilter(bson.M{"_id": model.ID})
@@ -115,7 +159,9 @@ func (s *ModelsService) SaveModels(models []*SketchfabModel) error {
 	}
 
 	// 執行批次寫入
-	_, err := s.collection.BulkWrite(ctx, operations)
+	opCtx, end := startOp(ctx, s.collection, "bulk_write")
+	_, err := s.collection.BulkWrite(opCtx, operations)
+	end(err)
 	if err != nil {
 		return fmt.Errorf("批次儲存模型失敗: %v", err)
 	}
@@ -129,10 +175,12 @@ func (s *ModelsService) GetModelByID(id string) (*SketchfabModel, error) {
 	defer cancel()
 
 	var model SketchfabModel
-	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&model)
+	opCtx, end := startOp(ctx, s.collection, "find_one")
+	err := s.collection.FindOne(opCtx, bson.M{"_id": id}).Decode(&model)
+	end(ignoreNoDocuments(err))
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("找不到ID為 %s 的模型", id)
+			return nil, fmt.Errorf("%w: %s", ErrModelNotFound, id)
 		}
 		return nil, fmt.Errorf("查詢模型失敗: %v", err)
 	}
@@ -141,11 +189,13 @@ func (s *ModelsService) GetModelByID(id string) (*SketchfabModel, error) {
 }
 
 // GetModelsCount 取得模型總數
-func (s *ModelsService) GetModelsCount() (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (s *ModelsService) GetModelsCount(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	count, err := s.collection.CountDocuments(ctx, bson.M{})
+	opCtx, end := startOp(ctx, s.collection, "count_documents")
+	count, err := s.collection.CountDocuments(opCtx, bson.M{})
+	end(err)
 	if err != nil {
 		return 0, fmt.Errorf("計算模型數量失敗: %v", err)
 	}
@@ -155,31 +205,61 @@ func (s *ModelsService) GetModelsCount() (int64, error) {
 
 // UpsertResult 表示 upsert 操作的結果
 type UpsertResult struct {
-	InsertedCount  int64 `json:"inserted_count"`
-	UpdatedCount   int64 `json:"updated_count"`
-	UnchangedCount int64 `json:"unchanged_count"`
+	InsertedCount  int64    `json:"inserted_count"`
+	UpdatedCount   int64    `json:"updated_count"`
+	UnchangedCount int64    `json:"unchanged_count"`
+	InsertedIDs    []string `json:"inserted_ids,omitempty"`
+	UpdatedIDs     []string `json:"updated_ids,omitempty"`
+	SinkErrors     []string `json:"sink_errors,omitempty"`   // 寫入下游 sink 失敗的訊息，不影響 MongoDB 的寫入
+	HistoryError   string   `json:"history_error,omitempty"` // 寫入歷史快照失敗的訊息，模型已寫入，只缺這次的快照
 }
 
+// upsertTimeout UpsertModels 的逾時；changed_at 在批次寫入前決定，最多早於寫入完成這麼久
+const upsertTimeout = 30 * time.Second
+
+// ChangedAtLag changed_at 早於模型實際可被查詢的最長時間
+//
+// 增量匯出以 changed_at 劃分範圍，進度必須往回保留這段時間，否則寫入途中的模型會落在已匯出的範圍而被漏掉。
+const ChangedAtLag = 2 * upsertTimeout
+
 // UpsertModels - 只在資料有變化時才更新
-func (s *ModelsService) UpsertModels(models []*SketchfabModel) (*UpsertResult, error) {
+func (s *ModelsService) UpsertModels(ctx context.Context, models []*SketchfabModel) (result *UpsertResult, err error) {
 	if len(models) == 0 {
 		return &UpsertResult{}, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, span := tracing.Start(ctx, "models.upsert", attribute.Int("models.count", len(models)))
+	defer func() {
+		if result != nil {
+			span.SetAttributes(
+				attribute.Int64("models.inserted", result.InsertedCount),
+				attribute.Int64("models.updated", result.UpdatedCount),
+				attribute.Int64("models.unchanged", result.UnchangedCount),
+			)
+		}
+		tracing.End(span, err)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, upsertTimeout)
 	defer cancel()
 
-	result := &UpsertResult{}
+	result = &UpsertResult{}
 	var operations []mongo.WriteModel
+	var changed []*SketchfabModel
+	var events []*ModelEvent
 
 	for _, model := range models {
 		// 檢查現有資料
 		var existingModel SketchfabModel
-		err := s.collection.FindOne(ctx, bson.M{"_id": model.ID}).Decode(&existingModel)
+		opCtx, end := startOp(ctx, s.collection, "find_one")
+		err := s.collection.FindOne(opCtx, bson.M{"_id": model.ID}).Decode(&existingModel)
+		end(ignoreNoDocuments(err))
 
 		if err == mongo.ErrNoDocuments {
 			// 資料不存在，準備插入
 			model.FetchedAt = time.Now()
+			model.ChangedAt = model.FetchedAt
+			model.InsertedAt = model.FetchedAt
 
 			operation := mongo.NewUpdateOneModel()
 			operation.SetFilter(bson.M{"_id": model.ID})
@@ -187,21 +267,35 @@ func (s *ModelsService) UpsertModels(models []*SketchfabModel) (*UpsertResult, e
 			operation.SetUpsert(true)
 
 			operations = append(operations, operation)
+			changed = append(changed, model)
+			event := newModelEvent(EventModelCreated, model.ID, model.ChangedAt)
+			event.Model = model
+			events = append(events, event)
 			result.InsertedCount++
+			result.InsertedIDs = append(result.InsertedIDs, model.ID)
 
 		} else if err == nil {
 			// 資料存在，檢查是否需要更新
-			if s.shouldUpdateModel(&existingModel, model) {
-				// 保留原始建立時間
+			if fields, previous := changedFields(&existingModel, model); len(fields) > 0 {
+				// 保留原始建立時間與第一次寫入的時間
 				model.CreatedAt = existingModel.CreatedAt
+				model.InsertedAt = existingModel.InsertedAt
 				model.FetchedAt = time.Now()
+				model.ChangedAt = model.FetchedAt
 
 				operation := mongo.NewUpdateOneModel()
 				operation.SetFilter(bson.M{"_id": model.ID})
 				operation.SetUpdate(bson.M{"$set": model})
 
 				operations = append(operations, operation)
+				changed = append(changed, model)
+				event := newModelEvent(EventModelUpdated, model.ID, model.ChangedAt)
+				event.ChangedFields = fields
+				event.Previous = previous
+				event.Model = model
+				events = append(events, event)
 				result.UpdatedCount++
+				result.UpdatedIDs = append(result.UpdatedIDs, model.ID)
 			} else {
 				// 資料沒有變化，只更新取得時間
 				operation := mongo.NewUpdateOneModel()
@@ -216,53 +310,80 @@ func (s *ModelsService) UpsertModels(models []*SketchfabModel) (*UpsertResult, e
 		}
 	}
 
+	// 事件先以 pending_commit 寫入，模型寫入完成後才改為可送出；
+	// 兩者之間中止或批次寫入失敗時，由 SweepOutbox 依模型的實際狀態確認或刪除，不會送出沒有發生的變更
+	if s.outbox != nil {
+		if err := s.outbox.Append(ctx, events); err != nil {
+			return nil, err
+		}
+	}
+
 	// 執行批次操作
 	if len(operations) > 0 {
-		_, err := s.collection.BulkWrite(ctx, operations)
+		opCtx, end := startOp(ctx, s.collection, "bulk_write")
+		_, err := s.collection.BulkWrite(opCtx, operations)
+		end(err)
 		if err != nil {
 			return nil, fmt.Errorf("批次 upsert 失敗: %v", err)
 		}
 	}
-
-	return result, nil
-}
-
-// shouldUpdateModel 判斷模型是否需要更新
-func (s *ModelsService) shouldUpdateModel(existing, new *SketchfabModel) bool {
-	// 檢查關鍵欄位是否有變化
-	if existing.Name != new.Name ||
-		existing.Description != new.Description ||
-		existing.ViewCount != new.ViewCount ||
-		existing.LikeCount != new.LikeCount ||
-		existing.IsDownloadable != new.IsDownloadable {
-		return true
+	if s.outbox != nil {
+		// 確認失敗時事件仍會由 SweepOutbox 送出，只是延後
+		s.outbox.Commit(ctx, events)
 	}
 
-	// 檢查更新時間
-	if !existing.UpdatedAt.Equal(new.UpdatedAt) {
-		return true
+	// 記錄統計數據的歷史快照；模型已寫入，失敗時仍回傳結果，避免呼叫端遺失這次的變更
+	if err := s.recordHistory(ctx, changed); err != nil {
+		result.HistoryError = err.Error()
 	}
 
-	// 檢查標籤數量變化
-	if len(existing.Tags) != len(new.Tags) {
-		return true
-	}
+	// 同步到下游 sink（例如搜尋索引）
+	result.SinkErrors = s.indexSinks(ctx, changed)
 
-	// 檢查分類數量變化
-	if len(existing.Categories) != len(new.Categories) {
-		return true
+	metrics.ModelsUpserted.WithLabelValues("inserted").Add(float64(result.InsertedCount))
+	metrics.ModelsUpserted.WithLabelValues("updated").Add(float64(result.UpdatedCount))
+	metrics.ModelsUpserted.WithLabelValues("unchanged").Add(float64(result.UnchangedCount))
+
+	return result, nil
+}
+
+// startOp 開始一個 MongoDB 操作的 span，回傳的函式會結束 span 並記錄耗時指標
+func startOp(ctx context.Context, collection *mongo.Collection, operation string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "mongo."+operation,
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.operation.name", operation),
+		attribute.String("db.collection.name", collection.Name()),
+	)
+	return ctx, func(err error) {
+		metrics.ObserveMongo(operation, start, err)
+		tracing.End(span, err)
 	}
+}
 
-	return false
+// ignoreNoDocuments 將查無資料視為正常結果，用於記錄指標
+func ignoreNoDocuments(err error) error {
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	return err
 }
 
 // ConvertAndSaveModelsResponse 將API回應轉換為資料庫模型並儲存
-func (s *ModelsService) ConvertAndSaveModelsResponse(response *models.ModelsResponse) (*UpsertResult, error) {
-	if response == nil || len(response.Results) == 0 {
-		return nil, fmt.Errorf("回應為空或沒有模型資料")
+func (s *ModelsService) ConvertAndSaveModelsResponse(ctx context.Context, response *models.ModelsResponse) (result *UpsertResult, err error) {
+	if response == nil {
+		return nil, fmt.Errorf("回應為空")
 	}
+	if len(response.Results) == 0 {
+		// 最後一頁或沒有符合條件的模型，不是錯誤
+		return &UpsertResult{}, nil
+	}
+
+	ctx, span := tracing.Start(ctx, "models.convert_and_save", attribute.Int("models.count", len(response.Results)))
+	defer func() { tracing.End(span, err) }()
 
 	// 轉換API模型為資料庫模型
+	_, convertSpan := tracing.Start(ctx, "models.convert")
 	var dbModels []*SketchfabModel
 
 	for _, apiModel := range response.Results {
@@ -338,6 +459,7 @@ func (s *ModelsService) ConvertAndSaveModelsResponse(response *models.ModelsResp
 
 		dbModels = append(dbModels, dbModel)
 	}
+	convertSpan.End()
 
-	return s.UpsertModels(dbModels)
+	return s.UpsertModels(ctx, dbModels)
 }
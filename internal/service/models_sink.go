@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// ModelSink 接收模型變更的下游儲存（例如搜尋索引），與 models 集合保持同步
+//
+// sink 失敗不會讓 upsert 失敗：資料已寫入 MongoDB，錯誤會記錄在 UpsertResult.SinkErrors，
+// 下游可由 MongoDB 重建。
+type ModelSink interface {
+	// Name sink 名稱，用於錯誤訊息
+	Name() string
+	// IndexModels 寫入新增或內容變更的模型
+	IndexModels(ctx context.Context, models []*SketchfabModel) error
+	// DeleteModels 移除已刪除的模型
+	DeleteModels(ctx context.Context, ids []string) error
+}
+
+// AddSink 加入下游 sink，之後 UpsertModels 與 RemoveModels 的變更都會送到該 sink
+func (s *ModelsService) AddSink(sink ModelSink) {
+	s.sinks = append(s.sinks, sink)
+}
+
+// indexSinks 將變更的模型送到所有 sink，回傳失敗的訊息
+func (s *ModelsService) indexSinks(ctx context.Context, changed []*SketchfabModel) []string {
+	if len(changed) == 0 {
+		return nil
+	}
+	var problems []string
+	for _, sink := range s.sinks {
+		if err := sink.IndexModels(ctx, changed); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", sink.Name(), err))
+		}
+	}
+	return problems
+}
+
+// deleteSinks 從所有 sink 移除模型，回傳失敗的訊息
+func (s *ModelsService) deleteSinks(ctx context.Context, ids []string) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	var problems []string
+	for _, sink := range s.sinks {
+		if err := sink.DeleteModels(ctx, ids); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", sink.Name(), err))
+		}
+	}
+	return problems
+}
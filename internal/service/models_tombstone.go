@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Tombstone 已移除模型的刪除紀錄，供增量匯出輸出刪除
+type Tombstone struct {
+	ID        string    `bson:"_id" json:"id"`
+	Name      string    `bson:"name" json:"name"`
+	DeletedAt time.Time `bson:"deleted_at" json:"deleted_at"`
+	Reason    string    `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// RemoveModels 移除模型並寫入刪除紀錄，回傳實際移除的筆數；不存在的模型會被略過
+func (s *ModelsService) RemoveModels(ctx context.Context, ids []string, reason string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// 先取得模型，刪除紀錄才能顯示是哪個模型，model.removed 事件也會帶上移除前的內容
+	opCtx, end := startOp(ctx, s.collection, "find")
+	cur, err := s.collection.Find(opCtx, bson.M{"_id": bson.M{"$in": ids}})
+	end(err)
+	if err != nil {
+		return 0, fmt.Errorf("查詢要移除的模型失敗: %v", err)
+	}
+	var existing []*SketchfabModel
+	if err := cur.All(ctx, &existing); err != nil {
+		return 0, fmt.Errorf("查詢要移除的模型失敗: %v", err)
+	}
+	if len(existing) == 0 {
+		return 0, nil
+	}
+
+	// 先寫入刪除紀錄再刪除模型，中途失敗時重試不會遺漏刪除
+	now := time.Now()
+	found := make([]string, 0, len(existing))
+	for _, model := range existing {
+		tombstone := &Tombstone{ID: model.ID, Name: model.Name, DeletedAt: now, Reason: reason}
+		opCtx, end := startOp(ctx, s.tombstoneCollection, "replace_one")
+		_, err := s.tombstoneCollection.ReplaceOne(opCtx, bson.M{"_id": model.ID}, tombstone, options.Replace().SetUpsert(true))
+		end(err)
+		if err != nil {
+			return 0, fmt.Errorf("寫入刪除紀錄失敗: %v", err)
+		}
+		found = append(found, model.ID)
+	}
+
+	// 事件先以 pending_commit 寫入，刪除完成後才改為可送出，見 UpsertModels
+	var events []*ModelEvent
+	if s.outbox != nil {
+		events = make([]*ModelEvent, 0, len(existing))
+		for _, model := range existing {
+			event := newModelEvent(EventModelRemoved, model.ID, now)
+			event.Model = model
+			event.Reason = reason
+			events = append(events, event)
+		}
+		if err := s.outbox.Append(ctx, events); err != nil {
+			return 0, err
+		}
+	}
+
+	opCtx, end = startOp(ctx, s.collection, "delete_many")
+	result, err := s.collection.DeleteMany(opCtx, bson.M{"_id": bson.M{"$in": found}})
+	end(err)
+	if err != nil {
+		return 0, fmt.Errorf("移除模型失敗: %v", err)
+	}
+	if s.outbox != nil {
+		s.outbox.Commit(ctx, events)
+	}
+	if problems := s.deleteSinks(ctx, found); len(problems) > 0 {
+		return result.DeletedCount, fmt.Errorf("模型已移除，但同步下游失敗: %s", strings.Join(problems, "; "))
+	}
+	return result.DeletedCount, nil
+}
+
+// StreamTombstones 依刪除時間遞增逐筆讀取 [since, before) 範圍內的刪除紀錄，since 或 before 為 nil 表示不限制
+func (s *ModelsService) StreamTombstones(ctx context.Context, since, before *time.Time, fn func(tombstone *Tombstone) error) (int, error) {
+	query := bson.M{}
+	if deletedAt := timeRange(since, before); deletedAt != nil {
+		query["deleted_at"] = deletedAt
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "deleted_at", Value: 1}, {Key: "_id", Value: 1}})
+	opCtx, end := startOp(ctx, s.tombstoneCollection, "find_stream")
+	cur, err := s.tombstoneCollection.Find(opCtx, query, opts)
+	end(err)
+	if err != nil {
+		return 0, fmt.Errorf("查詢刪除紀錄失敗: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	count := 0
+	for cur.Next(ctx) {
+		var tombstone Tombstone
+		if err := cur.Decode(&tombstone); err != nil {
+			return count, fmt.Errorf("解析刪除紀錄失敗: %v", err)
+		}
+		if err := fn(&tombstone); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := cur.Err(); err != nil {
+		return count, fmt.Errorf("讀取刪除紀錄失敗: %v", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fetch-sketchfab-data/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// outbox 項目狀態
+const (
+	OutboxPendingCommit = "pending_commit" // 對應的模型寫入尚未確認完成，dispatcher 不會取出
+	OutboxPending       = "pending"
+	OutboxDelivered     = "delivered"
+	OutboxFailed        = "failed" // 超過最大嘗試次數，不再重試
+)
+
+// outboxRetention 已送達的事件保留時間
+const outboxRetention = 7 * 24 * time.Hour
+
+// OutboxEntry outbox 中的事件與送出狀態
+type OutboxEntry struct {
+	ModelEvent    `bson:",inline"`
+	Status        string     `bson:"status" json:"status"`
+	Attempts      int        `bson:"attempts" json:"attempts"`
+	NextAttemptAt time.Time  `bson:"next_attempt_at" json:"next_attempt_at"`
+	LockedUntil   time.Time  `bson:"locked_until" json:"-"` // 取出後的租約，到期前其他 dispatcher 不會重複取出
+	LastError     string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	DeliveredAt   *time.Time `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+}
+
+// OutboxService 以 MongoDB 集合保存待送出的模型事件（transactional outbox）
+type OutboxService struct {
+	collection *mongo.Collection
+}
+
+// NewOutboxService 建立新的事件 outbox 服務
+func NewOutboxService(client *database.MongoDBClient) *OutboxService {
+	return &OutboxService{collection: client.GetCollection("event_outbox")}
+}
+
+// EnsureIndexes 建立取出待送事件的索引，以及已送達事件的 TTL 索引
+func (s *OutboxService) EnsureIndexes(ctx context.Context) ([]string, error) {
+	names, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "delivered_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(outboxRetention.Seconds())),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("建立事件 outbox 索引失敗: %v", err)
+	}
+	return names, nil
+}
+
+// Append 以 pending_commit 狀態寫入事件，對應的模型寫入完成後需呼叫 Commit 才會送出
+func (s *OutboxService) Append(ctx context.Context, events []*ModelEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	documents := make([]interface{}, len(events))
+	for i, event := range events {
+		documents[i] = &OutboxEntry{
+			ModelEvent:    *event,
+			Status:        OutboxPendingCommit,
+			NextAttemptAt: event.OccurredAt,
+		}
+	}
+
+	opCtx, end := startOp(ctx, s.collection, "insert_many")
+	_, err := s.collection.InsertMany(opCtx, documents)
+	end(err)
+	if err != nil {
+		return fmt.Errorf("寫入事件 outbox 失敗: %v", err)
+	}
+	return nil
+}
+
+// Commit 將事件由 pending_commit 改為 pending，之後 dispatcher 才會取出
+func (s *OutboxService) Commit(ctx context.Context, events []*ModelEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	filter := bson.M{"_id": bson.M{"$in": ids}, "status": OutboxPendingCommit}
+	opCtx, end := startOp(ctx, s.collection, "update_many")
+	_, err := s.collection.UpdateMany(opCtx, filter, bson.M{"$set": bson.M{"status": OutboxPending}})
+	end(err)
+	if err != nil {
+		return fmt.Errorf("確認事件 outbox 失敗: %v", err)
+	}
+	return nil
+}
+
+// ListUncommitted 列出發生時間早於 before 仍為 pending_commit 的事件
+func (s *OutboxService) ListUncommitted(ctx context.Context, before time.Time) ([]*OutboxEntry, error) {
+	filter := bson.M{"status": OutboxPendingCommit, "next_attempt_at": bson.M{"$lt": before}}
+	opCtx, end := startOp(ctx, s.collection, "find")
+	cur, err := s.collection.Find(opCtx, filter, options.Find().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}))
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("查詢未確認的事件失敗: %v", err)
+	}
+	entries := []*OutboxEntry{}
+	if err := cur.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("查詢未確認的事件失敗: %v", err)
+	}
+	return entries, nil
+}
+
+// Discard 刪除對應的模型寫入沒有完成的事件
+func (s *OutboxService) Discard(ctx context.Context, id string) error {
+	opCtx, end := startOp(ctx, s.collection, "delete_one")
+	_, err := s.collection.DeleteOne(opCtx, bson.M{"_id": id, "status": OutboxPendingCommit})
+	end(err)
+	if err != nil {
+		return fmt.Errorf("刪除未確認的事件失敗: %v", err)
+	}
+	return nil
+}
+
+// Claim 依發生時間取出最多 limit 筆可送出的事件，並設定 lease 期間的租約
+//
+// 送出前程序中止時，租約到期後事件會再次被取出，因此同一事件可能送出多次（至少一次）。
+func (s *OutboxService) Claim(ctx context.Context, limit int, lease time.Duration) ([]*OutboxEntry, error) {
+	var entries []*OutboxEntry
+	for len(entries) < limit {
+		now := time.Now()
+		filter := bson.M{
+			"status":          OutboxPending,
+			"next_attempt_at": bson.M{"$lte": now},
+			"locked_until":    bson.M{"$lte": now},
+		}
+		update := bson.M{"$set": bson.M{"locked_until": now.Add(lease)}}
+		opts := options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1}}).
+			SetReturnDocument(options.After)
+
+		var entry OutboxEntry
+		opCtx, end := startOp(ctx, s.collection, "find_one_and_update")
+		err := s.collection.FindOneAndUpdate(opCtx, filter, update, opts).Decode(&entry)
+		end(ignoreNoDocuments(err))
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			return entries, fmt.Errorf("取出待送事件失敗: %v", err)
+		}
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
+
+// MarkDelivered 標記事件已送達
+func (s *OutboxService) MarkDelivered(ctx context.Context, id string) error {
+	now := time.Now()
+	return s.update(ctx, id, bson.M{
+		"status":       OutboxDelivered,
+		"delivered_at": now,
+		"locked_until": time.Time{},
+		"last_error":   "",
+	})
+}
+
+// MarkRetry 記錄送出失敗，於 nextAttemptAt 後重試
+func (s *OutboxService) MarkRetry(ctx context.Context, id string, attempts int, nextAttemptAt time.Time, lastError string) error {
+	return s.update(ctx, id, bson.M{
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"locked_until":    time.Time{},
+		"last_error":      lastError,
+	})
+}
+
+// MarkFailed 超過最大嘗試次數，標記為失敗並不再重試
+func (s *OutboxService) MarkFailed(ctx context.Context, id string, attempts int, lastError string) error {
+	return s.update(ctx, id, bson.M{
+		"status":       OutboxFailed,
+		"attempts":     attempts,
+		"locked_until": time.Time{},
+		"last_error":   lastError,
+	})
+}
+
+// update 更新單一事件的欄位
+func (s *OutboxService) update(ctx context.Context, id string, fields bson.M) error {
+	opCtx, end := startOp(ctx, s.collection, "update_one")
+	_, err := s.collection.UpdateOne(opCtx, bson.M{"_id": id}, bson.M{"$set": fields})
+	end(err)
+	if err != nil {
+		return fmt.Errorf("更新事件狀態失敗: %v", err)
+	}
+	return nil
+}
+
+// CountByStatus 依狀態統計事件數量
+func (s *OutboxService) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}}},
+	}
+	opCtx, end := startOp(ctx, s.collection, "aggregate")
+	cur, err := s.collection.Aggregate(opCtx, pipeline)
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("統計事件失敗: %v", err)
+	}
+	var rows []struct {
+		Status string `bson:"_id"`
+		Count  int64  `bson:"count"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("統計事件失敗: %v", err)
+	}
+	counts := map[string]int64{OutboxPendingCommit: 0, OutboxPending: 0, OutboxDelivered: 0, OutboxFailed: 0}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
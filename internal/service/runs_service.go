@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fetch-sketchfab-data/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SyncRun 一次同步任務的執行紀錄
+type SyncRun struct {
+	RunID          string    `bson:"_id" json:"run_id"`
+	JobName        string    `bson:"job_name" json:"job_name"`
+	Trigger        string    `bson:"trigger" json:"trigger"` // scheduled / manual
+	Status         string    `bson:"status" json:"status"`   // done / failed
+	StartedAt      time.Time `bson:"started_at" json:"started_at"`
+	FinishedAt     time.Time `bson:"finished_at" json:"finished_at"`
+	DurationMs     int64     `bson:"duration_ms" json:"duration_ms"`
+	Pages          int       `bson:"pages" json:"pages"`
+	FetchedCount   int       `bson:"fetched_count" json:"fetched_count"`
+	InsertedCount  int64     `bson:"inserted_count" json:"inserted_count"`
+	UpdatedCount   int64     `bson:"updated_count" json:"updated_count"`
+	UnchangedCount int64     `bson:"unchanged_count" json:"unchanged_count"`
+	Error          string    `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// RunFilter 執行紀錄查詢條件
+type RunFilter struct {
+	JobName string
+	Status  string
+	Limit   int
+}
+
+// RunsService 保存與查詢同步任務的執行紀錄
+type RunsService struct {
+	collection *mongo.Collection
+}
+
+// NewRunsService 建立新的執行紀錄服務
+func NewRunsService(client *database.MongoDBClient) *RunsService {
+	return &RunsService{collection: client.GetCollection("sync_runs")}
+}
+
+// EnsureIndexes 建立依任務與開始時間、依狀態與完成時間查詢的索引，回傳索引名稱
+func (s *RunsService) EnsureIndexes(ctx context.Context) ([]string, error) {
+	names, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "job_name", Value: 1}, {Key: "started_at", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "finished_at", Value: -1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("建立執行紀錄索引失敗: %v", err)
+	}
+	return names, nil
+}
+
+// SaveRun 寫入一筆執行紀錄，相同 run ID 會覆寫
+func (s *RunsService) SaveRun(ctx context.Context, run *SyncRun) error {
+	opCtx, end := startOp(ctx, s.collection, "replace_one")
+	_, err := s.collection.ReplaceOne(opCtx, bson.M{"_id": run.RunID}, run, options.Replace().SetUpsert(true))
+	end(err)
+	if err != nil {
+		return fmt.Errorf("儲存執行紀錄失敗: %v", err)
+	}
+	return nil
+}
+
+// GetLastSuccessfulRun 取得最近一次成功完成的執行紀錄，沒有紀錄時回傳 nil
+func (s *RunsService) GetLastSuccessfulRun(ctx context.Context) (*SyncRun, error) {
+	var run SyncRun
+	opts := options.FindOne().SetSort(bson.D{{Key: "finished_at", Value: -1}})
+	opCtx, end := startOp(ctx, s.collection, "find_one")
+	err := s.collection.FindOne(opCtx, bson.M{"status": "done"}, opts).Decode(&run)
+	end(ignoreNoDocuments(err))
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查詢最近成功的執行紀錄失敗: %v", err)
+	}
+	return &run, nil
+}
+
+// ListRuns 依開始時間由新到舊列出執行紀錄
+func (s *RunsService) ListRuns(ctx context.Context, filter *RunFilter) ([]*SyncRun, error) {
+	query := bson.M{}
+	if filter.JobName != "" {
+		query["job_name"] = filter.JobName
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "started_at", Value: -1}}).
+		SetLimit(int64(limit))
+	opCtx, end := startOp(ctx, s.collection, "find")
+	cur, err := s.collection.Find(opCtx, query, opts)
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("查詢執行紀錄失敗: %v", err)
+	}
+	defer cur.Close(ctx)
+
+	runs := []*SyncRun{}
+	if err := cur.All(ctx, &runs); err != nil {
+		return nil, fmt.Errorf("解析執行紀錄失敗: %v", err)
+	}
+	return runs, nil
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"fetch-sketchfab-data/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrSavedSearchNotFound 表示找不到指定的儲存搜尋
+var ErrSavedSearchNotFound = errors.New("找不到儲存搜尋")
+
+// ErrInvalidSavedSearch 表示儲存搜尋內容不完整或格式錯誤
+var ErrInvalidSavedSearch = errors.New("無效的儲存搜尋")
+
+// SearchCriteria 儲存搜尋的條件，與 /models 查詢參數相同，空值表示不限制
+type SearchCriteria struct {
+	Query        string `bson:"query,omitempty" json:"q,omitempty"` // 關鍵字，每個字都須出現在名稱、描述或標籤中
+	Tag          string `bson:"tag,omitempty" json:"tag,omitempty"`
+	Category     string `bson:"category,omitempty" json:"category,omitempty"`
+	License      string `bson:"license,omitempty" json:"license,omitempty"` // 授權 uid 或名稱
+	User         string `bson:"user,omitempty" json:"user,omitempty"`       // 使用者 uid 或帳號
+	Downloadable *bool  `bson:"downloadable,omitempty" json:"downloadable,omitempty"`
+	MinViews     *int   `bson:"min_views,omitempty" json:"min_views,omitempty"`
+	MinLikes     *int   `bson:"min_likes,omitempty" json:"min_likes,omitempty"`
+}
+
+// ModelFilter 轉換為模型查詢條件
+func (c *SearchCriteria) ModelFilter() *ModelFilter {
+	return &ModelFilter{
+		Query:        c.Query,
+		Tag:          c.Tag,
+		Category:     c.Category,
+		License:      c.License,
+		User:         c.User,
+		Downloadable: c.Downloadable,
+		MinViews:     c.MinViews,
+		MinLikes:     c.MinLikes,
+	}
+}
+
+// SavedSearch 儲存的搜尋，每次同步後以新增的模型比對並寄送摘要
+type SavedSearch struct {
+	ID            string         `bson:"_id" json:"id"`
+	Name          string         `bson:"name" json:"name"`
+	Criteria      SearchCriteria `bson:"criteria" json:"criteria"`
+	Recipients    []string       `bson:"recipients,omitempty" json:"recipients,omitempty"` // 摘要的 email 收件人
+	Active        bool           `bson:"active" json:"active"`
+	CreatedAt     time.Time      `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time      `bson:"updated_at" json:"updated_at"`
+	LastMatchedAt *time.Time     `bson:"last_matched_at,omitempty" json:"last_matched_at,omitempty"` // 最近一次有新模型符合的時間
+}
+
+// validate 檢查儲存搜尋內容並整理收件人
+func (s *SavedSearch) validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return fmt.Errorf("%w: name 不可為空", ErrInvalidSavedSearch)
+	}
+	for i, recipient := range s.Recipients {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("%w: 無效的收件人 %q", ErrInvalidSavedSearch, recipient)
+		}
+		s.Recipients[i] = address.Address
+	}
+	if (s.Criteria.MinViews != nil && *s.Criteria.MinViews < 0) || (s.Criteria.MinLikes != nil && *s.Criteria.MinLikes < 0) {
+		return fmt.Errorf("%w: min_views 與 min_likes 不可為負數", ErrInvalidSavedSearch)
+	}
+	return nil
+}
+
+// SearchMatch 一個儲存搜尋在本次同步中符合的新模型
+type SearchMatch struct {
+	Search *SavedSearch      `json:"search"`
+	Models []*SketchfabModel `json:"models"`
+}
+
+// SavedSearchesService 管理儲存的搜尋
+type SavedSearchesService struct {
+	collection *mongo.Collection
+}
+
+// NewSavedSearchesService 建立新的儲存搜尋服務
+func NewSavedSearchesService(client *database.MongoDBClient) *SavedSearchesService {
+	return &SavedSearchesService{collection: client.GetCollection("saved_searches")}
+}
+
+// EnsureIndexes 建立列出啟用中搜尋的索引
+func (s *SavedSearchesService) EnsureIndexes(ctx context.Context) ([]string, error) {
+	names, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "active", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("建立儲存搜尋索引失敗: %v", err)
+	}
+	return names, nil
+}
+
+// CreateSearch 建立儲存搜尋
+func (s *SavedSearchesService) CreateSearch(ctx context.Context, search *SavedSearch) error {
+	if err := search.validate(); err != nil {
+		return err
+	}
+	now := time.Now()
+	search.ID = newEventID()
+	search.CreatedAt = now
+	search.UpdatedAt = now
+	search.LastMatchedAt = nil
+
+	opCtx, end := startOp(ctx, s.collection, "insert_one")
+	_, err := s.collection.InsertOne(opCtx, search)
+	end(err)
+	if err != nil {
+		return fmt.Errorf("建立儲存搜尋失敗: %v", err)
+	}
+	return nil
+}
+
+// UpdateSearch 更新儲存搜尋的名稱、條件、收件人與啟用狀態
+func (s *SavedSearchesService) UpdateSearch(ctx context.Context, search *SavedSearch) error {
+	if err := search.validate(); err != nil {
+		return err
+	}
+	fields := bson.M{
+		"name":       search.Name,
+		"criteria":   search.Criteria,
+		"recipients": search.Recipients,
+		"active":     search.Active,
+		"updated_at": time.Now(),
+	}
+
+	opCtx, end := startOp(ctx, s.collection, "update_one")
+	result, err := s.collection.UpdateOne(opCtx, bson.M{"_id": search.ID}, bson.M{"$set": fields})
+	end(err)
+	if err != nil {
+		return fmt.Errorf("更新儲存搜尋失敗: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: %s", ErrSavedSearchNotFound, search.ID)
+	}
+	return nil
+}
+
+// DeleteSearch 刪除儲存搜尋
+func (s *SavedSearchesService) DeleteSearch(ctx context.Context, id string) error {
+	opCtx, end := startOp(ctx, s.collection, "delete_one")
+	result, err := s.collection.DeleteOne(opCtx, bson.M{"_id": id})
+	end(err)
+	if err != nil {
+		return fmt.Errorf("刪除儲存搜尋失敗: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("%w: %s", ErrSavedSearchNotFound, id)
+	}
+	return nil
+}
+
+// GetSearch 取得單一儲存搜尋
+func (s *SavedSearchesService) GetSearch(ctx context.Context, id string) (*SavedSearch, error) {
+	var search SavedSearch
+	opCtx, end := startOp(ctx, s.collection, "find_one")
+	err := s.collection.FindOne(opCtx, bson.M{"_id": id}).Decode(&search)
+	end(ignoreNoDocuments(err))
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("%w: %s", ErrSavedSearchNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查詢儲存搜尋失敗: %v", err)
+	}
+	return &search, nil
+}
+
+// ListSearches 依建立時間列出儲存搜尋，activeOnly 為 true 時只列出啟用中的搜尋
+func (s *SavedSearchesService) ListSearches(ctx context.Context, activeOnly bool) ([]*SavedSearch, error) {
+	filter := bson.M{}
+	if activeOnly {
+		filter["active"] = true
+	}
+	opCtx, end := startOp(ctx, s.collection, "find")
+	cur, err := s.collection.Find(opCtx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("查詢儲存搜尋失敗: %v", err)
+	}
+	searches := []*SavedSearch{}
+	if err := cur.All(ctx, &searches); err != nil {
+		return nil, fmt.Errorf("查詢儲存搜尋失敗: %v", err)
+	}
+	return searches, nil
+}
+
+// Evaluate 以所有啟用中的儲存搜尋比對指定的模型（通常是本次同步新增的模型），只回傳有符合的搜尋
+//
+// 符合的模型包含原始 API 資料（縮圖、檢視網址），並更新搜尋的 last_matched_at。
+func (s *SavedSearchesService) Evaluate(ctx context.Context, modelsService *ModelsService, modelIDs []string) ([]*SearchMatch, error) {
+	if len(modelIDs) == 0 {
+		return nil, nil
+	}
+	searches, err := s.ListSearches(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*SearchMatch
+	for _, search := range searches {
+		filter := search.Criteria.ModelFilter()
+		filter.IDs = modelIDs
+		filter.Sort = "-like_count"
+		filter.WithRawData = true
+
+		var found []*SketchfabModel
+		_, err := modelsService.StreamModels(ctx, filter, func(model *SketchfabModel) error {
+			found = append(found, model)
+			return nil
+		})
+		if err != nil {
+			return matches, fmt.Errorf("比對儲存搜尋 %s 失敗: %v", search.Name, err)
+		}
+		if len(found) == 0 {
+			continue
+		}
+		matches = append(matches, &SearchMatch{Search: search, Models: found})
+	}
+
+	if len(matches) > 0 {
+		ids := make([]string, len(matches))
+		for i, match := range matches {
+			ids[i] = match.Search.ID
+		}
+		opCtx, end := startOp(ctx, s.collection, "update_many")
+		_, err := s.collection.UpdateMany(opCtx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{"$set": bson.M{"last_matched_at": time.Now()}})
+		end(err)
+		if err != nil {
+			return matches, fmt.Errorf("更新儲存搜尋失敗: %v", err)
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,472 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"fetch-sketchfab-data/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrSubscriptionNotFound 表示找不到指定的訂閱或投遞
+var ErrSubscriptionNotFound = errors.New("找不到訂閱")
+
+// ErrInvalidSubscription 表示訂閱內容不完整或格式錯誤
+var ErrInvalidSubscription = errors.New("無效的訂閱")
+
+// 投遞狀態
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead" // 超過最大嘗試次數，保留為 dead letter，可手動重送
+)
+
+// deliveryLogRetention 已送達的投遞與投遞紀錄的保留時間
+const deliveryLogRetention = 30 * 24 * time.Hour
+
+// SubscriptionFilter 訂閱的模型條件，空值表示不限制；多個標籤或分類符合任一即可
+type SubscriptionFilter struct {
+	Tags       []string `bson:"tags,omitempty" json:"tags,omitempty"`             // 標籤 slug 或名稱
+	Categories []string `bson:"categories,omitempty" json:"categories,omitempty"` // 分類名稱
+	License    string   `bson:"license,omitempty" json:"license,omitempty"`       // 授權 uid 或名稱
+	MinLikes   int      `bson:"min_likes,omitempty" json:"min_likes,omitempty"`
+}
+
+// Matches 判斷模型是否符合條件，名稱不分大小寫
+func (f *SubscriptionFilter) Matches(model *SketchfabModel) bool {
+	if model == nil {
+		return false
+	}
+	if model.LikeCount < f.MinLikes {
+		return false
+	}
+	if f.License != "" && !strings.EqualFold(f.License, model.LicenseString("uid")) &&
+		!strings.EqualFold(f.License, model.LicenseString("label")) {
+		return false
+	}
+	if len(f.Tags) > 0 {
+		var names []string
+		for _, tag := range model.Tags {
+			names = append(names, tag["slug"], tag["name"])
+		}
+		if !containsAny(names, f.Tags) {
+			return false
+		}
+	}
+	if len(f.Categories) > 0 && !containsAny(model.CategoryNames(), f.Categories) {
+		return false
+	}
+	return true
+}
+
+// containsAny 判斷 values 是否包含 wanted 中的任一值（不分大小寫）
+func containsAny(values, wanted []string) bool {
+	for _, w := range wanted {
+		for _, v := range values {
+			if v != "" && strings.EqualFold(v, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Subscription webhook 訂閱
+type Subscription struct {
+	ID        string             `bson:"_id" json:"id"`
+	URL       string             `bson:"url" json:"url"`
+	Secret    string             `bson:"secret" json:"secret,omitempty"` // 簽章金鑰，只在建立時回傳
+	Events    []string           `bson:"events" json:"events"`           // 訂閱的事件類型
+	Filter    SubscriptionFilter `bson:"filter" json:"filter"`
+	Active    bool               `bson:"active" json:"active"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Wants 判斷訂閱是否要接收此事件
+func (s *Subscription) Wants(event *ModelEvent) bool {
+	if !s.Active {
+		return false
+	}
+	for _, eventType := range s.Events {
+		if eventType == event.Type {
+			return s.Filter.Matches(event.Model)
+		}
+	}
+	return false
+}
+
+// validate 檢查訂閱內容並補上預設值
+func (s *Subscription) validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url 必須是 http(s) 網址", ErrInvalidSubscription)
+	}
+	if len(s.Events) == 0 {
+		s.Events = []string{EventModelCreated, EventModelUpdated}
+	}
+	for _, eventType := range s.Events {
+		switch eventType {
+		case EventModelCreated, EventModelUpdated, EventModelRemoved:
+		default:
+			return fmt.Errorf("%w: 未知的事件類型 %q", ErrInvalidSubscription, eventType)
+		}
+	}
+	if s.Filter.MinLikes < 0 {
+		return fmt.Errorf("%w: min_likes 不可為負數", ErrInvalidSubscription)
+	}
+	return nil
+}
+
+// WebhookDelivery 一個事件對一個訂閱的投遞
+type WebhookDelivery struct {
+	ID             string     `bson:"_id" json:"id"` // 訂閱 ID:事件 ID，重複加入時不會產生第二筆
+	SubscriptionID string     `bson:"subscription_id" json:"subscription_id"`
+	EventID        string     `bson:"event_id" json:"event_id"`
+	EventType      string     `bson:"event_type" json:"event_type"`
+	ModelID        string     `bson:"model_id" json:"model_id"`
+	Payload        string     `bson:"payload" json:"-"` // 送出的 JSON 內容，重送時內容與簽章輸入不變
+	Status         string     `bson:"status" json:"status"`
+	Attempts       int        `bson:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time  `bson:"next_attempt_at" json:"next_attempt_at"`
+	LockedUntil    time.Time  `bson:"locked_until" json:"-"`
+	LastError      string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	LastStatusCode int        `bson:"last_status_code,omitempty" json:"last_status_code,omitempty"`
+	CreatedAt      time.Time  `bson:"created_at" json:"created_at"`
+	DeliveredAt    *time.Time `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+	DeadAt         *time.Time `bson:"dead_at,omitempty" json:"dead_at,omitempty"`
+}
+
+// DeliveryAttempt 投遞紀錄中的一次嘗試
+type DeliveryAttempt struct {
+	DeliveryID     string    `bson:"delivery_id" json:"delivery_id"`
+	SubscriptionID string    `bson:"subscription_id" json:"subscription_id"`
+	EventID        string    `bson:"event_id" json:"event_id"`
+	Attempt        int       `bson:"attempt" json:"attempt"`
+	AttemptedAt    time.Time `bson:"attempted_at" json:"attempted_at"`
+	StatusCode     int       `bson:"status_code,omitempty" json:"status_code,omitempty"` // 0 表示連線失敗
+	DurationMs     int64     `bson:"duration_ms" json:"duration_ms"`
+	Error          string    `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// DeliveryFilter 投遞查詢條件
+type DeliveryFilter struct {
+	SubscriptionID string
+	Status         string
+	Limit          int
+}
+
+// SubscriptionsService 管理 webhook 訂閱、投遞佇列與投遞紀錄
+type SubscriptionsService struct {
+	collection         *mongo.Collection
+	deliveryCollection *mongo.Collection
+	logCollection      *mongo.Collection
+}
+
+// NewSubscriptionsService 建立新的 webhook 訂閱服務
+func NewSubscriptionsService(client *database.MongoDBClient) *SubscriptionsService {
+	return &SubscriptionsService{
+		collection:         client.GetCollection("webhook_subscriptions"),
+		deliveryCollection: client.GetCollection("webhook_deliveries"),
+		logCollection:      client.GetCollection("webhook_delivery_log"),
+	}
+}
+
+// EnsureIndexes 建立投遞佇列與投遞紀錄的索引，已送達的投遞與投遞紀錄保留 30 天，dead letter 不會過期
+func (s *SubscriptionsService) EnsureIndexes(ctx context.Context) ([]string, error) {
+	names, err := s.deliveryCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{Keys: bson.D{{Key: "subscription_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{
+			Keys:    bson.D{{Key: "delivered_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(deliveryLogRetention.Seconds())),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("建立投遞佇列索引失敗: %v", err)
+	}
+	logNames, err := s.logCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "delivery_id", Value: 1}, {Key: "attempted_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "attempted_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(deliveryLogRetention.Seconds())),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("建立投遞紀錄索引失敗: %v", err)
+	}
+	return append(names, logNames...), nil
+}
+
+// newSecret 產生訂閱的簽章金鑰
+func newSecret() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// CreateSubscription 建立訂閱，未指定 secret 時自動產生，未指定事件時訂閱 model.created 與 model.updated
+func (s *SubscriptionsService) CreateSubscription(ctx context.Context, subscription *Subscription) error {
+	if err := subscription.validate(); err != nil {
+		return err
+	}
+	now := time.Now()
+	subscription.ID = newEventID()
+	if subscription.Secret == "" {
+		subscription.Secret = newSecret()
+	}
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+
+	opCtx, end := startOp(ctx, s.collection, "insert_one")
+	_, err := s.collection.InsertOne(opCtx, subscription)
+	end(err)
+	if err != nil {
+		return fmt.Errorf("建立訂閱失敗: %v", err)
+	}
+	return nil
+}
+
+// UpdateSubscription 更新訂閱的網址、事件、條件與啟用狀態；secret 為空時保留原本的金鑰
+func (s *SubscriptionsService) UpdateSubscription(ctx context.Context, subscription *Subscription) error {
+	if err := subscription.validate(); err != nil {
+		return err
+	}
+	fields := bson.M{
+		"url":        subscription.URL,
+		"events":     subscription.Events,
+		"filter":     subscription.Filter,
+		"active":     subscription.Active,
+		"updated_at": time.Now(),
+	}
+	if subscription.Secret != "" {
+		fields["secret"] = subscription.Secret
+	}
+
+	opCtx, end := startOp(ctx, s.collection, "update_one")
+	result, err := s.collection.UpdateOne(opCtx, bson.M{"_id": subscription.ID}, bson.M{"$set": fields})
+	end(err)
+	if err != nil {
+		return fmt.Errorf("更新訂閱失敗: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: %s", ErrSubscriptionNotFound, subscription.ID)
+	}
+	return nil
+}
+
+// DeleteSubscription 刪除訂閱與尚未送出的投遞，投遞紀錄保留至到期
+func (s *SubscriptionsService) DeleteSubscription(ctx context.Context, id string) error {
+	opCtx, end := startOp(ctx, s.collection, "delete_one")
+	result, err := s.collection.DeleteOne(opCtx, bson.M{"_id": id})
+	end(err)
+	if err != nil {
+		return fmt.Errorf("刪除訂閱失敗: %v", err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("%w: %s", ErrSubscriptionNotFound, id)
+	}
+
+	opCtx, end = startOp(ctx, s.deliveryCollection, "delete_many")
+	_, err = s.deliveryCollection.DeleteMany(opCtx, bson.M{"subscription_id": id, "status": DeliveryPending})
+	end(err)
+	if err != nil {
+		return fmt.Errorf("刪除訂閱的投遞失敗: %v", err)
+	}
+	return nil
+}
+
+// GetSubscription 取得單一訂閱
+func (s *SubscriptionsService) GetSubscription(ctx context.Context, id string) (*Subscription, error) {
+	var subscription Subscription
+	opCtx, end := startOp(ctx, s.collection, "find_one")
+	err := s.collection.FindOne(opCtx, bson.M{"_id": id}).Decode(&subscription)
+	end(ignoreNoDocuments(err))
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("%w: %s", ErrSubscriptionNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查詢訂閱失敗: %v", err)
+	}
+	return &subscription, nil
+}
+
+// ListSubscriptions 列出所有訂閱，activeOnly 為 true 時只列出啟用中的訂閱
+func (s *SubscriptionsService) ListSubscriptions(ctx context.Context, activeOnly bool) ([]*Subscription, error) {
+	filter := bson.M{}
+	if activeOnly {
+		filter["active"] = true
+	}
+	opCtx, end := startOp(ctx, s.collection, "find")
+	cur, err := s.collection.Find(opCtx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("查詢訂閱失敗: %v", err)
+	}
+	subscriptions := []*Subscription{}
+	if err := cur.All(ctx, &subscriptions); err != nil {
+		return nil, fmt.Errorf("查詢訂閱失敗: %v", err)
+	}
+	return subscriptions, nil
+}
+
+// EnqueueDeliveries 加入投遞，已存在的投遞（同一訂閱與事件）不會重複加入
+func (s *SubscriptionsService) EnqueueDeliveries(ctx context.Context, deliveries []*WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	now := time.Now()
+	operations := make([]mongo.WriteModel, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		delivery.ID = delivery.SubscriptionID + ":" + delivery.EventID
+		delivery.Status = DeliveryPending
+		delivery.NextAttemptAt = now
+		delivery.CreatedAt = now
+		operation := mongo.NewUpdateOneModel()
+		operation.SetFilter(bson.M{"_id": delivery.ID})
+		operation.SetUpdate(bson.M{"$setOnInsert": delivery})
+		operation.SetUpsert(true)
+		operations = append(operations, operation)
+	}
+
+	opCtx, end := startOp(ctx, s.deliveryCollection, "bulk_write")
+	_, err := s.deliveryCollection.BulkWrite(opCtx, operations)
+	end(err)
+	if err != nil {
+		return fmt.Errorf("加入投遞失敗: %v", err)
+	}
+	return nil
+}
+
+// ClaimDeliveries 依建立時間取出最多 limit 筆到期的投遞，並設定 lease 期間的租約
+func (s *SubscriptionsService) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	for len(deliveries) < limit {
+		now := time.Now()
+		filter := bson.M{
+			"status":          DeliveryPending,
+			"next_attempt_at": bson.M{"$lte": now},
+			"locked_until":    bson.M{"$lte": now},
+		}
+		opts := options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+			SetReturnDocument(options.After)
+
+		var delivery WebhookDelivery
+		opCtx, end := startOp(ctx, s.deliveryCollection, "find_one_and_update")
+		err := s.deliveryCollection.FindOneAndUpdate(opCtx, filter, bson.M{"$set": bson.M{"locked_until": now.Add(lease)}}, opts).Decode(&delivery)
+		end(ignoreNoDocuments(err))
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			return deliveries, fmt.Errorf("取出待送投遞失敗: %v", err)
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+	return deliveries, nil
+}
+
+// RecordAttempt 寫入投遞紀錄並更新投遞狀態：成功時標記為已送達，失敗時於 nextAttemptAt 重試，dead 為 true 時轉為 dead letter
+func (s *SubscriptionsService) RecordAttempt(ctx context.Context, delivery *WebhookDelivery, attempt *DeliveryAttempt, nextAttemptAt time.Time, dead bool) error {
+	opCtx, end := startOp(ctx, s.logCollection, "insert_one")
+	_, err := s.logCollection.InsertOne(opCtx, attempt)
+	end(err)
+	if err != nil {
+		return fmt.Errorf("寫入投遞紀錄失敗: %v", err)
+	}
+
+	fields := bson.M{
+		"attempts":         attempt.Attempt,
+		"locked_until":     time.Time{},
+		"last_error":       attempt.Error,
+		"last_status_code": attempt.StatusCode,
+	}
+	switch {
+	case attempt.Error == "":
+		fields["status"] = DeliveryDelivered
+		fields["delivered_at"] = attempt.AttemptedAt
+	case dead:
+		fields["status"] = DeliveryDead
+		fields["dead_at"] = attempt.AttemptedAt
+	default:
+		fields["next_attempt_at"] = nextAttemptAt
+	}
+
+	opCtx, end = startOp(ctx, s.deliveryCollection, "update_one")
+	_, err = s.deliveryCollection.UpdateOne(opCtx, bson.M{"_id": delivery.ID}, bson.M{"$set": fields})
+	end(err)
+	if err != nil {
+		return fmt.Errorf("更新投遞狀態失敗: %v", err)
+	}
+	return nil
+}
+
+// ListDeliveries 依建立時間遞減列出投遞
+func (s *SubscriptionsService) ListDeliveries(ctx context.Context, filter *DeliveryFilter) ([]*WebhookDelivery, error) {
+	query := bson.M{}
+	if filter.SubscriptionID != "" {
+		query["subscription_id"] = filter.SubscriptionID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+
+	opCtx, end := startOp(ctx, s.deliveryCollection, "find")
+	cur, err := s.deliveryCollection.Find(opCtx, query, opts)
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("查詢投遞失敗: %v", err)
+	}
+	deliveries := []*WebhookDelivery{}
+	if err := cur.All(ctx, &deliveries); err != nil {
+		return nil, fmt.Errorf("查詢投遞失敗: %v", err)
+	}
+	return deliveries, nil
+}
+
+// ListAttempts 依時間列出一個投遞的所有嘗試（手動重送後 attempt 會從 1 重新計算）
+func (s *SubscriptionsService) ListAttempts(ctx context.Context, deliveryID string) ([]*DeliveryAttempt, error) {
+	opCtx, end := startOp(ctx, s.logCollection, "find")
+	cur, err := s.logCollection.Find(opCtx, bson.M{"delivery_id": deliveryID}, options.Find().SetSort(bson.D{{Key: "attempted_at", Value: 1}}))
+	end(err)
+	if err != nil {
+		return nil, fmt.Errorf("查詢投遞紀錄失敗: %v", err)
+	}
+	attempts := []*DeliveryAttempt{}
+	if err := cur.All(ctx, &attempts); err != nil {
+		return nil, fmt.Errorf("查詢投遞紀錄失敗: %v", err)
+	}
+	return attempts, nil
+}
+
+// Redeliver 將 dead letter 重新排入佇列並重設嘗試次數
+func (s *SubscriptionsService) Redeliver(ctx context.Context, deliveryID string) error {
+	update := bson.M{
+		"$set":   bson.M{"status": DeliveryPending, "attempts": 0, "next_attempt_at": time.Now()},
+		"$unset": bson.M{"dead_at": ""},
+	}
+	opCtx, end := startOp(ctx, s.deliveryCollection, "update_one")
+	result, err := s.deliveryCollection.UpdateOne(opCtx, bson.M{"_id": deliveryID, "status": DeliveryDead}, update)
+	end(err)
+	if err != nil {
+		return fmt.Errorf("重送投遞失敗: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: 找不到 dead letter %s", ErrSubscriptionNotFound, deliveryID)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package site
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"time"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+//go:embed site.html.tmpl
+var templateSource string
+
+//go:embed style.css
+var styleSource string
+
+//go:embed search.js
+var searchScriptSource string
+
+// assets 直接複製到網站根目錄的靜態檔案
+var assets = map[string]string{
+	"style.css": styleSource,
+	"search.js": searchScriptSource,
+}
+
+// templateFuncs 網站範本使用的函式
+var templateFuncs = template.FuncMap{
+	"size": formatSize,
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return "—"
+		}
+		return t.Local().Format("2006-01-02")
+	},
+	"deref": func(v *int) string {
+		if v == nil {
+			return "—"
+		}
+		return fmt.Sprint(*v)
+	},
+	"cards": func(root string, entries []*entry) cardList {
+		return cardList{Root: root, Entries: entries}
+	},
+}
+
+var templates = template.Must(template.New("site").Funcs(templateFuncs).Parse(templateSource))
+
+// page 所有網頁共用的資料
+type page struct {
+	Root        string // 網站根目錄的相對路徑，例如 "../"
+	Title       string
+	SiteTitle   string
+	GeneratedAt time.Time
+	Kinds       []*kind
+}
+
+// page 建立網頁共用資料
+func (g *Generator) page(root, title string) page {
+	return page{Root: root, Title: title, SiteTitle: g.title, GeneratedAt: g.generatedAt, Kinds: g.kinds()}
+}
+
+// cardList 模型卡片列表
+type cardList struct {
+	Root    string
+	Entries []*entry
+}
+
+// modelPage 模型詳細頁
+type modelPage struct {
+	page
+	Entry    *entry
+	Model    *service.SketchfabModel
+	Archives []service.NamedArchive
+}
+
+// groupPage 單一標籤、分類、授權或作者的模型列表
+type groupPage struct {
+	page
+	Group *group
+}
+
+// kindPage 列出一種索引的所有項目
+type kindPage struct {
+	page
+	Kind   *kind
+	Groups []*group
+}
+
+// homeSection 首頁中一種索引的熱門項目
+type homeSection struct {
+	Kind   *kind
+	Count  int
+	Groups []*group
+}
+
+// homePage 網站首頁
+type homePage struct {
+	page
+	Total    int
+	Models   []*entry
+	Sections []homeSection
+}
+
+// searchPage 搜尋頁
+type searchPage struct {
+	page
+}
+
+// formatSize 將位元組數轉為易讀的大小
+func formatSize(bytes int) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value, exp := float64(bytes)/unit, 0
+	for value >= unit && exp < 3 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", value, "KMGT"[exp])
+}
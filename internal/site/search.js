@@ -0,0 +1,60 @@
+// 以 search-index.js 預先產生的索引在瀏覽器中搜尋模型：每個字都須出現在名稱、作者、授權、標籤或分類中
+(function () {
+  var maxResults = 200;
+  var input = document.getElementById("q");
+  var summary = document.getElementById("summary");
+  var results = document.getElementById("results");
+  var index = (window.searchIndex || []).map(function (model) {
+    var text = [model.name, model.author, model.license].concat(model.tags || [], model.categories || []).join(" ");
+    return { model: model, text: text.toLowerCase() };
+  });
+
+  function card(model) {
+    var link = document.createElement("a");
+    link.className = "card";
+    link.href = model.file;
+    if (model.thumbnail) {
+      var img = document.createElement("img");
+      img.src = model.thumbnail;
+      img.alt = "";
+      img.loading = "lazy";
+      link.appendChild(img);
+    } else {
+      var placeholder = document.createElement("div");
+      placeholder.className = "noimg";
+      link.appendChild(placeholder);
+    }
+    var name = document.createElement("span");
+    name.className = "name";
+    name.textContent = model.name;
+    link.appendChild(name);
+    var meta = document.createElement("span");
+    meta.className = "meta";
+    meta.textContent = (model.author ? model.author + " · " : "") + "♥ " + model.likes + " · 👁 " + model.views;
+    link.appendChild(meta);
+    return link;
+  }
+
+  function search() {
+    var words = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.textContent = "";
+    if (words.length === 0) {
+      summary.textContent = "共 " + index.length + " 個模型";
+      return;
+    }
+    var matches = index.filter(function (item) {
+      return words.every(function (word) { return item.text.indexOf(word) !== -1; });
+    });
+    summary.textContent = "找到 " + matches.length + " 個模型" + (matches.length > maxResults ? "，顯示前 " + maxResults + " 個" : "");
+    matches.slice(0, maxResults).forEach(function (item) { results.appendChild(card(item.model)); });
+  }
+
+  input.value = new URLSearchParams(window.location.search).get("q") || "";
+  input.addEventListener("input", function () {
+    var url = new URL(window.location.href);
+    url.searchParams.set("q", input.value);
+    window.history.replaceState(null, "", url);
+    search();
+  });
+  search();
+})();
@@ -0,0 +1,361 @@
+package site
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"fetch-sketchfab-data/internal/service"
+)
+
+const (
+	// maxHomeModels 首頁列出的熱門模型數
+	maxHomeModels = 60
+	// maxHomeGroups 首頁每種索引列出的項目數
+	maxHomeGroups = 20
+	// IndexFile 搜尋索引的檔名，也用來辨識既有的網站目錄
+	IndexFile = "search-index.json"
+)
+
+// kind 一種索引頁（標籤、分類、授權、作者）
+type kind struct {
+	Dir    string // 輸出目錄，也是網址路徑
+	Title  string
+	groups map[string]*group
+	files  map[string]bool
+}
+
+// group 索引頁中的一個項目，例如一個標籤與其模型
+type group struct {
+	Kind    *kind
+	Name    string
+	File    string // 相對於網站根目錄的路徑
+	URL     string // 外部網址，例如作者的 Sketchfab 頁面
+	Entries []*entry
+}
+
+// entry 網站中的一個模型
+type entry struct {
+	ID           string
+	file         string // 詳細頁相對於網站根目錄的路徑，見 pageFile
+	Name         string
+	ThumbnailURL string
+	Likes        int
+	Views        int
+	CreatedAt    time.Time
+	Creator      *group
+	License      *group
+	Tags         []*group
+	Categories   []*group
+}
+
+// File 模型詳細頁相對於網站根目錄的路徑
+func (e *entry) File() string {
+	return e.file
+}
+
+// pageFile 模型詳細頁的路徑：uid 只含英數字、- 與 _ 時直接作為檔名，
+// 否則（例如含有 / 或 ..）改用 uid 的雜湊值，避免寫到輸出目錄之外或產生需要編碼的網址
+func pageFile(id string) string {
+	name := id
+	if !service.ValidModelID(id) || !urlSafe(id) {
+		sum := sha1.Sum([]byte(id))
+		name = "id-" + hex.EncodeToString(sum[:8])
+	}
+	return "models/" + name + ".html"
+}
+
+// urlSafe 是否只包含英數字、- 與 _
+func urlSafe(s string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// Generator 將模型產生為靜態網站：每個模型一個詳細頁，依標籤、分類、授權、作者建立索引頁，並附上搜尋索引
+//
+// 詳細頁在 Write 時直接寫出，索引頁與搜尋索引在 Close 時寫出；記憶體中只保留每個模型的摘要。
+type Generator struct {
+	dir         string
+	title       string
+	generatedAt time.Time
+	entries     []*entry
+	tags        *kind
+	categories  *kind
+	licenses    *kind
+	creators    *kind
+}
+
+// NewGenerator 建立輸出到 dir 的網站產生器，dir 必須是空目錄或不存在
+func NewGenerator(dir, title string) (*Generator, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "models"), 0o755); err != nil {
+		return nil, fmt.Errorf("建立網站目錄失敗: %v", err)
+	}
+	newKind := func(dir, title string) *kind {
+		return &kind{Dir: dir, Title: title, groups: map[string]*group{}, files: map[string]bool{}}
+	}
+	return &Generator{
+		dir:         dir,
+		title:       title,
+		generatedAt: time.Now(),
+		tags:        newKind("tags", "標籤"),
+		categories:  newKind("categories", "分類"),
+		licenses:    newKind("licenses", "授權"),
+		creators:    newKind("creators", "作者"),
+	}, nil
+}
+
+// kinds 依導覽列順序列出所有索引
+func (g *Generator) kinds() []*kind {
+	return []*kind{g.tags, g.categories, g.licenses, g.creators}
+}
+
+// Write 寫出模型的詳細頁並加入索引
+func (g *Generator) Write(model *service.SketchfabModel) error {
+	e := &entry{
+		ID:           model.ID,
+		file:         pageFile(model.ID),
+		Name:         model.Name,
+		ThumbnailURL: model.ThumbnailURL(640),
+		Likes:        model.LikeCount,
+		Views:        model.ViewCount,
+		CreatedAt:    model.CreatedAt,
+	}
+	if e.Name == "" {
+		e.Name = model.ID
+	}
+
+	author := model.UserString("displayName")
+	if author == "" {
+		author = model.UserString("username")
+	}
+	// 以帳號與授權名稱作為索引頁的檔名，比 uid 容易辨識
+	creatorKey := model.UserString("username")
+	if creatorKey == "" {
+		creatorKey = model.UserString("uid")
+	}
+	e.Creator = g.creators.add(creatorKey, author, e)
+	if e.Creator != nil {
+		e.Creator.URL = model.UserString("profileUrl")
+	}
+
+	licenseKey := model.LicenseString("label")
+	if licenseKey == "" {
+		licenseKey = model.LicenseString("uid")
+	}
+	e.License = g.licenses.add(licenseKey, model.LicenseString("label"), e)
+
+	for _, tag := range model.Tags {
+		key := tag["slug"]
+		if key == "" {
+			key = tag["name"]
+		}
+		if t := g.tags.add(key, tag["name"], e); t != nil {
+			e.Tags = append(e.Tags, t)
+		}
+	}
+	for _, name := range model.CategoryNames() {
+		if c := g.categories.add(name, name, e); c != nil {
+			e.Categories = append(e.Categories, c)
+		}
+	}
+	g.entries = append(g.entries, e)
+
+	return g.render(e.File(), "model", &modelPage{
+		page:     g.page("../", e.Name),
+		Entry:    e,
+		Model:    model,
+		Archives: model.ArchiveList(),
+	})
+}
+
+// add 將模型加入 key 對應的項目，key 為空時不加入
+func (k *kind) add(key, name string, e *entry) *group {
+	if key == "" {
+		return nil
+	}
+	if name == "" {
+		name = key
+	}
+	grp, ok := k.groups[key]
+	if !ok {
+		grp = &group{Kind: k, Name: name, File: k.Dir + "/" + k.fileName(key) + ".html"}
+		k.groups[key] = grp
+	}
+	grp.Entries = append(grp.Entries, e)
+	return grp
+}
+
+// fileName 將項目的 key 轉為不重複的檔名：保留英數字與 -，其他字元改為 -；無法轉換的 key 使用雜湊值
+func (k *kind) fileName(key string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(key) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	name := strings.TrimSuffix(b.String(), "-")
+	if name == "" || len(name) > 80 {
+		sum := sha1.Sum([]byte(key))
+		name = strings.TrimSuffix(name[:min(len(name), 60)], "-")
+		if name != "" {
+			name += "-"
+		}
+		name += hex.EncodeToString(sum[:4])
+	}
+	for i, base := 2, name; k.files[name]; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	k.files[name] = true
+	return name
+}
+
+// Close 寫出首頁、索引頁、搜尋頁、搜尋索引與靜態檔案
+func (g *Generator) Close() error {
+	sortEntries(g.entries)
+	for _, k := range g.kinds() {
+		groups := k.sorted()
+		for _, grp := range groups {
+			sortEntries(grp.Entries)
+			if err := g.render(grp.File, "group", &groupPage{page: g.page("../", k.Title+": "+grp.Name), Group: grp}); err != nil {
+				return err
+			}
+		}
+		if err := g.render(k.Dir+"/index.html", "kind", &kindPage{page: g.page("../", k.Title), Kind: k, Groups: groups}); err != nil {
+			return err
+		}
+	}
+
+	home := &homePage{page: g.page("", g.title), Total: len(g.entries)}
+	home.Models = g.entries[:min(len(g.entries), maxHomeModels)]
+	for _, k := range g.kinds() {
+		groups := k.sorted()
+		home.Sections = append(home.Sections, homeSection{Kind: k, Count: len(groups), Groups: groups[:min(len(groups), maxHomeGroups)]})
+	}
+	if err := g.render("index.html", "home", home); err != nil {
+		return err
+	}
+	if err := g.render("search.html", "search", &searchPage{page: g.page("", "搜尋")}); err != nil {
+		return err
+	}
+	if err := g.writeSearchIndex(); err != nil {
+		return err
+	}
+	for name, content := range assets {
+		if err := os.WriteFile(filepath.Join(g.dir, name), []byte(content), 0o644); err != nil {
+			return fmt.Errorf("寫入網站檔案失敗: %v", err)
+		}
+	}
+	return nil
+}
+
+// sorted 依模型數由多到少列出項目
+func (k *kind) sorted() []*group {
+	groups := make([]*group, 0, len(k.groups))
+	for _, grp := range k.groups {
+		groups = append(groups, grp)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if len(groups[i].Entries) != len(groups[j].Entries) {
+			return len(groups[i].Entries) > len(groups[j].Entries)
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// sortEntries 依喜歡數由多到少排序模型
+func sortEntries(entries []*entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Likes != entries[j].Likes {
+			return entries[i].Likes > entries[j].Likes
+		}
+		return entries[i].Views > entries[j].Views
+	})
+}
+
+// searchRecord 搜尋索引中的一個模型
+type searchRecord struct {
+	ID         string   `json:"id"`
+	File       string   `json:"file"` // 詳細頁的路徑，與網站中的連結相同
+	Name       string   `json:"name"`
+	Author     string   `json:"author,omitempty"`
+	License    string   `json:"license,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	Thumbnail  string   `json:"thumbnail,omitempty"`
+	Likes      int      `json:"likes"`
+	Views      int      `json:"views"`
+}
+
+// writeSearchIndex 寫出搜尋索引
+//
+// search-index.json 供一般的網頁伺服器使用；直接以 file:// 開啟時瀏覽器不允許讀取 JSON，
+// 因此另外寫出內容相同的 search-index.js。
+func (g *Generator) writeSearchIndex() error {
+	records := make([]searchRecord, len(g.entries))
+	for i, e := range g.entries {
+		record := searchRecord{ID: e.ID, File: e.File(), Name: e.Name, Thumbnail: e.ThumbnailURL, Likes: e.Likes, Views: e.Views}
+		if e.Creator != nil {
+			record.Author = e.Creator.Name
+		}
+		if e.License != nil {
+			record.License = e.License.Name
+		}
+		for _, t := range e.Tags {
+			record.Tags = append(record.Tags, t.Name)
+		}
+		for _, c := range e.Categories {
+			record.Categories = append(record.Categories, c.Name)
+		}
+		records[i] = record
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("產生搜尋索引失敗: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(g.dir, IndexFile), data, 0o644); err != nil {
+		return fmt.Errorf("寫入搜尋索引失敗: %v", err)
+	}
+	script := append(append([]byte("window.searchIndex = "), data...), ";\n"...)
+	if err := os.WriteFile(filepath.Join(g.dir, "search-index.js"), script, 0o644); err != nil {
+		return fmt.Errorf("寫入搜尋索引失敗: %v", err)
+	}
+	return nil
+}
+
+// render 以範本產生網頁並寫入 name
+func (g *Generator) render(name, tmpl string, data interface{}) error {
+	path := filepath.Join(g.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("建立網站目錄失敗: %v", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("寫入網頁失敗: %v", err)
+	}
+	defer file.Close()
+
+	buf := bufio.NewWriter(file)
+	if err := templates.ExecuteTemplate(buf, tmpl, data); err != nil {
+		return fmt.Errorf("產生網頁 %s 失敗: %v", name, err)
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("寫入網頁失敗: %v", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 所有 span 共用的 tracer 名稱
+const instrumentationName = "fetch-sketchfab-data"
+
+// Config 追蹤設定
+type Config struct {
+	Exporter    string  // none、otlp 或 stdout
+	Endpoint    string  // OTLP/HTTP 端點，例如 http://localhost:4318，未指定路徑時使用 /v1/traces
+	SampleRatio float64 // 取樣比例，0 到 1
+	ServiceName string
+}
+
+// Setup 依設定建立 TracerProvider 並設為全域，回傳的函式在結束前呼叫以送出剩餘的 span
+//
+// Exporter 為 none 時不會建立 provider，Start 建立的 span 皆為 no-op。
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch cfg.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		endpoint, parseErr := tracesURL(cfg.Endpoint)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("未知的追蹤 exporter: %s（可用: none、otlp、stdout）", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("建立追蹤 exporter 失敗: %v", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("建立追蹤資源失敗: %v", err)
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// tracesURL 依 OTEL_EXPORTER_OTLP_ENDPOINT 的慣例，在只有主機位址時補上 /v1/traces
+func tracesURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("無效的 OTLP 端點: %s", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/traces"
+	}
+	return u.String(), nil
+}
+
+// Start 開始一個 span
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End 結束 span，err 不為 nil 時記錄錯誤並將狀態設為 Error
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// IDs 取得 context 中目前 span 的 trace ID 與 span ID，沒有有效 span 時回傳空字串
+func IDs(ctx context.Context) (traceID, spanID string) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", ""
+	}
+	return sc.TraceID().String(), sc.SpanID().String()
+}